@@ -0,0 +1,47 @@
+package main
+
+import (
+	"contract-template/tools/abi"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+)
+
+// abiPathFor returns the manifest path that sits next to a wasm artifact,
+// e.g. artifacts/main.wasm -> artifacts/main.abi.json.
+func abiPathFor(artifact string) string {
+	return strings.TrimSuffix(artifact, ".wasm") + ".abi.json"
+}
+
+func runABI(args []string) error {
+	fs := flag.NewFlagSet("abi", flag.ContinueOnError)
+	out := fs.String("o", "", "output file (default stdout)")
+	wasm := fs.String("wasm", "", "write the manifest next to this artifact")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected exactly one package directory")
+	}
+	if *out != "" && *wasm != "" {
+		return errors.New("-o and -wasm are mutually exclusive")
+	}
+	m, err := abi.ParseDir(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	path := *out
+	if *wasm != "" {
+		path = abiPathFor(*wasm)
+	}
+	if path == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"contract-template/tools/abi"
+	"contract-template/tools/clientgen"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadABI reads a manifest file or extracts one from a package directory.
+func loadABI(src string) (*abi.ABI, error) {
+	if strings.HasSuffix(src, ".json") {
+		return abi.Load(src)
+	}
+	return abi.ParseDir(src)
+}
+
+func runClient(args []string) error {
+	fs := flag.NewFlagSet("client", flag.ContinueOnError)
+	pkg := fs.String("pkg", "", "package name of the generated client (required)")
+	out := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected a package directory or abi.json")
+	}
+	if *pkg == "" {
+		return errors.New("-pkg is required")
+	}
+	m, err := loadABI(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	src, err := clientgen.Generate(m, *pkg)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(*out, src, 0o644)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"contract-template/tools/deploy"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func runDeploy(args []string) error {
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	name := fs.String("name", "", "contract name (required)")
+	desc := fs.String("description", "", "contract description")
+	owner := fs.String("owner", "", "owner address (default: the signer)")
+	keyFile := fs.String("key", "", "key file: Hive active WIF or hex ed25519 seed for a did:key (required)")
+	account := fs.String("account", "", "Hive account signing the custom_json (default: the hive: owner)")
+	netId := fs.String("net", deploy.DefaultNetId, "VSC network id")
+	chainId := fs.String("chain-id", deploy.HiveChainId, "Hive chain id")
+	head := fs.String("head", "", "reference block as <number>:<block id> for offline Hive signing")
+	expire := fs.Duration("expire", 10*time.Minute, "Hive transaction lifetime")
+	rpcURL := fs.String("rpc", "", "Hive API node, used for the reference block and -broadcast")
+	broadcast := fs.Bool("broadcast", false, "broadcast the signed transaction through -rpc")
+	cid := fs.String("cid", "", "code CID overriding the computed one, which matches ipfs add --cid-version=1 (256 KiB chunks, raw leaves)")
+	out := fs.String("o", "", "output file (default <artifact>.deploy.json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected a wasm artifact")
+	}
+	if *name == "" || *keyFile == "" {
+		return errors.New("-name and -key are required")
+	}
+	artifact := fs.Arg(0)
+	wasm, err := os.ReadFile(artifact)
+	if err != nil {
+		return err
+	}
+	key, err := deploy.LoadKey(*keyFile)
+	if err != nil {
+		return err
+	}
+
+	var rpc deploy.RPC
+	if *rpcURL != "" {
+		rpc = &deploy.HiveRPC{URL: *rpcURL}
+	}
+	ctx := context.Background()
+	opts := deploy.Options{NetId: *netId, Account: *account, ChainId: *chainId, Expiration: time.Now().Add(*expire), CID: *cid}
+	if _, isHive := key.(*deploy.HiveKey); isHive {
+		switch {
+		case *head != "":
+			if opts.Ref, err = parseHead(*head); err != nil {
+				return err
+			}
+		case rpc != nil:
+			if opts.Ref, err = rpc.RefBlock(ctx); err != nil {
+				return err
+			}
+		default:
+			return errors.New("hive signing needs -head or -rpc for the reference block")
+		}
+	}
+
+	signed, err := deploy.Build(wasm, deploy.Metadata{Name: *name, Description: *desc, Owner: *owner}, key, opts)
+	if err != nil {
+		return err
+	}
+	b, err := signed.Marshal()
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = strings.TrimSuffix(artifact, ".wasm") + ".deploy.json"
+	}
+	if err := os.WriteFile(*out, b, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "code %s\nwrote %s (tx %s)\n", signed.Code.CID, *out, signed.TxId)
+
+	if *broadcast {
+		if rpc == nil {
+			return errors.New("-broadcast needs -rpc")
+		}
+		id, err := rpc.Broadcast(ctx, signed)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "broadcast %s\n", id)
+	}
+	return nil
+}
+
+func parseHead(s string) (deploy.RefBlock, error) {
+	num, id, ok := strings.Cut(s, ":")
+	if !ok {
+		return deploy.RefBlock{}, errors.New("-head must be <number>:<block id>")
+	}
+	n, err := strconv.ParseUint(num, 10, 32)
+	if err != nil {
+		return deploy.RefBlock{}, fmt.Errorf("-head: %w", err)
+	}
+	return deploy.RefBlockOf(uint32(n), id)
+}
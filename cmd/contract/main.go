@@ -0,0 +1,54 @@
+// Command contract is the developer tool for contracts built on this template.
+//
+// Usage:
+//
+//	contract abi [-o file | -wasm artifact] <package dir>
+//	contract client -pkg name [-o file] <package dir | abi.json>
+//	contract deploy -name n -key file [-rpc url [-broadcast]] <artifact.wasm>
+//	contract new <name> [-template empty|token|nft|multitoken|amm-v2|clmm] [-dir parent]
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"abi", "write the ABI manifest of a contract package", runABI},
+	{"client", "generate a typed Go client from a contract ABI", runClient},
+	{"deploy", "build and sign the contract creation transaction", runDeploy},
+	{"new", "create a contract package from a template", runNew},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: contract <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", c.name, c.summary)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, "contract "+c.name+":", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
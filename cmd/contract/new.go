@@ -0,0 +1,44 @@
+package main
+
+import (
+	"contract-template/tools/scaffold"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func runNew(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	tmpl := fs.String("template", "empty", "template: "+templateNames())
+	dir := fs.String("dir", ".", "parent directory of the new package")
+	// accept the name before the flags: contract new <name> -template=token
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		args = append(args[1:], args[0])
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected a contract name")
+	}
+	root, err := scaffold.ModuleRoot(*dir)
+	if err != nil {
+		return err
+	}
+	out, err := scaffold.New(root, *tmpl, *dir, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "created %s from the %s template; run `make test` there\n", out, *tmpl)
+	return nil
+}
+
+func templateNames() string {
+	names := make([]string, len(scaffold.Templates))
+	for i, t := range scaffold.Templates {
+		names[i] = t.Name
+	}
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,13 @@
+// Command contractvet runs the contractlint analyzer as a go vet tool:
+//
+//	go build -o bin/contractvet ./cmd/contractvet
+//	go vet -vettool=$(pwd)/bin/contractvet ./examples/...
+package main
+
+import (
+	"contract-template/tools/contractlint"
+
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() { unitchecker.Main(contractlint.Analyzer) }
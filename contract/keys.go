@@ -0,0 +1,22 @@
+package main
+
+import "contract-template/sdk"
+
+// stateKey names an entry in contract state. Declare every key here so the
+// contract's state layout reads in one place.
+type stateKey string
+
+const (
+	keyMessage stateKey = "message"
+)
+
+func (k stateKey) get() string {
+	if v := sdk.StateGetObject(string(k)); v != nil {
+		return *v
+	}
+	return ""
+}
+
+func (k stateKey) set(v string) { sdk.StateSetObject(string(k), v) }
+
+func (k stateKey) del() { sdk.StateDeleteObject(string(k)) }
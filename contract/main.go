@@ -26,9 +26,21 @@ func main() {
 
 }
 
+//abi:payload message:string
+//abi:returns string
+//abi:mutability view
 //go:wasmexport entrypoint
 func Entrypoint(a *string) *string {
 	sdk.Log(*a)
 	// panic("test")
 	return a
 }
+
+// Store the payload as the contract's message.
+//
+//abi:payload message:string
+//go:wasmexport set_message
+func SetMessage(a *string) *string {
+	keyMessage.set(*a)
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"testing"
+)
+
+func sptr(s string) *string { return &s }
+
+func TestEntrypoint_EchoesPayload(t *testing.T) {
+	sdk.ShimReset()
+	if got := Entrypoint(sptr("hello")); got == nil || *got != "hello" {
+		t.Fatalf("entrypoint returned %v, want hello", got)
+	}
+}
+
+func TestSetMessage_StoresPayload(t *testing.T) {
+	sdk.ShimReset()
+	SetMessage(sptr("hi"))
+	if keyMessage.get() != "hi" {
+		t.Fatalf("message = %q, want hi", keyMessage.get())
+	}
+	keyMessage.del()
+	if keyMessage.get() != "" {
+		t.Fatal("message not deleted")
+	}
+}
@@ -0,0 +1,10 @@
+#!/usr/bin/env bash
+# Build ./contract and write a signed deploy transaction to artifacts/main.deploy.json.
+#
+# Usage: ./deploy.sh -name mycontract -key active.wif -rpc https://api.hive.blog [-broadcast]
+#        ./deploy.sh -name mycontract -key did.key
+set -euo pipefail
+cd "$(dirname "$0")"
+mkdir -p artifacts
+tinygo build -o artifacts/main.wasm -gc=custom -scheduler=none -panic=trap -no-debug -target=wasm-unknown ./contract
+go run ./cmd/contract deploy "$@" artifacts/main.wasm
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"contract-template/sdk/multitoken"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+func main() {}
+
+// batch is the JSON payload of the batch entrypoints.
+type batch struct {
+	From     string   `json:"from,omitempty"`
+	To       string   `json:"to,omitempty"`
+	Accounts []string `json:"accounts,omitempty"`
+	Ids      []uint64 `json:"ids"`
+	Amounts  []uint64 `json:"amounts,omitempty"`
+}
+
+func params(payload *string, n int) []string {
+	if payload == nil {
+		sdk.Abort("missing payload")
+	}
+	p := strings.Split(strings.TrimSpace(*payload), ",")
+	if len(p) != n {
+		sdk.Abort("expected " + strconv.Itoa(n) + " parameters")
+	}
+	return p
+}
+
+func parseBatch(payload *string) batch {
+	var b batch
+	if payload == nil || json.Unmarshal([]byte(*payload), &b) != nil {
+		sdk.Abort("invalid batch payload")
+	}
+	return b
+}
+
+func parseUint(s string) uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		sdk.Abort("invalid number")
+	}
+	return v
+}
+
+func parseAddress(s string) sdk.Address {
+	addr, err := sdk.ParseAddress(s)
+	if err != nil {
+		sdk.Abort("invalid address")
+	}
+	return addr
+}
+
+func ret(v uint64) *string {
+	s := strconv.FormatUint(v, 10)
+	return &s
+}
+
+// Initialize the contract with a metadata URI template in which "{id}" is
+// replaced by the token id. The caller becomes the owner.
+//
+//abi:payload uri:string?
+//go:wasmexport init
+func Init(payload *string) *string {
+	uri := ""
+	if payload != nil {
+		uri = strings.TrimSpace(*payload)
+	}
+	multitoken.Init(uri)
+	return nil
+}
+
+// Create a token id with a supply cap, 0 for none. The caller must be the owner.
+//
+//abi:payload id:uint64,maxSupply:uint64
+//abi:auth owner
+//go:wasmexport create
+func Create(payload *string) *string {
+	p := params(payload, 2)
+	multitoken.Create(parseUint(p[0]), parseUint(p[1]))
+	return nil
+}
+
+// Mint units of an id to an address. The caller must be the owner, a minter
+// or a minter of that id.
+//
+//abi:payload to:address,id:uint64,amount:uint64
+//abi:auth owner
+//go:wasmexport mint
+func Mint(payload *string) *string {
+	p := params(payload, 3)
+	multitoken.Mint(parseAddress(p[0]), parseUint(p[1]), parseUint(p[2]))
+	return nil
+}
+
+// Mint several ids at once.
+// Payload: {"to":"hive:alice","ids":[1,2],"amounts":[100,1]}
+//
+//abi:payload json
+//abi:auth owner
+//go:wasmexport mint_batch
+func MintBatch(payload *string) *string {
+	b := parseBatch(payload)
+	multitoken.MintBatch(parseAddress(b.To), b.Ids, b.Amounts)
+	return nil
+}
+
+// Transfer units of an id from the caller.
+//
+//abi:payload to:address,id:uint64,amount:uint64
+//go:wasmexport transfer
+func Transfer(payload *string) *string {
+	p := params(payload, 3)
+	multitoken.TransferFrom(sdk.GetEnv().Caller.Address, parseAddress(p[0]), parseUint(p[1]), parseUint(p[2]))
+	return nil
+}
+
+// Transfer units of an id from a holder. The caller must be the holder or its operator.
+//
+//abi:payload from:address,to:address,id:uint64,amount:uint64
+//go:wasmexport transfer_from
+func TransferFrom(payload *string) *string {
+	p := params(payload, 4)
+	multitoken.TransferFrom(parseAddress(p[0]), parseAddress(p[1]), parseUint(p[2]), parseUint(p[3]))
+	return nil
+}
+
+// Transfer several ids from a holder at once; either all move or none.
+// Payload: {"from":"hive:alice","to":"hive:bob","ids":[1,2],"amounts":[10,1]}
+//
+//abi:payload json
+//go:wasmexport batch_transfer_from
+func BatchTransferFrom(payload *string) *string {
+	b := parseBatch(payload)
+	multitoken.BatchTransferFrom(parseAddress(b.From), parseAddress(b.To), b.Ids, b.Amounts)
+	return nil
+}
+
+// Burn units of an id from a holder. The caller must be the holder or its operator.
+//
+//abi:payload from:address,id:uint64,amount:uint64
+//go:wasmexport burn
+func Burn(payload *string) *string {
+	p := params(payload, 3)
+	multitoken.Burn(parseAddress(p[0]), parseUint(p[1]), parseUint(p[2]))
+	return nil
+}
+
+// Burn several ids from a holder at once.
+// Payload: {"from":"hive:alice","ids":[1,2],"amounts":[10,1]}
+//
+//abi:payload json
+//go:wasmexport burn_batch
+func BurnBatch(payload *string) *string {
+	b := parseBatch(payload)
+	multitoken.BurnBatch(parseAddress(b.From), b.Ids, b.Amounts)
+	return nil
+}
+
+// Let an operator move all of the caller's tokens.
+//
+//abi:payload operator:address,approved:enum(0|1)
+//go:wasmexport set_approval_for_all
+func SetApprovalForAll(payload *string) *string {
+	p := params(payload, 2)
+	if p[1] != "0" && p[1] != "1" {
+		sdk.Abort("approved must be 0 or 1")
+	}
+	multitoken.SetApprovalForAll(parseAddress(p[0]), p[1] == "1")
+	return nil
+}
+
+// Units of an id held by an address.
+//
+//abi:payload address:address,id:uint64
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport balance_of
+func BalanceOf(payload *string) *string {
+	p := params(payload, 2)
+	return ret(multitoken.BalanceOf(parseAddress(p[0]), parseUint(p[1])))
+}
+
+// Balances of accounts[i] in ids[i], returned as a JSON array.
+// Payload: {"accounts":["hive:alice","hive:bob"],"ids":[1,1]}
+//
+//abi:payload json
+//abi:returns json
+//abi:mutability view
+//go:wasmexport balance_of_batch
+func BalanceOfBatch(payload *string) *string {
+	b := parseBatch(payload)
+	addrs := make([]sdk.Address, len(b.Accounts))
+	for i, a := range b.Accounts {
+		addrs[i] = parseAddress(a)
+	}
+	out, _ := json.Marshal(multitoken.BalanceOfBatch(addrs, b.Ids))
+	s := string(out)
+	return &s
+}
+
+// Units of an id in existence.
+//
+//abi:payload id:uint64
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport total_supply
+func TotalSupply(payload *string) *string {
+	p := params(payload, 1)
+	return ret(multitoken.Supply(parseUint(p[0])))
+}
+
+// Metadata URI of an id.
+//
+//abi:payload id:uint64
+//abi:returns string
+//abi:mutability view
+//go:wasmexport uri
+func URI(payload *string) *string {
+	p := params(payload, 1)
+	s := multitoken.URI(parseUint(p[0]))
+	return &s
+}
+
+// Grant or revoke minting of one id, or of every id when id is empty. The
+// caller must be the owner.
+//
+//abi:payload address:address,id:uint64?,enabled:enum(0|1)
+//abi:auth owner
+//go:wasmexport set_minter
+func SetMinter(payload *string) *string {
+	p := params(payload, 3)
+	role := multitoken.RoleMinter
+	if p[1] != "" {
+		role = multitoken.MinterRole(parseUint(p[1]))
+	}
+	addr := parseAddress(p[0])
+	switch p[2] {
+	case "1":
+		access.GrantRole(role, addr)
+	case "0":
+		access.RevokeRole(role, addr)
+	default:
+		sdk.Abort("enabled must be 0 or 1")
+	}
+	return nil
+}
+
+// Propose a new owner. The caller must be the current owner.
+// Ownership moves once the proposed address calls acceptOwner.
+//
+//abi:payload newOwner:address
+//abi:auth owner
+//go:wasmexport changeOwner
+func ChangeOwner(payload *string) *string {
+	multitoken.RequireInit()
+	access.TransferOwnership(parseAddress(*payload))
+	return nil
+}
+
+// Accept a pending ownership transfer. The caller must be the proposed owner.
+//
+//abi:payload none
+//go:wasmexport acceptOwner
+func AcceptOwner(_ *string) *string {
+	multitoken.RequireInit()
+	access.AcceptOwnership()
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"testing"
+)
+
+func sptr(s string) *string { return &s }
+
+func expectPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic but none occurred")
+		}
+	}()
+	f()
+}
+
+func view(f func(*string) *string, payload string) string {
+	res := f(sptr(payload))
+	if res == nil {
+		return ""
+	}
+	return *res
+}
+
+func TestMultiToken_Entrypoints(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:items")
+	sdk.ShimSetSender("hive:owner")
+	Init(sptr("https://items.example/{id}"))
+	Create(sptr("1,0"))
+	Create(sptr("2,1"))
+	expectPanic(t, func() { _ = Create(sptr("3")) })
+	Mint(sptr("hive:alice,1,100"))
+	MintBatch(sptr(`{"to":"hive:alice","ids":[2],"amounts":[1]}`))
+	expectPanic(t, func() { _ = MintBatch(sptr(`{"to":"hive:alice","ids":[1]`)) })
+	expectPanic(t, func() { _ = Mint(sptr("hive:alice,1,x")) })
+
+	sdk.ShimSetSender("hive:alice")
+	Transfer(sptr("hive:bob,1,30"))
+	BatchTransferFrom(sptr(`{"from":"hive:alice","to":"hive:bob","ids":[1,2],"amounts":[10,1]}`))
+	if got := view(BalanceOfBatch, `{"accounts":["hive:alice","hive:bob","hive:bob"],"ids":[1,1,2]}`); got != "[60,40,1]" {
+		t.Fatalf("balance_of_batch = %s", got)
+	}
+	expectPanic(t, func() { _ = SetApprovalForAll(sptr("hive:market,2")) })
+	SetApprovalForAll(sptr("hive:market,1"))
+
+	sdk.ShimSetSender("hive:market")
+	TransferFrom(sptr("hive:alice,hive:carol,1,5"))
+	Burn(sptr("hive:alice,1,5"))
+	BurnBatch(sptr(`{"from":"hive:alice","ids":[1],"amounts":[50]}`))
+	if view(BalanceOf, "hive:alice,1") != "0" || view(BalanceOf, "hive:carol,1") != "5" || view(TotalSupply, "1") != "45" {
+		t.Fatal("operator transfer and burn")
+	}
+	expectPanic(t, func() { _ = Mint(sptr("hive:market,1,1")) })
+
+	sdk.ShimSetSender("hive:owner")
+	SetMinter(sptr("hive:market,1,1"))
+	SetMinter(sptr("hive:admin,,1"))
+	sdk.ShimSetSender("hive:market")
+	Mint(sptr("hive:market,1,1"))
+	expectPanic(t, func() { _ = Mint(sptr("hive:market,2,1")) })
+	sdk.ShimSetSender("hive:admin")
+	expectPanic(t, func() { _ = Mint(sptr("hive:admin,2,1")) }) // capped at 1
+	if view(URI, "2") != "https://items.example/2" {
+		t.Fatal("uri")
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"contract-template/sdk/scenario"
+	"testing"
+)
+
+func TestScenario(t *testing.T) {
+	scenario.Run(t, "testdata/scenario.json", scenario.Entrypoints{
+		"init":                 Init,
+		"create":               Create,
+		"mint":                 Mint,
+		"mint_batch":           MintBatch,
+		"transfer":             Transfer,
+		"transfer_from":        TransferFrom,
+		"batch_transfer_from":  BatchTransferFrom,
+		"burn":                 Burn,
+		"burn_batch":           BurnBatch,
+		"set_approval_for_all": SetApprovalForAll,
+		"balance_of":           BalanceOf,
+		"balance_of_batch":     BalanceOfBatch,
+		"total_supply":         TotalSupply,
+		"uri":                  URI,
+		"set_minter":           SetMinter,
+		"changeOwner":          ChangeOwner,
+		"acceptOwner":          AcceptOwner,
+	})
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"contract-template/sdk/nft"
+	"strconv"
+	"strings"
+)
+
+func main() {}
+
+func params(payload *string, n int) []string {
+	if payload == nil {
+		sdk.Abort("missing payload")
+	}
+	p := strings.Split(strings.TrimSpace(*payload), ",")
+	if len(p) != n {
+		sdk.Abort("expected " + strconv.Itoa(n) + " parameters")
+	}
+	return p
+}
+
+func parseId(s string) uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		sdk.Abort("invalid token id")
+	}
+	return v
+}
+
+func parseAddress(s string) sdk.Address {
+	addr, err := sdk.ParseAddress(s)
+	if err != nil {
+		sdk.Abort("invalid address")
+	}
+	return addr
+}
+
+func ret(v uint64) *string {
+	s := strconv.FormatUint(v, 10)
+	return &s
+}
+
+// Initialize the collection. The caller becomes the owner.
+//
+//abi:payload name:string,symbol:string
+//go:wasmexport init
+func Init(payload *string) *string {
+	p := params(payload, 2)
+	nft.Init(nft.Config{Name: p[0], Symbol: p[1]})
+	return nil
+}
+
+// Mint the next token to an address and return its id. The URI is the rest
+// of the payload and may contain commas. The caller must be the owner or a minter.
+//
+//abi:payload to:address,uri:string?
+//abi:returns uint64
+//abi:auth owner
+//go:wasmexport mint
+func Mint(payload *string) *string {
+	if payload == nil {
+		sdk.Abort("missing payload")
+	}
+	to, uri, _ := strings.Cut(strings.TrimSpace(*payload), ",")
+	return ret(nft.Mint(parseAddress(to), uri))
+}
+
+// Transfer a token owned by the caller.
+//
+//abi:payload to:address,id:uint64
+//go:wasmexport transfer
+func Transfer(payload *string) *string {
+	p := params(payload, 2)
+	nft.Transfer(parseAddress(p[0]), parseId(p[1]))
+	return nil
+}
+
+// Transfer a token the caller owns, is approved for or operates.
+//
+//abi:payload from:address,to:address,id:uint64
+//go:wasmexport transfer_from
+func TransferFrom(payload *string) *string {
+	p := params(payload, 3)
+	nft.TransferFrom(parseAddress(p[0]), parseAddress(p[1]), parseId(p[2]))
+	return nil
+}
+
+// Approve an address to move one token; an empty address clears it.
+//
+//abi:payload approved:address?,id:uint64
+//go:wasmexport approve
+func Approve(payload *string) *string {
+	p := params(payload, 2)
+	var approved sdk.Address
+	if p[0] != "" {
+		approved = parseAddress(p[0])
+	}
+	nft.Approve(approved, parseId(p[1]))
+	return nil
+}
+
+// Let an operator move all of the caller's tokens.
+//
+//abi:payload operator:address,approved:enum(0|1)
+//go:wasmexport set_approval_for_all
+func SetApprovalForAll(payload *string) *string {
+	p := params(payload, 2)
+	if p[1] != "0" && p[1] != "1" {
+		sdk.Abort("approved must be 0 or 1")
+	}
+	nft.SetApprovalForAll(parseAddress(p[0]), p[1] == "1")
+	return nil
+}
+
+// Burn a token the caller owns, is approved for or operates.
+//
+//abi:payload id:uint64
+//go:wasmexport burn
+func Burn(payload *string) *string {
+	p := params(payload, 1)
+	nft.Burn(parseId(p[0]))
+	return nil
+}
+
+// Owner of a token.
+//
+//abi:payload id:uint64
+//abi:returns address
+//abi:mutability view
+//go:wasmexport owner_of
+func OwnerOf(payload *string) *string {
+	p := params(payload, 1)
+	s := nft.OwnerOf(parseId(p[0])).String()
+	return &s
+}
+
+// Metadata URI of a token.
+//
+//abi:payload id:uint64
+//abi:returns string
+//abi:mutability view
+//go:wasmexport token_uri
+func TokenURI(payload *string) *string {
+	p := params(payload, 1)
+	s := nft.TokenURI(parseId(p[0]))
+	return &s
+}
+
+// Address approved for a token, or empty.
+//
+//abi:payload id:uint64
+//abi:returns address
+//abi:mutability view
+//go:wasmexport get_approved
+func GetApproved(payload *string) *string {
+	p := params(payload, 1)
+	s := nft.GetApproved(parseId(p[0])).String()
+	return &s
+}
+
+// Number of tokens held by an address.
+//
+//abi:payload address:address
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport balance_of
+func BalanceOf(payload *string) *string {
+	p := params(payload, 1)
+	return ret(nft.BalanceOf(parseAddress(p[0])))
+}
+
+// Token id at a position of an owner's tokens.
+//
+//abi:payload owner:address,index:uint64
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport token_of_owner_by_index
+func TokenOfOwnerByIndex(payload *string) *string {
+	p := params(payload, 2)
+	return ret(nft.TokenOfOwnerByIndex(parseAddress(p[0]), parseId(p[1])))
+}
+
+// Token id at a position of all tokens.
+//
+//abi:payload index:uint64
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport token_by_index
+func TokenByIndex(payload *string) *string {
+	p := params(payload, 1)
+	return ret(nft.TokenByIndex(parseId(p[0])))
+}
+
+// Number of tokens in existence.
+//
+//abi:payload none
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport total_supply
+func TotalSupply(_ *string) *string {
+	return ret(nft.TotalSupply())
+}
+
+// Grant or revoke the minter role. The caller must be the owner.
+//
+//abi:payload address:address,enabled:enum(0|1)
+//abi:auth owner
+//go:wasmexport set_minter
+func SetMinter(payload *string) *string {
+	p := params(payload, 2)
+	addr := parseAddress(p[0])
+	switch p[1] {
+	case "1":
+		access.GrantRole(nft.RoleMinter, addr)
+	case "0":
+		access.RevokeRole(nft.RoleMinter, addr)
+	default:
+		sdk.Abort("enabled must be 0 or 1")
+	}
+	return nil
+}
+
+// Propose a new owner of the collection. The caller must be the current owner.
+// Ownership moves once the proposed address calls acceptOwner.
+//
+//abi:payload newOwner:address
+//abi:auth owner
+//go:wasmexport changeOwner
+func ChangeOwner(payload *string) *string {
+	nft.RequireInit()
+	access.TransferOwnership(parseAddress(*payload))
+	return nil
+}
+
+// Accept a pending ownership transfer. The caller must be the proposed owner.
+//
+//abi:payload none
+//go:wasmexport acceptOwner
+func AcceptOwner(_ *string) *string {
+	nft.RequireInit()
+	access.AcceptOwnership()
+	return nil
+}
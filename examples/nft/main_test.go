@@ -0,0 +1,71 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"testing"
+)
+
+func sptr(s string) *string { return &s }
+
+func expectPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic but none occurred")
+		}
+	}()
+	f()
+}
+
+func view(f func(*string) *string, payload string) string {
+	res := f(sptr(payload))
+	if res == nil {
+		return ""
+	}
+	return *res
+}
+
+func TestNFT_Entrypoints(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:nft")
+	sdk.ShimSetSender("hive:owner")
+	expectPanic(t, func() { _ = Init(sptr("Cards")) })
+	Init(sptr("Cards,CARD"))
+	if view(Mint, "hive:alice,ipfs://a") != "1" || view(Mint, "hive:alice") != "2" {
+		t.Fatal("mint ids")
+	}
+	expectPanic(t, func() { _ = Mint(sptr("alice,ipfs://a")) })
+
+	sdk.ShimSetSender("hive:alice")
+	expectPanic(t, func() { _ = Transfer(sptr("hive:bob,x")) })
+	expectPanic(t, func() { _ = SetApprovalForAll(sptr("hive:market,2")) })
+	Approve(sptr("hive:bob,1"))
+	if view(GetApproved, "1") != "hive:bob" {
+		t.Fatal("approval")
+	}
+	Approve(sptr(",1"))
+	if view(GetApproved, "1") != "" {
+		t.Fatal("empty approve must clear")
+	}
+
+	SetApprovalForAll(sptr("hive:market,1"))
+	sdk.ShimSetSender("hive:market")
+	TransferFrom(sptr("hive:alice,hive:bob,2"))
+	if view(OwnerOf, "2") != "hive:bob" || view(BalanceOf, "hive:alice") != "1" || view(TokenOfOwnerByIndex, "hive:bob,0") != "2" {
+		t.Fatal("operator transfer")
+	}
+	Burn(sptr("1"))
+	if view(TotalSupply, "") != "1" || view(TokenByIndex, "0") != "2" {
+		t.Fatal("operator burn")
+	}
+	expectPanic(t, func() { _ = TokenURI(sptr("1")) })
+
+	// minters are managed by the owner
+	expectPanic(t, func() { _ = SetMinter(sptr("hive:market,1")) })
+	sdk.ShimSetSender("hive:owner")
+	SetMinter(sptr("hive:market,1"))
+	sdk.ShimSetSender("hive:market")
+	if view(Mint, "hive:market,ipfs://m") != "3" || view(TokenURI, "3") != "ipfs://m" {
+		t.Fatal("minter mint")
+	}
+}
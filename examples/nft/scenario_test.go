@@ -0,0 +1,28 @@
+package main
+
+import (
+	"contract-template/sdk/scenario"
+	"testing"
+)
+
+func TestScenario(t *testing.T) {
+	scenario.Run(t, "testdata/scenario.json", scenario.Entrypoints{
+		"init":                    Init,
+		"mint":                    Mint,
+		"transfer":                Transfer,
+		"transfer_from":           TransferFrom,
+		"approve":                 Approve,
+		"set_approval_for_all":    SetApprovalForAll,
+		"burn":                    Burn,
+		"owner_of":                OwnerOf,
+		"token_uri":               TokenURI,
+		"get_approved":            GetApproved,
+		"balance_of":              BalanceOf,
+		"token_of_owner_by_index": TokenOfOwnerByIndex,
+		"token_by_index":          TokenByIndex,
+		"total_supply":            TotalSupply,
+		"set_minter":              SetMinter,
+		"changeOwner":             ChangeOwner,
+		"acceptOwner":             AcceptOwner,
+	})
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"contract-template/examples/v2-amm/router"
+	"contract-template/sdk"
+	"strconv"
+)
+
+func main() {}
+
+// Swap an exact input along a path of v2-amm pools and return the output,
+// which is sent to the caller. The input is drawn from the caller.
+// Payload: {"path":["contract:hive_hbd","contract:hbd_tok"],"assetIn":"hive",
+// "amountIn":1000,"minOut":950,"deadline":1200,"beneficiary":"hive:ui","refBps":25}
+// deadline (a block height, or a timestamp string such as
+// "2025-06-01T12:00:00Z"), beneficiary and refBps are optional.
+//
+//abi:payload json
+//abi:returns uint64
+//abi:mutability payable
+//go:wasmexport swap
+func Swap(payload *string) *string {
+	if payload == nil {
+		sdk.Abort("missing payload")
+	}
+	s := strconv.FormatUint(router.SwapExactIn(router.ParseRoute(*payload)), 10)
+	return &s
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"contract-template/sdk/scenario"
+	"testing"
+)
+
+func TestScenario(t *testing.T) {
+	scenario.Run(t, "testdata/scenario.json", scenario.Entrypoints{
+		"swap": Swap,
+	})
+}
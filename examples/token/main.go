@@ -2,176 +2,234 @@ package main
 
 import (
 	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"contract-template/sdk/fungible"
+	"contract-template/sdk/migrate"
 	"strconv"
 	"strings"
 )
 
-const MaxSupply = 1000000
-const Precision = 3
-const Symbol = "TOKEN"
-const Creator = "hive:vaultec.vsc"
+func main() {}
 
-// Get boolean of whether token has been initialized.
-func isInit() bool {
-	i := sdk.StateGetObject("isInit")
-	return i != nil
-}
+// Defaults used when init is called without a payload.
+const (
+	Name      = "Token"
+	Symbol    = "TOKEN"
+	Precision = 3
+	MaxSupply = 1000000
+)
 
-// Abort execution if token has not been initialized.
-func assertInit() {
-	if !isInit() {
-		// env.Abort()
+func params(payload *string, n int) []string {
+	if payload == nil {
+		sdk.Abort("missing payload")
 	}
-}
-
-// Get contract owner address and boolean of whether caller is an owner.
-func getOwner() (sdk.Address, bool) {
-	i := sdk.StateGetObject("owner")
-	e := sdk.GetEnv()
-	return sdk.Address(*i), *i == e.Caller.Address.String()
-}
-
-// Perform a + b addition. Aborts execution if an overflow is detected.
-func safeAdd(a, b uint64) uint64 {
-	sum := a + b
-	// Overflow occurs if sum < a (or equivalently sum < b)
-	overflow := sum < a
-	if overflow {
-		// env.Abort()
+	p := strings.Split(strings.TrimSpace(*payload), ",")
+	if len(p) != n {
+		sdk.Abort("expected " + strconv.Itoa(n) + " parameters")
 	}
-	return sum
+	return p
 }
 
-// Perform a - b subtraction. Aborts execution if an overflow is detected.
-func safeSub(a, b uint64) uint64 {
-	sum := a - b
-	// Overflow occurs if sum > a (or equivalently sum < b)
-	overflow := sum > a
-	if overflow {
-		// env.Abort()
+func parseAmount(s string) uint64 {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		sdk.Abort("invalid amount")
 	}
-	return sum
+	return v
 }
 
-// Increment token balance of an address.
-func incBalance(account sdk.Address, amount uint64) {
-	oldBal := getBalance(account)
-	newBal := safeAdd(oldBal, amount)
-	sdk.StateSetObject("accs/"+account.String()+"/bal", strconv.FormatUint(newBal, 10))
-}
-
-// Decrement token balance of an address. Aborts execution if insufficient balance.
-func decBalance(account sdk.Address, amount uint64) {
-	oldBal := getBalance(account)
-	if oldBal >= amount {
-		newBal := safeSub(oldBal, amount)
-		sdk.StateSetObject("accs/"+account.String()+"/bal", strconv.FormatUint(newBal, 10))
-	} else {
-		// env.Abort("Insufficient balance")
+func parseAddress(s string) sdk.Address {
+	addr, err := sdk.ParseAddress(s)
+	if err != nil {
+		sdk.Abort("invalid address")
 	}
+	return addr
 }
 
-// Retrieve token balance of an address.
-func getBalance(account sdk.Address) uint64 {
-	bal := sdk.StateGetObject("accs/" + account.String() + "/bal")
-	if bal == nil {
-		return 0
-	} else {
-		amt, _ := strconv.ParseUint(*bal, 10, 64)
-		return amt
-	}
+func ret(v uint64) *string {
+	s := strconv.FormatUint(v, 10)
+	return &s
 }
 
-// Initialize the token contract. Must be called by the Creator address.
+// Initialize the token contract. The caller becomes the owner. Empty fields
+// take the defaults above; maxSupply 0 means uncapped.
 //
+//abi:payload name:string?,symbol:string?,precision:uint64?,maxSupply:uint64?
+//abi:payload none
 //go:wasmexport init
-func Init(a *string) *string {
-	if isInit() {
-		// env.Abort()
+func Init(payload *string) *string {
+	cfg := fungible.Config{Name: Name, Symbol: Symbol, Precision: Precision, MaxSupply: MaxSupply}
+	var p []string
+	if payload != nil && strings.TrimSpace(*payload) != "" {
+		p = strings.Split(strings.TrimSpace(*payload), ",")
+	}
+	if len(p) > 4 {
+		sdk.Abort("expected at most 4 parameters")
+	}
+	if len(p) > 0 && p[0] != "" {
+		cfg.Name = p[0]
+	}
+	if len(p) > 1 && p[1] != "" {
+		cfg.Symbol = p[1]
 	}
-	env := sdk.GetEnv()
-	if env.Caller.Address.String() != Creator {
-		// env.Abort()
+	if len(p) > 2 && p[2] != "" {
+		prec := parseAmount(p[2])
+		if prec > fungible.MaxPrecision {
+			sdk.Abort("invalid precision")
+		}
+		cfg.Precision = uint8(prec)
 	}
-	sdk.StateSetObject("isInit", "1")
-	sdk.StateSetObject("supply", "0")
-	sdk.StateSetObject("owner", Creator)
+	if len(p) > 3 && p[3] != "" {
+		cfg.MaxSupply = parseAmount(p[3])
+	}
+	fungible.Init(cfg)
+	migrate.Init(migrations)
 	return nil
 }
 
-// Mint new tokens to account owner. The caller must be the owner of the token contract.
+// Upgrade state written by an older version of this contract. Owner or
+// consensus only; a token in the legacy layout has no owner until migrated,
+// so consensus migrates it.
 //
+//abi:payload none
+//abi:auth owner
+//go:wasmexport migrate
+func Migrate(_ *string) *string {
+	migrate.Run(migrations)
+	return nil
+}
+
+// Mint new tokens to an address. The caller must be the owner or a minter.
+//
+//abi:payload to:address,amount:uint64
+//abi:auth owner
 //go:wasmexport mint
-func Mint(a *string) *string {
-	owner, isOwner := getOwner()
-	if !isInit() || !isOwner {
-		// env.Abort("Must be owner")
-	}
-	toMint, err := strconv.ParseUint(*a, 10, 64)
-	if err != nil {
-		// env.abort("Invalid amount")
-	}
-	supplyStr := sdk.StateGetObject("supply")
-	supply, _ := strconv.ParseUint(*supplyStr, 10, 64)
-	newSupply := safeAdd(toMint, supply)
-	if newSupply <= MaxSupply {
-		sdk.StateSetObject("supply", strconv.FormatUint(newSupply, 10))
-		incBalance(owner, toMint)
-	} else {
-		// env.Abort()
-	}
+func Mint(payload *string) *string {
+	p := params(payload, 2)
+	fungible.Mint(parseAddress(p[0]), parseAmount(p[1]))
 	return nil
 }
 
-// Burn tokens from contract caller reducing its current total supply.
+// Burn tokens from the caller, reducing the total supply.
 //
+//abi:payload amount:uint64
 //go:wasmexport burn
-func Burn(a *string) *string {
-	assertInit()
-	toBurn, err := strconv.ParseUint(*a, 10, 64)
-	if err != nil {
-		// env.Abort("Invalid amount")
-	}
-	env := sdk.GetEnv()
-	decBalance(env.Caller.Address, toBurn)
-	supplyStr := sdk.StateGetObject("supply")
-	supply, _ := strconv.ParseUint(*supplyStr, 10, 64)
-	newSupply := safeSub(supply, toBurn)
-	sdk.StateSetObject("supply", strconv.FormatUint(newSupply, 10))
+func Burn(payload *string) *string {
+	p := params(payload, 1)
+	fungible.Burn(parseAmount(p[0]))
 	return nil
 }
 
-// Transfer tokens from caller address to another address. Argument is a comma-separated string of destination address and amount.
+// Transfer tokens from the caller to another address.
 //
+//abi:payload to:address,amount:uint64
 //go:wasmexport transfer
-func Transfer(a *string) *string {
-	assertInit()
-	params := strings.Split(*a, ",")
-	if len(params) < 2 {
-		// env.Abort("Invalid number of parameters")
-	}
-	env := sdk.GetEnv()
-	from := env.Caller.Address.String()
-	to := params[0]
-	amt, err := strconv.ParseUint(params[1], 10, 64)
-	if err != nil {
-		// env.Abort("Invalid amount")
+func Transfer(payload *string) *string {
+	p := params(payload, 2)
+	fungible.Transfer(parseAddress(p[0]), parseAmount(p[1]))
+	return nil
+}
+
+// Allow spender to move up to amount of the caller's tokens. 0 revokes.
+//
+//abi:payload spender:address,amount:uint64
+//go:wasmexport approve
+func Approve(payload *string) *string {
+	p := params(payload, 2)
+	fungible.Approve(parseAddress(p[0]), parseAmount(p[1]))
+	return nil
+}
+
+// Move tokens from an address that approved the caller.
+//
+//abi:payload from:address,to:address,amount:uint64
+//go:wasmexport transfer_from
+func TransferFrom(payload *string) *string {
+	p := params(payload, 3)
+	fungible.TransferFrom(parseAddress(p[0]), parseAddress(p[1]), parseAmount(p[2]))
+	return nil
+}
+
+// Balance of an address in units.
+//
+//abi:payload address:address
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport balance_of
+func BalanceOf(payload *string) *string {
+	p := params(payload, 1)
+	return ret(fungible.BalanceOf(parseAddress(p[0])))
+}
+
+// Amount spender may still move for owner.
+//
+//abi:payload owner:address,spender:address
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport allowance
+func Allowance(payload *string) *string {
+	p := params(payload, 2)
+	return ret(fungible.Allowance(parseAddress(p[0]), parseAddress(p[1])))
+}
+
+// Total supply in units.
+//
+//abi:payload none
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport total_supply
+func TotalSupply(_ *string) *string {
+	return ret(fungible.Supply())
+}
+
+// Decimals of one unit.
+//
+//abi:payload none
+//abi:returns uint64
+//abi:mutability view
+//go:wasmexport decimals
+func Decimals(_ *string) *string {
+	fungible.RequireInit()
+	return ret(uint64(fungible.GetConfig().Precision))
+}
+
+// Grant or revoke the minter role. The caller must be the owner.
+//
+//abi:payload address:address,enabled:enum(0|1)
+//abi:auth owner
+//go:wasmexport set_minter
+func SetMinter(payload *string) *string {
+	p := params(payload, 2)
+	addr := parseAddress(p[0])
+	switch p[1] {
+	case "1":
+		access.GrantRole(fungible.RoleMinter, addr)
+	case "0":
+		access.RevokeRole(fungible.RoleMinter, addr)
+	default:
+		sdk.Abort("enabled must be 0 or 1")
 	}
-	decBalance(sdk.Address(from), amt)
-	incBalance(sdk.Address(to), amt)
 	return nil
 }
 
-// Transfer token contract ownership to another address. The caller must be the current owner of the token contract.
+// Propose a new owner of the token contract. The caller must be the current owner of the token contract.
+// Ownership moves once the proposed address calls acceptOwner.
 //
+//abi:payload newOwner:address
+//abi:auth owner
 //go:wasmexport changeOwner
-func ChangeOwner(a *string) *string {
-	assertInit()
-	_, isOwner := getOwner()
-	if isOwner {
-		sdk.StateSetObject("owner", *a)
-	} else {
-		// env.Abort()
-	}
+func ChangeOwner(payload *string) *string {
+	fungible.RequireInit()
+	access.TransferOwnership(parseAddress(*payload))
+	return nil
+}
+
+// Accept a pending ownership transfer. The caller must be the proposed owner.
+//
+//abi:payload none
+//go:wasmexport acceptOwner
+func AcceptOwner(_ *string) *string {
+	fungible.RequireInit()
+	access.AcceptOwnership()
 	return nil
 }
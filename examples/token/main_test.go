@@ -0,0 +1,151 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"testing"
+)
+
+func sptr(s string) *string { return &s }
+
+func expectPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic but none occurred")
+		}
+	}()
+	f()
+}
+
+func view(f func(*string) *string, payload string) string {
+	res := f(sptr(payload))
+	if res == nil {
+		return ""
+	}
+	return *res
+}
+
+func TestToken_InitDefaultsAndPayload(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	expectPanic(t, func() { _ = Decimals(nil) })
+	Init(nil)
+	if view(Decimals, "") != "3" || view(TotalSupply, "") != "0" {
+		t.Fatal("defaults")
+	}
+	expectPanic(t, func() { _ = Init(sptr("Other,OTH")) })
+
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	expectPanic(t, func() { _ = Init(sptr("a,b,c,d,e")) })
+	expectPanic(t, func() { _ = Init(sptr("Gold,GLD,19")) })
+	Init(sptr("Gold,GLD,8,0"))
+	if view(Decimals, "") != "8" {
+		t.Fatal("precision from payload")
+	}
+	Mint(sptr("hive:owner,5000000000000"))
+}
+
+func TestToken_SupplyCapAndAuth(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	Init(sptr("Test,TST,3,1000"))
+	Mint(sptr("hive:alice,1000"))
+	expectPanic(t, func() { _ = Mint(sptr("hive:alice,1")) })
+	expectPanic(t, func() { _ = Mint(sptr("hive:alice,abc")) })
+	expectPanic(t, func() { _ = Mint(sptr("alice,1")) })
+
+	sdk.ShimSetSender("hive:alice")
+	expectPanic(t, func() { _ = SetMinter(sptr("hive:alice,1")) })
+	Burn(sptr("400"))
+	if view(TotalSupply, "") != "600" || view(BalanceOf, "hive:alice") != "600" {
+		t.Fatal("burn")
+	}
+	expectPanic(t, func() { _ = Mint(sptr("hive:alice,1")) })
+
+	sdk.ShimSetSender("hive:owner")
+	SetMinter(sptr("hive:minter,1"))
+	sdk.ShimSetSender("hive:minter")
+	Mint(sptr("hive:bob,400"))
+	sdk.ShimSetSender("hive:owner")
+	SetMinter(sptr("hive:minter,0"))
+	sdk.ShimSetSender("hive:minter")
+	expectPanic(t, func() { _ = Mint(sptr("hive:bob,1")) })
+}
+
+func TestToken_TransfersAndAllowances(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	Init(sptr(""))
+	Mint(sptr("hive:alice,1000"))
+
+	sdk.ShimSetSender("hive:alice")
+	expectPanic(t, func() { _ = Transfer(sptr("hive:bob,1001")) })
+	expectPanic(t, func() { _ = Transfer(sptr("hive:bob")) })
+	Transfer(sptr("hive:bob,250"))
+	Approve(sptr("hive:carol,100"))
+	if view(Allowance, "hive:alice,hive:carol") != "100" {
+		t.Fatal("allowance")
+	}
+
+	sdk.ShimSetSender("hive:carol")
+	expectPanic(t, func() { _ = TransferFrom(sptr("hive:alice,hive:carol,101")) })
+	TransferFrom(sptr("hive:alice,hive:carol,60"))
+	if view(BalanceOf, "hive:alice") != "690" || view(BalanceOf, "hive:bob") != "250" ||
+		view(BalanceOf, "hive:carol") != "60" || view(Allowance, "hive:alice,hive:carol") != "40" {
+		t.Fatal("balances after transfers")
+	}
+	if view(TotalSupply, "") != "1000" {
+		t.Fatal("transfers must not change supply")
+	}
+}
+
+// The token contract is reachable as an sdk.ContractToken, so pools can hold it.
+func TestToken_AsContractToken(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimRegisterContract("contract:token", map[string]func(*string) *string{
+		"transfer": Transfer, "transfer_from": TransferFrom, "balance_of": BalanceOf, "decimals": Decimals,
+	})
+	sdk.ShimWithContract("contract:token", func() {
+		sdk.ShimSetSender("hive:owner")
+		Init(sptr("Test,TST,6,0"))
+		Mint(sptr("hive:alice,1000"))
+		sdk.ShimSetSender("hive:alice")
+		Approve(sptr("contract:pool,300"))
+	})
+
+	sdk.ShimSetContractId("contract:pool")
+	tok := sdk.TokenOf("contract:token/TST")
+	if tok.Decimals() != 6 || tok.BalanceOf("hive:alice") != 1000 {
+		t.Fatal("views through contracts.call")
+	}
+	tok.Draw(300)
+	expectPanic(t, func() { tok.Draw(1) })
+	tok.Transfer("hive:bob", 100)
+	if tok.BalanceOf("hive:alice") != 700 || tok.BalanceOf("contract:pool") != 200 || tok.BalanceOf("hive:bob") != 100 {
+		t.Fatal("balances after draw and transfer")
+	}
+}
+
+func TestToken_MigrateLegacyLayout(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:token")
+	// state of the token this example replaced
+	sdk.StateSetObject("isInit", "1")
+	sdk.StateSetObject("owner", "hive:vaultec.vsc")
+	sdk.StateSetObject("supply", "1000")
+	sdk.StateSetObject("accs/hive:alice/bal", "1000")
+
+	sdk.ShimSetSender("hive:mallory")
+	expectPanic(t, func() { _ = Init(nil) })
+	expectPanic(t, func() { _ = Migrate(nil) })
+
+	sdk.ShimSetSender("system:consensus")
+	Migrate(nil)
+	if view(Decimals, "") != "3" || view(TotalSupply, "") != "1000" || view(BalanceOf, "hive:alice") != "1000" {
+		t.Fatal("balances or config lost in migration")
+	}
+	sdk.ShimSetSender("hive:vaultec.vsc")
+	expectPanic(t, func() { _ = Mint(sptr("hive:vaultec.vsc,999001")) })
+	Mint(sptr("hive:vaultec.vsc,999000"))
+}
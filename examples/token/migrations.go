@@ -0,0 +1,17 @@
+package main
+
+import (
+	"contract-template/sdk/fungible"
+	"contract-template/sdk/migrate"
+)
+
+// Schema history. Version 0 is either a token initialized before schema
+// versions were recorded or the hand written token this example replaced,
+// which kept "isInit" and "owner" and hard coded the defaults in main.go.
+var migrations = []migrate.Step{
+	{Name: "adopt sdk/fungible layout", Up: migrateV0toV1}, // v0 -> v1
+}
+
+func migrateV0toV1() {
+	fungible.MigrateLegacy(fungible.Config{Name: Name, Symbol: Symbol, Precision: Precision, MaxSupply: MaxSupply})
+}
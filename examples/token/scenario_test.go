@@ -0,0 +1,25 @@
+package main
+
+import (
+	"contract-template/sdk/scenario"
+	"testing"
+)
+
+func TestScenario(t *testing.T) {
+	scenario.Run(t, "testdata/scenario.json", scenario.Entrypoints{
+		"init":          Init,
+		"mint":          Mint,
+		"burn":          Burn,
+		"transfer":      Transfer,
+		"approve":       Approve,
+		"transfer_from": TransferFrom,
+		"balance_of":    BalanceOf,
+		"allowance":     Allowance,
+		"total_supply":  TotalSupply,
+		"decimals":      Decimals,
+		"set_minter":    SetMinter,
+		"changeOwner":   ChangeOwner,
+		"acceptOwner":   AcceptOwner,
+		"migrate":       Migrate,
+	})
+}
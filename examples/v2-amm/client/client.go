@@ -0,0 +1,990 @@
+// Code generated by contract client from the v2-amm ABI; DO NOT EDIT.
+
+// Package client is a typed client for the v2-amm contract.
+package client
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"contract-template/tools/vscclient"
+)
+
+type Client struct {
+	ContractId string
+	Caller     string
+	NetId      string
+	RcLimit    uint64
+}
+
+// New returns a client calling contractId as caller on mainnet.
+func New(contractId, caller string) *Client {
+	return &Client{ContractId: contractId, Caller: caller, NetId: vscclient.DefaultNetId, RcLimit: vscclient.DefaultRcLimit}
+}
+
+func (c *Client) call(action, payload string, intents []vscclient.Intent) *vscclient.CallContract {
+	return &vscclient.CallContract{NetId: c.NetId, Caller: c.Caller, ContractId: c.ContractId, Action: action, Payload: payload, RcLimit: c.RcLimit, Intents: intents}
+}
+
+// InitArgs is the payload of init: "asset0,asset1,baseFeeBps?"
+type InitArgs struct {
+	Asset0     string
+	Asset1     string
+	BaseFeeBps *uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a InitArgs) Payload() (string, error) {
+	fields := make([]string, 3)
+	if err := vscclient.CheckField("asset0", a.Asset0); err != nil {
+		return "", err
+	}
+	fields[0] = a.Asset0
+	if err := vscclient.CheckField("asset1", a.Asset1); err != nil {
+		return "", err
+	}
+	fields[1] = a.Asset1
+	if a.BaseFeeBps != nil {
+		fields[2] = strconv.FormatUint(*a.BaseFeeBps, 10)
+	}
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// Init builds a call to init (auth: any, write).
+func (c *Client) Init(args InitArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("init", payload, nil), nil
+}
+
+// AddLiquidityArgs is the payload of add_liquidity: "amt0,amt1,minLP?,deadline?"
+type AddLiquidityArgs struct {
+	Amt0     uint64
+	Amt1     uint64
+	MinLP    *uint64
+	Deadline string // optional; empty omits it
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a AddLiquidityArgs) Payload() (string, error) {
+	fields := make([]string, 4)
+	fields[0] = strconv.FormatUint(a.Amt0, 10)
+	fields[1] = strconv.FormatUint(a.Amt1, 10)
+	if a.MinLP != nil {
+		fields[2] = strconv.FormatUint(*a.MinLP, 10)
+	}
+	if err := vscclient.CheckField("deadline", a.Deadline); err != nil {
+		return "", err
+	}
+	fields[3] = a.Deadline
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// AddLiquidity builds a call to add_liquidity (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) AddLiquidity(args AddLiquidityArgs, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("add_liquidity", payload, allow), nil
+}
+
+// RemoveLiquidityArgs is the payload of remove_liquidity: "lpAmount,minAmount0?,minAmount1?,deadline?"
+type RemoveLiquidityArgs struct {
+	LpAmount   uint64
+	MinAmount0 *uint64
+	MinAmount1 *uint64
+	Deadline   string // optional; empty omits it
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a RemoveLiquidityArgs) Payload() (string, error) {
+	fields := make([]string, 4)
+	fields[0] = strconv.FormatUint(a.LpAmount, 10)
+	if a.MinAmount0 != nil {
+		fields[1] = strconv.FormatUint(*a.MinAmount0, 10)
+	}
+	if a.MinAmount1 != nil {
+		fields[2] = strconv.FormatUint(*a.MinAmount1, 10)
+	}
+	if err := vscclient.CheckField("deadline", a.Deadline); err != nil {
+		return "", err
+	}
+	fields[3] = a.Deadline
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// RemoveLiquidity builds a call to remove_liquidity (auth: any, write).
+func (c *Client) RemoveLiquidity(args RemoveLiquidityArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("remove_liquidity", payload, nil), nil
+}
+
+// QuoteAddLiquidityArgs is the payload of quote_add_liquidity: "amt0,amt1"
+type QuoteAddLiquidityArgs struct {
+	Amt0 uint64
+	Amt1 uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a QuoteAddLiquidityArgs) Payload() (string, error) {
+	fields := make([]string, 2)
+	fields[0] = strconv.FormatUint(a.Amt0, 10)
+	fields[1] = strconv.FormatUint(a.Amt1, 10)
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// QuoteAddLiquidity builds a call to quote_add_liquidity (auth: any, view).
+func (c *Client) QuoteAddLiquidity(args QuoteAddLiquidityArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("quote_add_liquidity", payload, nil), nil
+}
+
+// DecodeQuoteAddLiquidity unmarshals the JSON returned by quote_add_liquidity into v.
+func DecodeQuoteAddLiquidity(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// QuoteRemoveLiquidityArgs is the payload of quote_remove_liquidity: "lpAmount"
+type QuoteRemoveLiquidityArgs struct {
+	LpAmount uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a QuoteRemoveLiquidityArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	fields[0] = strconv.FormatUint(a.LpAmount, 10)
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// QuoteRemoveLiquidity builds a call to quote_remove_liquidity (auth: any, view).
+func (c *Client) QuoteRemoveLiquidity(args QuoteRemoveLiquidityArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("quote_remove_liquidity", payload, nil), nil
+}
+
+// DecodeQuoteRemoveLiquidity unmarshals the JSON returned by quote_remove_liquidity into v.
+func DecodeQuoteRemoveLiquidity(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// GetReserves builds a call to get_reserves (auth: any, view).
+func (c *Client) GetReserves() (*vscclient.CallContract, error) {
+	return c.call("get_reserves", "", nil), nil
+}
+
+// DecodeGetReserves unmarshals the JSON returned by get_reserves into v.
+func DecodeGetReserves(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// GetAmountOutArgs is the payload of get_amount_out: "dir,amountIn,refBps?"
+type GetAmountOutArgs struct {
+	Dir      string // one of 0to1|1to0
+	AmountIn uint64
+	RefBps   *uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a GetAmountOutArgs) Payload() (string, error) {
+	fields := make([]string, 3)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountIn, 10)
+	if a.RefBps != nil {
+		fields[2] = strconv.FormatUint(*a.RefBps, 10)
+	}
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// GetAmountOut builds a call to get_amount_out (auth: any, view).
+func (c *Client) GetAmountOut(args GetAmountOutArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("get_amount_out", payload, nil), nil
+}
+
+// DecodeGetAmountOut unmarshals the JSON returned by get_amount_out into v.
+func DecodeGetAmountOut(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// GetAmountInArgs is the payload of get_amount_in: "dir,amountOut,refBps?"
+type GetAmountInArgs struct {
+	Dir       string // one of 0to1|1to0
+	AmountOut uint64
+	RefBps    *uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a GetAmountInArgs) Payload() (string, error) {
+	fields := make([]string, 3)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountOut, 10)
+	if a.RefBps != nil {
+		fields[2] = strconv.FormatUint(*a.RefBps, 10)
+	}
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// GetAmountIn builds a call to get_amount_in (auth: any, view).
+func (c *Client) GetAmountIn(args GetAmountInArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("get_amount_in", payload, nil), nil
+}
+
+// DecodeGetAmountIn unmarshals the JSON returned by get_amount_in into v.
+func DecodeGetAmountIn(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// GetLpBalanceArgs is the payload of get_lp_balance: "address"
+type GetLpBalanceArgs struct {
+	Address string
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a GetLpBalanceArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	if err := vscclient.CheckField("address", a.Address); err != nil {
+		return "", err
+	}
+	fields[0] = a.Address
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// GetLpBalance builds a call to get_lp_balance (auth: any, view).
+func (c *Client) GetLpBalance(args GetLpBalanceArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("get_lp_balance", payload, nil), nil
+}
+
+// DecodeGetLpBalance unmarshals the JSON returned by get_lp_balance into v.
+func DecodeGetLpBalance(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// GetPoolConfig builds a call to get_pool_config (auth: any, view).
+func (c *Client) GetPoolConfig() (*vscclient.CallContract, error) {
+	return c.call("get_pool_config", "", nil), nil
+}
+
+// DecodeGetPoolConfig unmarshals the JSON returned by get_pool_config into v.
+func DecodeGetPoolConfig(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// GetClaimableFees builds a call to get_claimable_fees (auth: any, view).
+func (c *Client) GetClaimableFees() (*vscclient.CallContract, error) {
+	return c.call("get_claimable_fees", "", nil), nil
+}
+
+// DecodeGetClaimableFees unmarshals the JSON returned by get_claimable_fees into v.
+func DecodeGetClaimableFees(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// SwapArgs is the payload of swap: "dir,amountIn,minOut?"
+type SwapArgs struct {
+	Dir      string // one of 0to1|1to0
+	AmountIn uint64
+	MinOut   *uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SwapArgs) Payload() (string, error) {
+	fields := make([]string, 3)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountIn, 10)
+	if a.MinOut != nil {
+		fields[2] = strconv.FormatUint(*a.MinOut, 10)
+	}
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// Swap builds a call to swap (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) Swap(args SwapArgs, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("swap", payload, allow), nil
+}
+
+// Swap2Args is the payload of swap: "dir,amountIn,beneficiary,refBps"
+type Swap2Args struct {
+	Dir         string // one of 0to1|1to0
+	AmountIn    uint64
+	Beneficiary string
+	RefBps      uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a Swap2Args) Payload() (string, error) {
+	fields := make([]string, 4)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountIn, 10)
+	if err := vscclient.CheckField("beneficiary", a.Beneficiary); err != nil {
+		return "", err
+	}
+	fields[2] = a.Beneficiary
+	fields[3] = strconv.FormatUint(a.RefBps, 10)
+	return vscclient.JoinPayload(fields, 4), nil
+}
+
+// Swap2 builds a call to swap (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) Swap2(args Swap2Args, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("swap", payload, allow), nil
+}
+
+// Swap3Args is the payload of swap: "dir,amountIn,minOut?,beneficiary,refBps"
+type Swap3Args struct {
+	Dir         string // one of 0to1|1to0
+	AmountIn    uint64
+	MinOut      *uint64
+	Beneficiary string
+	RefBps      uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a Swap3Args) Payload() (string, error) {
+	fields := make([]string, 5)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountIn, 10)
+	if a.MinOut != nil {
+		fields[2] = strconv.FormatUint(*a.MinOut, 10)
+	}
+	if err := vscclient.CheckField("beneficiary", a.Beneficiary); err != nil {
+		return "", err
+	}
+	fields[3] = a.Beneficiary
+	fields[4] = strconv.FormatUint(a.RefBps, 10)
+	return vscclient.JoinPayload(fields, 5), nil
+}
+
+// Swap3 builds a call to swap (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) Swap3(args Swap3Args, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("swap", payload, allow), nil
+}
+
+// Swap4Args is the payload of swap: "dir,amountIn,minOut?,beneficiary?,refBps?,deadline?"
+type Swap4Args struct {
+	Dir         string // one of 0to1|1to0
+	AmountIn    uint64
+	MinOut      *uint64
+	Beneficiary string // optional; empty omits it
+	RefBps      *uint64
+	Deadline    string // optional; empty omits it
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a Swap4Args) Payload() (string, error) {
+	fields := make([]string, 6)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountIn, 10)
+	if a.MinOut != nil {
+		fields[2] = strconv.FormatUint(*a.MinOut, 10)
+	}
+	if err := vscclient.CheckField("beneficiary", a.Beneficiary); err != nil {
+		return "", err
+	}
+	fields[3] = a.Beneficiary
+	if a.RefBps != nil {
+		fields[4] = strconv.FormatUint(*a.RefBps, 10)
+	}
+	if err := vscclient.CheckField("deadline", a.Deadline); err != nil {
+		return "", err
+	}
+	fields[5] = a.Deadline
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// Swap4 builds a call to swap (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) Swap4(args Swap4Args, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("swap", payload, allow), nil
+}
+
+// Swap5Args is the payload of swap: "dir,amountOut,mode,target,data?"
+type Swap5Args struct {
+	Dir       string // one of 0to1|1to0
+	AmountOut uint64
+	Mode      string // one of flash
+	Target    string
+	Data      string // optional; empty omits it
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a Swap5Args) Payload() (string, error) {
+	fields := make([]string, 5)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountOut, 10)
+	if err := vscclient.CheckEnum("mode", a.Mode, "flash"); err != nil {
+		return "", err
+	}
+	fields[2] = a.Mode
+	if err := vscclient.CheckField("target", a.Target); err != nil {
+		return "", err
+	}
+	fields[3] = a.Target
+	if err := vscclient.CheckField("data", a.Data); err != nil {
+		return "", err
+	}
+	fields[4] = a.Data
+	return vscclient.JoinPayload(fields, 4), nil
+}
+
+// Swap5 builds a call to swap (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) Swap5(args Swap5Args, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("swap", payload, allow), nil
+}
+
+// SwapExactOutArgs is the payload of swap_exact_out: "dir,amountOut,maxIn"
+type SwapExactOutArgs struct {
+	Dir       string // one of 0to1|1to0
+	AmountOut uint64
+	MaxIn     uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SwapExactOutArgs) Payload() (string, error) {
+	fields := make([]string, 3)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountOut, 10)
+	fields[2] = strconv.FormatUint(a.MaxIn, 10)
+	return vscclient.JoinPayload(fields, 3), nil
+}
+
+// SwapExactOut builds a call to swap_exact_out (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) SwapExactOut(args SwapExactOutArgs, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("swap_exact_out", payload, allow), nil
+}
+
+// SwapExactOut2Args is the payload of swap_exact_out: "dir,amountOut,maxIn,beneficiary,refBps"
+type SwapExactOut2Args struct {
+	Dir         string // one of 0to1|1to0
+	AmountOut   uint64
+	MaxIn       uint64
+	Beneficiary string
+	RefBps      uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SwapExactOut2Args) Payload() (string, error) {
+	fields := make([]string, 5)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountOut, 10)
+	fields[2] = strconv.FormatUint(a.MaxIn, 10)
+	if err := vscclient.CheckField("beneficiary", a.Beneficiary); err != nil {
+		return "", err
+	}
+	fields[3] = a.Beneficiary
+	fields[4] = strconv.FormatUint(a.RefBps, 10)
+	return vscclient.JoinPayload(fields, 5), nil
+}
+
+// SwapExactOut2 builds a call to swap_exact_out (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) SwapExactOut2(args SwapExactOut2Args, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("swap_exact_out", payload, allow), nil
+}
+
+// SwapExactOut3Args is the payload of swap_exact_out: "dir,amountOut,maxIn,beneficiary?,refBps?,deadline?"
+type SwapExactOut3Args struct {
+	Dir         string // one of 0to1|1to0
+	AmountOut   uint64
+	MaxIn       uint64
+	Beneficiary string // optional; empty omits it
+	RefBps      *uint64
+	Deadline    string // optional; empty omits it
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SwapExactOut3Args) Payload() (string, error) {
+	fields := make([]string, 6)
+	if err := vscclient.CheckEnum("dir", a.Dir, "0to1", "1to0"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Dir
+	fields[1] = strconv.FormatUint(a.AmountOut, 10)
+	fields[2] = strconv.FormatUint(a.MaxIn, 10)
+	if err := vscclient.CheckField("beneficiary", a.Beneficiary); err != nil {
+		return "", err
+	}
+	fields[3] = a.Beneficiary
+	if a.RefBps != nil {
+		fields[4] = strconv.FormatUint(*a.RefBps, 10)
+	}
+	if err := vscclient.CheckField("deadline", a.Deadline); err != nil {
+		return "", err
+	}
+	fields[5] = a.Deadline
+	return vscclient.JoinPayload(fields, 3), nil
+}
+
+// SwapExactOut3 builds a call to swap_exact_out (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) SwapExactOut3(args SwapExactOut3Args, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("swap_exact_out", payload, allow), nil
+}
+
+// DecodeSwapExactOut returns the value returned by swap_exact_out.
+func DecodeSwapExactOut(envelope []byte) (uint64, error) {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(ret, 10, 64)
+}
+
+// DonateArgs is the payload of donate: "amt0,amt1"
+type DonateArgs struct {
+	Amt0 uint64
+	Amt1 uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a DonateArgs) Payload() (string, error) {
+	fields := make([]string, 2)
+	fields[0] = strconv.FormatUint(a.Amt0, 10)
+	fields[1] = strconv.FormatUint(a.Amt1, 10)
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// Donate builds a call to donate (auth: any, payable).
+// Pass vscclient.Allow intents covering every amount the contract draws.
+func (c *Client) Donate(args DonateArgs, allow ...vscclient.Intent) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("donate", payload, allow), nil
+}
+
+// ClaimFees builds a call to claim_fees (auth: system, write).
+func (c *Client) ClaimFees() (*vscclient.CallContract, error) {
+	return c.call("claim_fees", "", nil), nil
+}
+
+// Observe builds a call to observe (auth: any, view).
+func (c *Client) Observe() (*vscclient.CallContract, error) {
+	return c.call("observe", "", nil), nil
+}
+
+// DecodeObserve unmarshals the JSON returned by observe into v.
+func DecodeObserve(envelope []byte, v any) error {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(ret), v)
+}
+
+// BurnArgs is the payload of burn: "lpAmount"
+type BurnArgs struct {
+	LpAmount uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a BurnArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	fields[0] = strconv.FormatUint(a.LpAmount, 10)
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// Burn builds a call to burn (auth: any, write).
+func (c *Client) Burn(args BurnArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("burn", payload, nil), nil
+}
+
+// TransferArgs is the payload of transfer: "to,amount"
+type TransferArgs struct {
+	To     string
+	Amount uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a TransferArgs) Payload() (string, error) {
+	fields := make([]string, 2)
+	if err := vscclient.CheckField("to", a.To); err != nil {
+		return "", err
+	}
+	fields[0] = a.To
+	fields[1] = strconv.FormatUint(a.Amount, 10)
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// Transfer builds a call to transfer (auth: any, write).
+func (c *Client) Transfer(args TransferArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("transfer", payload, nil), nil
+}
+
+// SiWithdrawArgs is the payload of si_withdraw: "address,lpAmount"
+type SiWithdrawArgs struct {
+	Address  string
+	LpAmount uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SiWithdrawArgs) Payload() (string, error) {
+	fields := make([]string, 2)
+	if err := vscclient.CheckField("address", a.Address); err != nil {
+		return "", err
+	}
+	fields[0] = a.Address
+	fields[1] = strconv.FormatUint(a.LpAmount, 10)
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// SiWithdraw builds a call to si_withdraw (auth: system, write).
+func (c *Client) SiWithdraw(args SiWithdrawArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("si_withdraw", payload, nil), nil
+}
+
+// SiUnwindArgs is the payload of si_unwind: "limit"
+type SiUnwindArgs struct {
+	Limit uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SiUnwindArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	fields[0] = strconv.FormatUint(a.Limit, 10)
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// SiUnwind builds a call to si_unwind (auth: system, write).
+func (c *Client) SiUnwind(args SiUnwindArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("si_unwind", payload, nil), nil
+}
+
+// DecodeSiUnwind returns the value returned by si_unwind.
+func DecodeSiUnwind(envelope []byte) (uint64, error) {
+	ret, err := vscclient.DecodeResult(envelope)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(ret, 10, 64)
+}
+
+// SiIndexHoldersArgs is the payload of si_index_holders: "addresses"
+type SiIndexHoldersArgs struct {
+	Addresses string
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SiIndexHoldersArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	if err := vscclient.CheckField("addresses", a.Addresses); err != nil {
+		return "", err
+	}
+	fields[0] = a.Addresses
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// SiIndexHolders builds a call to si_index_holders (auth: system, write).
+func (c *Client) SiIndexHolders(args SiIndexHoldersArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("si_index_holders", payload, nil), nil
+}
+
+// SetBaseFeeArgs is the payload of set_base_fee: "newBps"
+type SetBaseFeeArgs struct {
+	NewBps uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SetBaseFeeArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	fields[0] = strconv.FormatUint(a.NewBps, 10)
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// SetBaseFee builds a call to set_base_fee (auth: system, write).
+func (c *Client) SetBaseFee(args SetBaseFeeArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("set_base_fee", payload, nil), nil
+}
+
+// SetSlipParamsArgs is the payload of set_slip_params: "baselineBps,shareBps"
+type SetSlipParamsArgs struct {
+	BaselineBps uint64
+	ShareBps    uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SetSlipParamsArgs) Payload() (string, error) {
+	fields := make([]string, 2)
+	fields[0] = strconv.FormatUint(a.BaselineBps, 10)
+	fields[1] = strconv.FormatUint(a.ShareBps, 10)
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// SetSlipParams builds a call to set_slip_params (auth: system, write).
+func (c *Client) SetSlipParams(args SetSlipParamsArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("set_slip_params", payload, nil), nil
+}
+
+// SetFeeClaimIntervalArgs is the payload of set_fee_claim_interval: "seconds"
+type SetFeeClaimIntervalArgs struct {
+	Seconds uint64
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SetFeeClaimIntervalArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	fields[0] = strconv.FormatUint(a.Seconds, 10)
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// SetFeeClaimInterval builds a call to set_fee_claim_interval (auth: system, write).
+func (c *Client) SetFeeClaimInterval(args SetFeeClaimIntervalArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("set_fee_claim_interval", payload, nil), nil
+}
+
+// SetPausedArgs is the payload of set_paused: "paused"
+type SetPausedArgs struct {
+	Paused string // one of 0|1
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SetPausedArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	if err := vscclient.CheckEnum("paused", a.Paused, "0", "1"); err != nil {
+		return "", err
+	}
+	fields[0] = a.Paused
+	return vscclient.JoinPayload(fields, 1), nil
+}
+
+// SetPaused builds a call to set_paused (auth: system, write).
+func (c *Client) SetPaused(args SetPausedArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("set_paused", payload, nil), nil
+}
+
+// SetPaused2Args is the payload of set_paused: "entrypoint,paused"
+type SetPaused2Args struct {
+	Entrypoint string
+	Paused     string // one of 0|1
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SetPaused2Args) Payload() (string, error) {
+	fields := make([]string, 2)
+	if err := vscclient.CheckField("entrypoint", a.Entrypoint); err != nil {
+		return "", err
+	}
+	fields[0] = a.Entrypoint
+	if err := vscclient.CheckEnum("paused", a.Paused, "0", "1"); err != nil {
+		return "", err
+	}
+	fields[1] = a.Paused
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// SetPaused2 builds a call to set_paused (auth: system, write).
+func (c *Client) SetPaused2(args SetPaused2Args) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("set_paused", payload, nil), nil
+}
+
+// PauseArgs is the payload of pause: "entrypoint?"
+type PauseArgs struct {
+	Entrypoint string // optional; empty omits it
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a PauseArgs) Payload() (string, error) {
+	fields := make([]string, 1)
+	if err := vscclient.CheckField("entrypoint", a.Entrypoint); err != nil {
+		return "", err
+	}
+	fields[0] = a.Entrypoint
+	return vscclient.JoinPayload(fields, 0), nil
+}
+
+// Pause builds a call to pause (auth: role:guardian, write).
+func (c *Client) Pause(args PauseArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("pause", payload, nil), nil
+}
+
+// SetGuardianArgs is the payload of set_guardian: "address,enabled"
+type SetGuardianArgs struct {
+	Address string
+	Enabled string // one of 0|1
+}
+
+// Payload encodes the arguments in the contract's comma separated form.
+func (a SetGuardianArgs) Payload() (string, error) {
+	fields := make([]string, 2)
+	if err := vscclient.CheckField("address", a.Address); err != nil {
+		return "", err
+	}
+	fields[0] = a.Address
+	if err := vscclient.CheckEnum("enabled", a.Enabled, "0", "1"); err != nil {
+		return "", err
+	}
+	fields[1] = a.Enabled
+	return vscclient.JoinPayload(fields, 2), nil
+}
+
+// SetGuardian builds a call to set_guardian (auth: system, write).
+func (c *Client) SetGuardian(args SetGuardianArgs) (*vscclient.CallContract, error) {
+	payload, err := args.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return c.call("set_guardian", payload, nil), nil
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"contract-template/tools/vscclient"
+	"encoding/json"
+	"testing"
+)
+
+func TestClient_SwapTransaction(t *testing.T) {
+	c := New("contract:v2amm", "hive:bob")
+	minOut := uint64(9000)
+	tx, err := c.Swap3(Swap3Args{Dir: "0to1", AmountIn: 10000, MinOut: &minOut, Beneficiary: "hive:ref", RefBps: 50},
+		vscclient.Allow("hbd", 10000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Action != "swap" || tx.Payload != "0to1,10000,9000,hive:ref,50" {
+		t.Fatalf("unexpected call: %+v", tx)
+	}
+	op, err := tx.Op(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Id != "vsc.call_contract" || len(op.RequiredAuths) != 1 || op.RequiredAuths[0] != "bob" {
+		t.Fatalf("unexpected op: %+v", op)
+	}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(op.Json), &body); err != nil {
+		t.Fatal(err)
+	}
+	intent := body["intents"].([]any)[0].(map[string]any)
+	if intent["type"] != "transfer.allow" || intent["args"].(map[string]any)["limit"] != "10.000" {
+		t.Fatalf("unexpected intent: %v", intent)
+	}
+
+	// optional trailing minOut is dropped
+	tx, _ = c.Swap(SwapArgs{Dir: "1to0", AmountIn: 5})
+	if tx.Payload != "1to0,5" {
+		t.Fatalf("payload = %q", tx.Payload)
+	}
+	if _, err := c.Swap(SwapArgs{Dir: "sideways", AmountIn: 5}); err == nil {
+		t.Fatal("invalid enum must be rejected")
+	}
+	if _, err := c.Transfer(TransferArgs{To: "hive:a,hive:b", Amount: 1}); err == nil {
+		t.Fatal("comma in address must be rejected")
+	}
+}
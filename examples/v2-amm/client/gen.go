@@ -0,0 +1,3 @@
+package client
+
+//go:generate go run ../../../cmd/contract client -pkg client -o client.go ..
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"strconv"
+)
+
+// stateKey names an entry in contract state. Every key the pool writes is
+// declared here so its state layout reads in one place.
+type stateKey string
+
+const (
+	keyAsset0            stateKey = "pool/asset0"
+	keyAsset1            stateKey = "pool/asset1"
+	keyReserve0          stateKey = "pool/reserve0"
+	keyReserve1          stateKey = "pool/reserve1"
+	keyFee0              stateKey = "pool/fee0"
+	keyFee1              stateKey = "pool/fee1"
+	keyFeeLastClaimUnix  stateKey = "pool/fee_last_claim"
+	keyBaseFeeBps        stateKey = "pool/base_fee_bps"
+	keyFeeClaimIntervalS stateKey = "pool/fee_claim_interval_s"
+	keyTotalLP           stateKey = "pool/total_lp"
+	keyLPPrefix          stateKey = "lps/" // lps/<address>
+	keySlipBaselineBps   stateKey = "pool/slip_baseline_bps"
+	keySlipShareBps      stateKey = "pool/slip_share_bps"
+	keyPrice0Cumulative  stateKey = "pool/price0_cumulative"
+	keyPrice1Cumulative  stateKey = "pool/price1_cumulative"
+	keyPriceTime         stateKey = "pool/price_time"
+	keyHolderCount       stateKey = "pool/holder_count"
+	keyHolderPrefix      stateKey = "holders/"    // holders/<index> = address
+	keyHolderIdxPrefix   stateKey = "holder_idx/" // holder_idx/<address> = index+1
+	keyUnwindCursor      stateKey = "pool/unwind_cursor"
+	keyFeeConvertObs     stateKey = "pool/fee_convert_obs" // observation starting the conversion TWAP window
+)
+
+func lpKey(addr sdk.Address) stateKey {
+	return keyLPPrefix + stateKey(addr.String())
+}
+
+func holderKey(i uint64) stateKey {
+	return keyHolderPrefix + stateKey(strconv.FormatUint(i, 10))
+}
+
+func holderIdxKey(addr sdk.Address) stateKey {
+	return keyHolderIdxPrefix + stateKey(addr.String())
+}
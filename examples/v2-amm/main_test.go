@@ -2,7 +2,11 @@ package main
 
 import (
 	"contract-template/sdk"
+	"contract-template/sdk/twap"
+	"encoding/json"
+	"math"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -133,6 +137,7 @@ func TestV2_Donate_Transfer_Burn_ClaimFees_System(t *testing.T) {
 	// Transfer LP to another address
 	sdk.ShimSetSender(sdk.Address("hive:lp1"))
 	lpOwned := getLP(sdk.Address("hive:lp1"))
+	expectPanic(t, func() { _ = Transfer(sptr("lp2," + strconv.FormatUint(lpOwned/2, 10))) })
 	Transfer(sptr("hive:lp2," + strconv.FormatUint(lpOwned/2, 10)))
 	if getLP(sdk.Address("hive:lp1")) != lpOwned/2 {
 		t.Fatal("sender LP not reduced")
@@ -155,9 +160,11 @@ func TestV2_Donate_Transfer_Burn_ClaimFees_System(t *testing.T) {
 	if getInt(keyFee0) <= 0 {
 		t.Fatal("no fee accrued on 0 side")
 	}
-	// Claim must be system-only now; sends to system:fr_balance
+	// Claim must be system-only now; sends to system:fr_balance once the
+	// claim interval has passed
 	preFR := sdk.ShimGetBalance(sdk.Address("system:fr_balance"), sdk.AssetHbd)
 	sdk.ShimSetSender(sdk.Address("system:consensus"))
+	sdk.ShimSetTimestamp(strconv.Itoa(defaultFeeClaimIntervalS))
 	ClaimFees(nil)
 	if sdk.ShimGetBalance(sdk.Address("system:fr_balance"), sdk.AssetHbd) <= preFR {
 		t.Fatal("fees not transferred to system FR")
@@ -255,6 +262,16 @@ func TestV2_Referral_Bounds_And_MinOut(t *testing.T) {
 	_ = Swap(sptr("0to1,1000,,hive:ref,1"))
 	_ = Swap(sptr("0to1,1000,,hive:ref,1000"))
 
+	// malformed beneficiaries are rejected before any funds move
+	preBal := sdk.ShimGetBalance(sdk.Address("hive:trader"), sdk.AssetHbd)
+	for _, b := range []string{"hive:x", "ref", "hive:Ref", "did:pkh:eip155:1:0x12", "contract:"} {
+		expectPanic(t, func() { _ = Swap(sptr("0to1,1000,," + b + ",10")) })
+		expectPanic(t, func() { _ = Swap(sptr("0to1,1000," + b + ",10")) })
+	}
+	if sdk.ShimGetBalance(sdk.Address("hive:trader"), sdk.AssetHbd) != preBal {
+		t.Fatal("rejected swap moved funds")
+	}
+
 	// 1to0 minOut applies to net after referral
 	sdk.ShimSetSender(sdk.Address("hive:trader2"))
 	sdk.ShimSetBalance(sdk.Address("hive:trader2"), sdk.AssetHive, 100000)
@@ -324,8 +341,9 @@ func TestV2_InvalidDir_And_NonSystem_Claim(t *testing.T) {
 	// non-system claim should panic
 	expectPanic(t, func() { _ = ClaimFees(nil) })
 
-	// system claim should succeed
+	// system claim should succeed after the claim interval
 	sdk.ShimSetSender(sdk.Address("system:consensus"))
+	sdk.ShimSetTimestamp(strconv.Itoa(defaultFeeClaimIntervalS))
 	_ = ClaimFees(nil)
 }
 
@@ -408,3 +426,778 @@ func TestV2_Swap_Referral_Beneficiary(t *testing.T) {
 		t.Fatal("user did not receive net HBD after referral")
 	}
 }
+
+func TestV2_GuardianPause_SystemUnpause(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	sdk.ShimSetSender(sdk.Address("hive:lp"))
+	Init(sptr("hbd,hive,8"))
+	sdk.ShimSetBalance(sdk.Address("hive:lp"), sdk.AssetHbd, 100000)
+	sdk.ShimSetBalance(sdk.Address("hive:lp"), sdk.AssetHive, 100000)
+	AddLiquidity(sptr("50000,50000"))
+
+	// only consensus appoints guardians
+	expectPanic(t, func() { _ = SetGuardian(sptr("hive:guard,1")) })
+	sdk.ShimSetSender(sdk.Address("system:consensus"))
+	SetGuardian(sptr("hive:guard,1"))
+
+	// non-guardians cannot pause
+	sdk.ShimSetSender(sdk.Address("hive:trader"))
+	expectPanic(t, func() { _ = Pause(sptr("swap")) })
+
+	// guardian pauses swaps only
+	sdk.ShimSetSender(sdk.Address("hive:guard"))
+	Pause(sptr("swap"))
+	expectPanic(t, func() { _ = SetPaused(sptr("swap,0")) })
+	sdk.ShimSetSender(sdk.Address("hive:trader"))
+	sdk.ShimSetBalance(sdk.Address("hive:trader"), sdk.AssetHbd, 10000)
+	expectPanic(t, func() { _ = Swap(sptr("0to1,1000")) })
+	sdk.ShimSetSender(sdk.Address("hive:lp"))
+	AddLiquidity(sptr("1000,1000"))
+
+	// consensus lifts the pause
+	sdk.ShimSetSender(sdk.Address("system:consensus"))
+	SetPaused(sptr("swap,0"))
+	sdk.ShimSetSender(sdk.Address("hive:trader"))
+	_ = Swap(sptr("0to1,1000"))
+}
+
+func TestV2_ContractTokenPool(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	pool := sdk.Address("contract:v2")
+	alice, bob := sdk.Address("hive:alice"), sdk.Address("hive:bob")
+	tok := sdk.ShimRegisterToken(sdk.Address("contract:tok"), "TOK", 3)
+	asset := sdk.Asset(tok.Id())
+
+	expectPanic(t, func() { _ = Init(sptr("hbd,contract:tok")) })
+	expectPanic(t, func() { _ = Init(sptr("hbd,hbd")) })
+	Init(sptr("hbd,contract:tok/TOK,30"))
+
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 100_000)
+	sdk.ShimSetBalance(alice, asset, 500_000)
+	// token draws need an allowance for the pool
+	expectPanic(t, func() { _ = AddLiquidity(sptr("100000,200000")) })
+	sdk.ShimApproveToken(tok, alice, pool, 200_000)
+	AddLiquidity(sptr("100000,200000"))
+	if sdk.ShimGetBalance(alice, asset) != 300_000 || sdk.ShimGetBalance(pool, asset) != 200_000 || tok.BalanceOf(pool) != 200_000 {
+		t.Fatal("token not drawn into the pool")
+	}
+
+	// bob sells TOK for HBD
+	sdk.ShimSetSender(bob)
+	sdk.ShimSetBalance(bob, asset, 10_000)
+	sdk.ShimApproveToken(tok, bob, pool, 10_000)
+	preR0 := getInt(keyReserve0)
+	Swap(sptr("1to0,10000"))
+	got := sdk.ShimGetBalance(bob, sdk.AssetHbd)
+	if got <= 0 || sdk.ShimGetBalance(bob, asset) != 0 || getInt(keyReserve0) >= preR0 {
+		t.Fatalf("swap paid %d hbd", got)
+	}
+
+	// alice withdraws everything, including TOK sent back by the token
+	// contract; only the locked minimum liquidity's share stays
+	sdk.ShimSetSender(alice)
+	RemoveLiquidity(sptr(strconv.FormatUint(getLP(alice), 10)))
+	if sdk.ShimGetBalance(pool, asset) != getInt(keyReserve1) || getUint(keyTotalLP) != minimumLiquidity ||
+		sdk.ShimGetBalance(pool, asset)+sdk.ShimGetBalance(alice, asset) != 510_000 {
+		t.Fatalf("pool tok %d, alice tok %d", sdk.ShimGetBalance(pool, asset), sdk.ShimGetBalance(alice, asset))
+	}
+}
+
+func TestV2_PriceOracle(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 1_000_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 1_000_000)
+	observe := func() twap.Observation {
+		o, err := twap.ParseObservation(*Observe(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return o
+	}
+
+	sdk.ShimSetTimestamp("2025-06-01T00:00:00")
+	Init(sptr("hbd,hive,0"))
+	sdk.ShimSetTimestamp("2025-06-01T00:00:30") // empty pool: nothing accrues
+	AddLiquidity(sptr("100000,400000"))
+	start := observe()
+	if start.Price0 != (twap.Cumulative{}) || start.Time != 1748736030 {
+		t.Fatalf("start = %+v", start)
+	}
+
+	// 4 HIVE per HBD for 60s, then 1 HIVE per HBD for 20s
+	sdk.ShimSetTimestamp("2025-06-01T00:01:30")
+	Swap(sptr("0to1,100000"))
+	if getInt(keyReserve0) != 200000 || getInt(keyReserve1) != 200000 {
+		t.Fatalf("reserves %d/%d", getInt(keyReserve0), getInt(keyReserve1))
+	}
+	sdk.ShimSetTimestamp("2025-06-01T00:01:50")
+	// the view advances to the current block without a write
+	stored := getObservation()
+	end := observe()
+	if getObservation() != stored || end.Time != start.Time+80 {
+		t.Fatal("observe must not write")
+	}
+	p0, p1, err := twap.Average(start, end)
+	// price0 = (4*60 + 1*20)/80 = 3.25 HIVE/HBD; price1 = (0.25*60 + 1*20)/80 = 0.4375
+	if err != nil || p0 != twap.Ratio(13, 4) || p1 != twap.Ratio(7, 16) {
+		t.Fatalf("twap %v %v %v", p0, p1, err)
+	}
+	if p0.Mul(1000) != 3250 {
+		t.Fatal("quote at twap")
+	}
+
+	// another contract reads the pool through contracts.call
+	sdk.ShimRegisterContract("contract:v2", map[string]func(*string) *string{"observe": Observe})
+	var seen twap.Observation
+	sdk.ShimWithContract("contract:lender", func() { seen = twap.Observe("contract:v2") })
+	if seen != end {
+		t.Fatal("cross-contract observe")
+	}
+}
+
+func TestV2_FlashSwap(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	pool, arb := sdk.Address("contract:v2"), sdk.Address("contract:arb")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 1_000_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 1_000_000)
+	Init(sptr("hbd,hive,30"))
+	AddLiquidity(sptr("100000,200000"))
+	sdk.ShimRegisterContract(pool, map[string]func(*string) *string{"swap": Swap, "donate": Donate})
+
+	// the price of 10000 HBD in a regular swap
+	restore := sdk.ShimSnapshot()
+	Swap(sptr("0to1,10000"))
+	out := uint64(sdk.ShimGetBalance(alice, sdk.AssetHive) - 1_000_000 + 200_000)
+	wantR0, wantR1, wantFee := getInt(keyReserve0), getInt(keyReserve1), getInt(keyFee0)
+	restore()
+
+	// the arbitrage contract holds the output during its callback and repays
+	// `repay` HBD, or tries something else
+	var repay int64
+	var got []string
+	var during int64
+	reenter := ""
+	sdk.ShimRegisterContract(arb, map[string]func(*string) *string{
+		"flash_callback": func(p *string) *string {
+			got = strings.Split(*p, ",")
+			during = sdk.GetBalance(arb, sdk.AssetHive)
+			if sdk.GetEnv().Caller.Address != pool {
+				sdk.Abort("arb: untrusted caller")
+			}
+			if reenter != "" {
+				sdk.ContractCall(pool, reenter, "1,1", nil)
+			}
+			sdk.HiveTransfer(pool, repay, sdk.AssetHbd)
+			return nil
+		},
+	})
+	sdk.ShimSetBalance(arb, sdk.AssetHbd, 50_000)
+
+	repay = 10_000
+	Swap(sptr("0to1," + strconv.FormatUint(out, 10) + ",flash,contract:arb,route=7,x"))
+	if strings.Join(got, ",") != "hive:alice,hive,"+strconv.FormatUint(out, 10)+",hbd,route=7,x" || during != int64(out) {
+		t.Fatalf("callback payload %v, balance during callback %d", got, during)
+	}
+	if getInt(keyReserve0) != wantR0 || getInt(keyReserve1) != wantR1 || getInt(keyFee0) != wantFee {
+		t.Fatal("flash swap must book like the regular swap")
+	}
+	if sdk.ShimGetBalance(arb, sdk.AssetHive) != int64(out) || sdk.ShimGetBalance(arb, sdk.AssetHbd) != 40_000 {
+		t.Fatal("arb balances")
+	}
+
+	// underpaying, re-entering, a user target or draining the reserve all abort
+	for _, c := range []struct {
+		payload, reenter, want string
+		repay                  int64
+	}{
+		{"0to1,1000,flash,contract:arb", "", "assertion failed", 400},
+		{"1to0,1000,flash,contract:arb", "", "assertion failed", 0},
+		{"0to1,1000,flash,contract:arb", "swap", "reentrancy: pool is locked", 10_000},
+		{"0to1,1000,flash,contract:arb", "donate", "reentrancy: pool is locked", 10_000},
+		{"0to1,1000,flash,hive:bob", "", "assertion failed", 0},
+		{"0to1,300000,flash,contract:arb", "", "assertion failed", 0},
+	} {
+		repay, reenter = c.repay, c.reenter
+		restore := sdk.ShimSnapshot()
+		expectPanicMsg(t, c.want, func() { Swap(sptr(c.payload)) })
+		restore()
+	}
+}
+
+func TestV2_SwapExactOut(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice, bob, ref := sdk.Address("hive:alice"), sdk.Address("hive:bob"), sdk.Address("hive:frontend")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 1_000_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 2_000_000)
+	Init(sptr("hbd,hive,30"))
+	AddLiquidity(sptr("100000,200000"))
+	sdk.ShimSetEnv("msg.required_auths", `["system:consensus"]`)
+	SetSlipParams(sptr("10,5000"))
+	sdk.ShimSetEnv("msg.required_auths", `[]`)
+
+	sdk.ShimSetSender(bob)
+	sdk.ShimSetBalance(bob, sdk.AssetHbd, 100_000)
+	sdk.ShimSetBalance(bob, sdk.AssetHive, 100_000)
+
+	// the spent input is the smallest whose regular swap pays the output
+	paidBy := func(payload string) int64 {
+		restore := sdk.ShimSnapshot()
+		defer restore()
+		before := sdk.ShimGetBalance(bob, sdk.AssetHive)
+		Swap(sptr(payload))
+		return sdk.ShimGetBalance(bob, sdk.AssetHive) - before
+	}
+	restore := sdk.ShimSnapshot()
+	in, _ := strconv.ParseUint(*SwapExactOut(sptr("0to1,15000,100000")), 10, 64)
+	restore()
+	if paidBy("0to1,"+strconv.FormatUint(in, 10)) < 15000 || paidBy("0to1,"+strconv.FormatUint(in-1, 10)) >= 15000 {
+		t.Fatalf("input %d is not the minimal input for 15000", in)
+	}
+	SwapExactOut(sptr("0to1,15000,100000"))
+	if sdk.ShimGetBalance(bob, sdk.AssetHbd) != 100_000-int64(in) || sdk.ShimGetBalance(bob, sdk.AssetHive) != 115_000 {
+		t.Fatal("exact out must draw only the input and pay exactly the output")
+	}
+	if fee := in - in*9970/10_000; getInt(keyFee0) != int64(fee) {
+		t.Fatalf("fee0 = %d, want %d", getInt(keyFee0), fee)
+	}
+	if sdk.ShimGetBalance("contract:v2", sdk.AssetHive) != getInt(keyReserve1) {
+		t.Fatal("hive reserve out of sync with the pool balance")
+	}
+
+	// 1to0 with a referral: the caller still gets exactly the output
+	out, _ := strconv.ParseUint(*SwapExactOut(sptr("1to0,3000,20000,hive:frontend,100")), 10, 64)
+	if sdk.ShimGetBalance(bob, sdk.AssetHbd) != 103_000-int64(in) || sdk.ShimGetBalance(ref, sdk.AssetHbd) == 0 ||
+		sdk.ShimGetBalance(bob, sdk.AssetHive) != 115_000-int64(out) {
+		t.Fatalf("referral exact out: bob hbd %d, frontend %d", sdk.ShimGetBalance(bob, sdk.AssetHbd), sdk.ShimGetBalance(ref, sdk.AssetHbd))
+	}
+	if sdk.ShimGetBalance("contract:v2", sdk.AssetHbd)-getInt(keyFee0) != getInt(keyReserve0) {
+		t.Fatal("hbd reserve out of sync with the pool balance")
+	}
+
+	for _, p := range []string{
+		"0to1,15000,1000",             // maxIn too low
+		"0to1,200000,1000000",         // more than the reserve
+		"0to1,0,1000",                 // zero output
+		"1to0,10,100,hive:frontend,0", // refBps bounds
+		"2to0,10,100",
+	} {
+		restore := sdk.ShimSnapshot()
+		expectPanic(t, func() { SwapExactOut(sptr(p)) })
+		restore()
+	}
+}
+
+func TestV2_LargeReserves(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	// reserves whose product does not fit 64 bits
+	const r = 10_000_000_000
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 2*r)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 2*r)
+	Init(sptr("hbd,hive,30"))
+	AddLiquidity(sptr("10000000000,10000000000"))
+	sdk.ShimSetEnv("msg.required_auths", `["system:consensus"]`)
+	SetSlipParams(sptr("10,5000"))
+	sdk.ShimSetEnv("msg.required_auths", `[]`)
+
+	var v swapView
+	json.Unmarshal([]byte(*GetAmountIn(sptr("1to0,1000000"))), &v)
+	if v.AmountOut < 1_000_000 || v.AmountIn < 1_000_000 || v.AmountIn > 1_010_000 {
+		t.Fatalf("get_amount_in = %+v", v)
+	}
+	json.Unmarshal([]byte(*GetAmountOut(sptr("0to1,1000000"))), &v)
+	// 0.3% base fee, about 0.01% price impact and its slip fee
+	if v.AmountOut < 996_000 || v.AmountOut > 997_000 {
+		t.Fatalf("get_amount_out = %+v", v)
+	}
+	in, _ := strconv.ParseUint(*SwapExactOut(sptr("0to1,1000000,1010000")), 10, 64)
+	if sdk.ShimGetBalance(alice, sdk.AssetHive) != r+1_000_000 || sdk.ShimGetBalance(alice, sdk.AssetHbd) != r-int64(in) {
+		t.Fatalf("exact out spent %d", in)
+	}
+}
+
+func TestV2_AddRemoveLiquidity_Slippage(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice, bob := sdk.Address("hive:alice"), sdk.Address("hive:bob")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 1_000_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 2_000_000)
+	Init(sptr("hbd,hive,30"))
+	AddLiquidity(sptr("100000,200000"))
+	totalLP := getUint(keyTotalLP)
+
+	// bob offers too much hive: only the 1:2 share is drawn
+	sdk.ShimSetSender(bob)
+	sdk.ShimSetBalance(bob, sdk.AssetHbd, 10_000)
+	sdk.ShimSetBalance(bob, sdk.AssetHive, 50_000)
+	var q liquidityQuote
+	if err := json.Unmarshal([]byte(*QuoteAddLiquidity(sptr("10000,50000"))), &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Amount0 != 10_000 || q.Amount1 != 20_000 || q.LP != totalLP/10 {
+		t.Fatalf("quote add = %+v", q)
+	}
+	restore := sdk.ShimSnapshot()
+	expectPanic(t, func() { AddLiquidity(sptr("10000,50000," + strconv.FormatUint(q.LP+1, 10))) })
+	restore()
+	AddLiquidity(sptr("10000,50000," + strconv.FormatUint(q.LP, 10)))
+	if sdk.ShimGetBalance(bob, sdk.AssetHbd) != 0 || sdk.ShimGetBalance(bob, sdk.AssetHive) != 30_000 || getLP(bob) != q.LP {
+		t.Fatal("add must draw only the ratio amounts")
+	}
+	if getInt(keyReserve0) != 110_000 || getInt(keyReserve1) != 220_000 {
+		t.Fatal("excess must not reach the reserves")
+	}
+
+	// too little hive: hbd is scaled down instead
+	sdk.ShimSetBalance(bob, sdk.AssetHbd, 10_000)
+	AddLiquidity(sptr("10000,2200"))
+	if sdk.ShimGetBalance(bob, sdk.AssetHbd) != 8_900 || sdk.ShimGetBalance(bob, sdk.AssetHive) != 27_800 {
+		t.Fatal("add must scale the larger side down")
+	}
+
+	if err := json.Unmarshal([]byte(*QuoteRemoveLiquidity(sptr("1000"))), &q); err != nil {
+		t.Fatal(err)
+	}
+	r0, r1, lp := uint64(getInt(keyReserve0)), uint64(getInt(keyReserve1)), getUint(keyTotalLP)
+	if q.Amount0 != r0*1000/lp || q.Amount1 != r1*1000/lp || q.LP != 1000 {
+		t.Fatalf("quote remove = %+v", q)
+	}
+	sdk.ShimSetEnv("anchor.height", "50")
+	for _, p := range []string{
+		"1000," + strconv.FormatUint(q.Amount0+1, 10) + ",0",
+		"1000,0," + strconv.FormatUint(q.Amount1+1, 10),
+		"1000,0,0,49",
+	} {
+		restore := sdk.ShimSnapshot()
+		expectPanic(t, func() { RemoveLiquidity(sptr(p)) })
+		restore()
+	}
+	hbd := sdk.ShimGetBalance(bob, sdk.AssetHbd)
+	RemoveLiquidity(sptr("1000," + strconv.FormatUint(q.Amount0, 10) + "," + strconv.FormatUint(q.Amount1, 10) + ",50"))
+	if sdk.ShimGetBalance(bob, sdk.AssetHbd) != hbd+int64(q.Amount0) {
+		t.Fatal("remove at the deadline with exact minimums must pay out")
+	}
+	expectPanic(t, func() { QuoteRemoveLiquidity(sptr(strconv.FormatUint(getUint(keyTotalLP)+1, 10))) })
+}
+
+func TestV2_Deadlines(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 1_000_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 2_000_000)
+	Init(sptr("hbd,hive,30"))
+	sdk.ShimSetEnv("anchor.height", "100")
+	sdk.ShimSetTimestamp("2025-06-01T12:00:00")
+
+	// a deadline at the current height or time still passes
+	AddLiquidity(sptr("100000,200000,0,100"))
+	AddLiquidity(sptr("1000,2000,,2025-06-01T12:00:00Z"))
+	Swap(sptr("0to1,1000,,,,100"))
+	Swap(sptr("0to1,1000,1,hive:frontend,25,2025-06-01T12:00:00"))
+	SwapExactOut(sptr("0to1,100,10000,,,101"))
+	RemoveLiquidity(sptr("1000,0,0,2025-06-01T12:00:00"))
+
+	for _, f := range []func(){
+		func() { AddLiquidity(sptr("1000,2000,0,99")) },
+		func() { AddLiquidity(sptr("1000,2000,,2025-06-01T11:59:59Z")) },
+		func() { AddLiquidity(sptr("1000,2000,0,soon")) },
+		func() { Swap(sptr("0to1,1000,,,,99")) },
+		func() { Swap(sptr("0to1,1000,1,,,2025-06-01T11:00:00")) },
+		func() { Swap(sptr("0to1,1000,1,hive:frontend,,101")) },
+		func() { SwapExactOut(sptr("0to1,100,10000,,,2025-06-01T11:00:00")) },
+		func() { RemoveLiquidity(sptr("1000,0,0,2025-06-01T11:59:59")) },
+	} {
+		restore := sdk.ShimSnapshot()
+		expectPanic(t, f)
+		restore()
+	}
+}
+
+func TestV2_MinimumLiquidity_InflationAttack(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	mallory, bob := sdk.Address("hive:mallory"), sdk.Address("hive:bob")
+	sdk.ShimSetSender(mallory)
+	sdk.ShimSetBalance(mallory, sdk.AssetHbd, 200_000)
+	sdk.ShimSetBalance(mallory, sdk.AssetHive, 200_000)
+	Init(sptr("hbd,hive,30"))
+
+	// the first deposit must mint more than the locked minimum
+	restore := sdk.ShimSnapshot()
+	expectPanic(t, func() { AddLiquidity(sptr("1000,1000")) })
+	restore()
+
+	// mallory mints a single LP unit and donates to inflate its price
+	AddLiquidity(sptr("1001,1001"))
+	if getLP(mallory) != 1 || getLP(lockedLP) != minimumLiquidity || getUint(keyTotalLP) != 1001 {
+		t.Fatalf("first mint: mallory %d, locked %d", getLP(mallory), getLP(lockedLP))
+	}
+	Donate(sptr("100000,100000"))
+
+	// bob's deposit still mints a fair share: the donation mostly accrues
+	// to the locked LP, not to mallory
+	sdk.ShimSetSender(bob)
+	sdk.ShimSetBalance(bob, sdk.AssetHbd, 50_000)
+	sdk.ShimSetBalance(bob, sdk.AssetHive, 50_000)
+	AddLiquidity(sptr("50000,50000"))
+	RemoveLiquidity(sptr(strconv.FormatUint(getLP(bob), 10)))
+	if got := sdk.ShimGetBalance(bob, sdk.AssetHbd); got < 49_950 {
+		t.Fatalf("bob lost %d of 50000 to rounding", 50_000-got)
+	}
+
+	sdk.ShimSetSender(mallory)
+	RemoveLiquidity(sptr("1"))
+	if got := sdk.ShimGetBalance(mallory, sdk.AssetHbd); got > 100_000 {
+		t.Fatalf("mallory kept %d hbd, the attack must cost the donation", got)
+	}
+
+	// the locked LP can be neither withdrawn nor burned by anyone
+	sdk.ShimSetEnv("msg.required_auths", `["system:consensus"]`)
+	expectPanic(t, func() { SIWithdraw(sptr("system:locked_lp,1000")) })
+	if getUint(keyTotalLP) != minimumLiquidity {
+		t.Fatal("total LP must stay at the locked minimum")
+	}
+}
+
+func TestV2_MinimumLiquidity_EmptiedPool(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 100_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 100_000)
+	Init(sptr("hbd,hive,30"))
+
+	// a pool whose LP was all burned before the lock existed
+	setInt(keyReserve0, 7_000)
+	setInt(keyReserve1, 3_000)
+	sdk.ShimSetBalance("contract:v2", sdk.AssetHbd, 7_000)
+	sdk.ShimSetBalance("contract:v2", sdk.AssetHive, 3_000)
+
+	AddLiquidity(sptr("10000,40000"))
+	if getInt(keyReserve0) != 10_000 || getInt(keyReserve1) != 40_000 || getInt(keyFee0) != 7_000 || getInt(keyFee1) != 3_000 {
+		t.Fatal("orphaned reserves must move to the fee buckets, not to the new depositor")
+	}
+	if getLP(alice) != 20_000-minimumLiquidity || getUint(keyTotalLP) != 20_000 {
+		t.Fatalf("re-initialising mint: alice %d, total %d", getLP(alice), getUint(keyTotalLP))
+	}
+	Burn(sptr(strconv.FormatUint(getLP(alice), 10)))
+	if getUint(keyTotalLP) != minimumLiquidity {
+		t.Fatal("burning every holder's LP leaves the locked minimum")
+	}
+}
+
+func TestV2_Views(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice, bob, ref := sdk.Address("hive:alice"), sdk.Address("hive:bob"), sdk.Address("hive:frontend")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 1_000_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 2_000_000)
+	Init(sptr("hbd,hive,30"))
+	AddLiquidity(sptr("100000,200000"))
+	sdk.ShimSetEnv("msg.required_auths", `["system:consensus"]`)
+	SetSlipParams(sptr("10,5000"))
+	sdk.ShimSetEnv("msg.required_auths", `[]`)
+	sdk.ShimSetSender(bob)
+	sdk.ShimSetBalance(bob, sdk.AssetHbd, 100_000)
+	sdk.ShimSetBalance(bob, sdk.AssetHive, 100_000)
+
+	view := func(f func(*string) *string, payload string, v interface{}) {
+		t.Helper()
+		before := string(sdk.ShimDumpState())
+		if err := json.Unmarshal([]byte(*f(sptr(payload))), v); err != nil {
+			t.Fatal(err)
+		}
+		if string(sdk.ShimDumpState()) != before {
+			t.Fatal("view wrote state")
+		}
+	}
+
+	// quotes match what the swap pays, referral included
+	for _, c := range []struct{ quote, swap, in, out string }{
+		{"0to1,20000,1000", "0to1,20000,hive:frontend,1000", sdk.AssetHbd.String(), sdk.AssetHive.String()},
+		{"1to0,30000,100", "1to0,30000,hive:frontend,100", sdk.AssetHive.String(), sdk.AssetHbd.String()},
+	} {
+		var q swapView
+		view(GetAmountOut, c.quote, &q)
+		if q.AmountOut == 0 || q.SlipFee == 0 || q.Referral == 0 || (c.in == "hbd") != (q.BaseFee > 0) {
+			t.Fatalf("%s: quote %+v", c.quote, q)
+		}
+		restore := sdk.ShimSnapshot()
+		out0 := sdk.ShimGetBalance(bob, sdk.Asset(c.out))
+		Swap(sptr(c.swap))
+		if got := sdk.ShimGetBalance(bob, sdk.Asset(c.out)) - out0; got != int64(q.AmountOut) || sdk.ShimGetBalance(ref, sdk.AssetHbd) != int64(q.Referral) {
+			t.Fatalf("%s: swap paid %d, quoted %+v", c.swap, got, q)
+		}
+		restore()
+	}
+
+	var q swapView
+	view(GetAmountIn, "0to1,15000", &q)
+	restore := sdk.ShimSnapshot()
+	if got := *SwapExactOut(sptr("0to1,15000,1000000")); got != strconv.FormatUint(q.AmountIn, 10) || q.AmountOut != 15000 {
+		t.Fatalf("get_amount_in %+v, swap_exact_out spent %s", q, got)
+	}
+	restore()
+	view(GetAmountOut, "0to1,100000000000", &q)
+	if q.AmountOut != 0 {
+		t.Fatal("a swap that would abort quotes 0")
+	}
+	for _, p := range []string{"2to0,10", "0to1,10,1001", "0to1", "1to0,200000"} {
+		expectPanic(t, func() { GetAmountIn(sptr(p)) })
+	}
+
+	Swap(sptr("0to1,20000"))
+	var lp liquidityQuote
+	view(GetLPBalance, "hive:alice", &lp)
+	if lp.LP != getLP(alice) || lp.Amount0 == 0 || lp.Amount1 == 0 {
+		t.Fatalf("lp balance %+v", lp)
+	}
+	var reserves struct {
+		Reserve0, Reserve1 int64
+		TotalLP            uint64
+	}
+	view(GetReserves, "", &reserves)
+	if reserves.Reserve0 != getInt(keyReserve0) || reserves.Reserve1 != getInt(keyReserve1) || reserves.TotalLP != getUint(keyTotalLP) {
+		t.Fatalf("reserves %+v", reserves)
+	}
+	var cfg struct {
+		Asset0, Asset1                            string
+		BaseFeeBps, SlipBaselineBps, SlipShareBps uint64
+		FeeClaimIntervalS                         uint64
+	}
+	view(GetPoolConfig, "", &cfg)
+	if cfg.Asset0 != "hbd" || cfg.Asset1 != "hive" || cfg.BaseFeeBps != 30 || cfg.SlipBaselineBps != 10 || cfg.SlipShareBps != 5000 || cfg.FeeClaimIntervalS != defaultFeeClaimIntervalS {
+		t.Fatalf("config %+v", cfg)
+	}
+	var fees struct{ Fee0, Fee1 int64 }
+	view(GetClaimableFees, "", &fees)
+	if fees.Fee0 != getInt(keyFee0) || fees.Fee0 == 0 || fees.Fee1 != 0 {
+		t.Fatalf("fees %+v", fees)
+	}
+}
+
+func TestV2_ClaimFees_IntervalAndConversion(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	pool, dao := sdk.Address("contract:v2"), sdk.Address("system:fr_balance")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetTimestamp("2025-06-01T00:00:00")
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 1_000_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 2_000_000)
+	Init(sptr("hbd,hive,30"))
+	// pools created before block time was parsed store the raw timestamp
+	setStr(keyFeeLastClaimUnix, "2025-06-01T00:00:00")
+	AddLiquidity(sptr("100000,200000"))
+	Swap(sptr("0to1,10000"))
+	// a non-HBD fee bucket, as left by reserves swept from an emptied pool
+	setInt(keyFee1, 5_000)
+	sdk.ShimSetBalance(pool, sdk.AssetHive, sdk.ShimGetBalance(pool, sdk.AssetHive)+5_000)
+
+	expectPanic(t, func() { SetFeeClaimInterval(sptr("3600")) })
+	sdk.ShimSetSender("system:consensus")
+	SetFeeClaimInterval(sptr("3600"))
+	sdk.ShimSetTimestamp("2025-06-01T00:59:59")
+	restore := sdk.ShimSnapshot()
+	expectPanic(t, func() { ClaimFees(nil) })
+	restore()
+
+	sdk.ShimSetTimestamp("2025-06-01T01:00:00")
+	// spot dumped within the claim's block is away from the hour's TWAP, so
+	// the hive fees are not sold into it
+	restore = sdk.ShimSnapshot()
+	sdk.ShimSetSender(alice)
+	Swap(sptr("1to0,20000"))
+	sdk.ShimSetSender("system:consensus")
+	ClaimFees(nil)
+	if getInt(keyFee1) != 5_000 || getInt(keyFee0) != 0 {
+		t.Fatalf("fees after a skewed claim: %d hbd, %d hive", getInt(keyFee0), getInt(keyFee1))
+	}
+	restore()
+
+	fee0, r0, r1 := getInt(keyFee0), getInt(keyReserve0), getInt(keyReserve1)
+	ClaimFees(nil)
+	// at most the amount moving the price 1% is sold, near the spot price
+	sold := 5_000 - getInt(keyFee1)
+	bought := sdk.ShimGetBalance(dao, sdk.AssetHbd) - fee0
+	if sold != r1*100/9_900 || getInt(keyReserve1) != r1+sold || getInt(keyReserve0) != r0-bought {
+		t.Fatalf("sold %d hive for %d hbd", sold, bought)
+	}
+	if bought*r1*10_000 < sold*r0*9_900 {
+		t.Fatalf("conversion slipped more than 1%%: %d hive for %d hbd", sold, bought)
+	}
+	if getInt(keyFee0) != 0 || sdk.ShimGetBalance(pool, sdk.AssetHbd) != getInt(keyReserve0) ||
+		sdk.ShimGetBalance(pool, sdk.AssetHive) != getInt(keyReserve1)+getInt(keyFee1) {
+		t.Fatal("pool balances out of sync with reserves and fees")
+	}
+	if getUint(keyFeeLastClaimUnix) != 1748739600 {
+		t.Fatalf("last claim = %s", getStr(keyFeeLastClaimUnix))
+	}
+
+	// the rest is converted by later claims
+	restore = sdk.ShimSnapshot()
+	expectPanic(t, func() { ClaimFees(nil) })
+	restore()
+	sdk.ShimSetTimestamp("2025-06-01T02:00:00")
+	ClaimFees(nil)
+	sdk.ShimSetTimestamp("2025-06-01T03:00:00")
+	ClaimFees(nil)
+	if getInt(keyFee1) != 0 || sdk.ShimGetBalance(pool, sdk.AssetHive) != getInt(keyReserve1) {
+		t.Fatalf("fee1 = %d after three claims", getInt(keyFee1))
+	}
+
+	// reserves near the int64 limit do not wrap the amount sold
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetTimestamp("2025-06-01T00:00:00")
+	Init(sptr("hbd,hive,30"))
+	r0, r1 = math.MaxInt64/4, math.MaxInt64/2
+	setInt(keyReserve0, r0)
+	setInt(keyReserve1, r1)
+	setInt(keyFee1, r1/50)
+	sdk.ShimSetBalance(pool, sdk.AssetHbd, r0)
+	sdk.ShimSetBalance(pool, sdk.AssetHive, r1+r1/50)
+	sdk.ShimSetSender("system:consensus")
+	SetFeeClaimInterval(sptr("3600"))
+	sdk.ShimSetTimestamp("2025-06-01T01:00:00")
+	ClaimFees(nil)
+	sold = r1/50 - getInt(keyFee1)
+	bought = sdk.ShimGetBalance(dao, sdk.AssetHbd)
+	if uint64(sold) != mulDiv(uint64(r1), 100, 9_900) || getInt(keyReserve1) != r1+sold {
+		t.Fatalf("sold %d of %d hive", sold, r1/50)
+	}
+	if uint64(bought) < mulDiv(mulDiv(uint64(sold), uint64(r0), uint64(r1)), 9_900, 10_000) {
+		t.Fatalf("conversion slipped more than 1%%: %d hive for %d hbd", sold, bought)
+	}
+}
+
+func TestV2_SIUnwind(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	pool := sdk.Address("contract:v2")
+	alice, bob, carol, dave := sdk.Address("hive:alice"), sdk.Address("hive:bob"), sdk.Address("hive:carol"), sdk.Address("hive:dave")
+	sdk.ShimSetSender(alice)
+	Init(sptr("hbd,hive,30"))
+	for _, a := range []sdk.Address{alice, bob, carol} {
+		sdk.ShimSetSender(a)
+		sdk.ShimSetBalance(a, sdk.AssetHbd, 100_000)
+		sdk.ShimSetBalance(a, sdk.AssetHive, 200_000)
+		AddLiquidity(sptr("50000,100000"))
+	}
+	sdk.ShimSetSender(bob)
+	Transfer(sptr("hive:dave," + strconv.FormatUint(getLP(bob)/2, 10)))
+	sdk.ShimSetSender(carol)
+	Swap(sptr("0to1,10000"))
+	RemoveLiquidity(sptr(strconv.FormatUint(getLP(carol), 10)))
+
+	// the index holds every address with LP, densely
+	holders := map[sdk.Address]bool{}
+	for i := uint64(0); i < getUint(keyHolderCount); i++ {
+		holders[holderAt(i)] = true
+	}
+	if len(holders) != 4 || !holders[lockedLP] || !holders[alice] || !holders[bob] || !holders[dave] {
+		t.Fatalf("holders = %v", holders)
+	}
+
+	expectPanic(t, func() { SIUnwind(sptr("2")) })
+	// everyone gets the share remove_liquidity would pay
+	want := map[sdk.Address]int64{}
+	for _, a := range []sdk.Address{alice, bob, dave} {
+		var q liquidityQuote
+		json.Unmarshal([]byte(*GetLPBalance(sptr(a.String()))), &q)
+		want[a] = sdk.ShimGetBalance(a, sdk.AssetHbd) + int64(q.Amount0)
+	}
+
+	sdk.ShimSetSender("system:consensus")
+	if left := *SIUnwind(sptr("2")); left != "2" || getUint(keyUnwindCursor) != 1 {
+		t.Fatalf("first page left %s holders, cursor %d", left, getUint(keyUnwindCursor))
+	}
+	// withdraw-only: no deposits or trades, but holders can still leave
+	sdk.ShimSetSender(bob)
+	for _, f := range []func(){
+		func() { Swap(sptr("0to1,1000")) },
+		func() { SwapExactOut(sptr("0to1,1000,5000")) },
+		func() { AddLiquidity(sptr("1000,2000")) },
+		func() { Donate(sptr("1000,0")) },
+	} {
+		restore := sdk.ShimSnapshot()
+		expectPanic(t, f)
+		restore()
+	}
+	RemoveLiquidity(sptr(strconv.FormatUint(getLP(bob), 10)))
+
+	sdk.ShimSetSender("system:consensus")
+	if left := *SIUnwind(sptr("10")); left != "0" {
+		t.Fatalf("second page left %s holders", left)
+	}
+	for a, w := range want {
+		if getLP(a) != 0 || sdk.ShimGetBalance(a, sdk.AssetHbd) < w-1 {
+			t.Fatalf("%s: lp %d, hbd %d want %d", a, getLP(a), sdk.ShimGetBalance(a, sdk.AssetHbd), w)
+		}
+	}
+	if getUint(keyTotalLP) != minimumLiquidity || getUint(keyHolderCount) != 1 || holderAt(0) != lockedLP {
+		t.Fatal("only the locked minimum liquidity may remain")
+	}
+	if sdk.ShimGetBalance(pool, sdk.AssetHbd) != getInt(keyReserve0)+getInt(keyFee0) ||
+		sdk.ShimGetBalance(pool, sdk.AssetHive) != getInt(keyReserve1)+getInt(keyFee1) {
+		t.Fatal("pool balances out of sync with reserves and fees")
+	}
+	if left := *SIUnwind(sptr("10")); left != "0" {
+		t.Fatal("unwinding an unwound pool is a no-op")
+	}
+}
+
+func TestV2_SIUnwind_LegacyHolders(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice, old := sdk.Address("hive:alice"), sdk.Address("hive:oldlp")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 100_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 200_000)
+	Init(sptr("hbd,hive,30"))
+	AddLiquidity(sptr("50000,100000"))
+	// an LP position written before the holder index existed
+	setUint(lpKey(old), 20_000)
+	setUint(keyTotalLP, getUint(keyTotalLP)+20_000)
+	setInt(keyReserve0, getInt(keyReserve0)+14_000)
+	setInt(keyReserve1, getInt(keyReserve1)+28_000)
+	sdk.ShimSetBalance("contract:v2", sdk.AssetHbd, getInt(keyReserve0))
+	sdk.ShimSetBalance("contract:v2", sdk.AssetHive, getInt(keyReserve1))
+
+	sdk.ShimSetSender("system:consensus")
+	if left := *SIUnwind(sptr("10")); left != "1" || getLP(alice) != 0 {
+		t.Fatalf("unindexed LP must keep the unwind open, left %s", left)
+	}
+	sdk.ShimSetSender(alice)
+	expectPanic(t, func() { SIIndexHolders(sptr(old.String())) })
+	sdk.ShimSetSender("system:consensus")
+	SIIndexHolders(sptr(old.String() + ",hive:nobody," + old.String()))
+	if getUint(keyHolderCount) != 2 {
+		t.Fatalf("holder count = %d", getUint(keyHolderCount))
+	}
+	if left := *SIUnwind(sptr("10")); left != "0" || getLP(old) != 0 || sdk.ShimGetBalance(old, sdk.AssetHbd) == 0 {
+		t.Fatalf("legacy holder not unwound, left %s", left)
+	}
+	if getUint(keyTotalLP) != minimumLiquidity {
+		t.Fatal("only the locked minimum liquidity may remain")
+	}
+}
@@ -132,8 +132,13 @@ func TestAMM_Wasm_Init_Add_Swap_Claim(t *testing.T) {
 		}
 	}
 
-	// claim fees by reducing contract HBD via withdraw
+	// claim fees by reducing contract HBD via withdraw; the claim interval
+	// runs from init, so drop it first
 	{
+		setTx := makeTx([]string{"system:consensus"}, "set_fee_claim_interval", "0", nil)
+		if res, _, _ := ct.Call(setTx); !res.Success {
+			t.Fatalf("set_fee_claim_interval failed: %s", res.Ret)
+		}
 		pre := ct.GetBalance("contract:v2amm", ledgerDb.AssetHbd)
 		tx := makeTx([]string{"system:consensus"}, "claim_fees", "", nil)
 		res, _, _ := ct.Call(tx)
@@ -0,0 +1,171 @@
+// Package router swaps along a path of v2-amm pools in one transaction, so
+// e.g. HIVE -> HBD -> TOKEN settles atomically instead of as two user
+// transactions with slippage exposure in between.
+//
+// Each hop is a contracts.call to the pool's swap entrypoint. The router
+// draws the input from its caller, lets every pool draw the running amount
+// from the router (a transfer.allow intent for native assets, an approval
+// for token contracts), measures what the pool paid back and hands the final
+// output to the caller. Only the end-to-end minimum output is enforced;
+// intermediate hops run without their own minOut.
+package router
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/reentrancy"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxHops bounds the number of pools in a path.
+const MaxHops = 4
+
+// v2-amm state keys read from the pools.
+const (
+	keyPoolAsset0 = "pool/asset0"
+	keyPoolAsset1 = "pool/asset1"
+)
+
+const lockName = "router"
+
+// Route is the payload of a swap:
+//
+//	{"path":["contract:hive_hbd","contract:hbd_tok"],"assetIn":"hive",
+//	 "amountIn":1000,"minOut":950,"deadline":1200,
+//	 "beneficiary":"hive:frontend","refBps":25}
+//
+// Deadline is the last block at which the swap may execute: a block height
+// number or a timestamp string, see sdk.ParseDeadline; absent or 0 for none.
+// Beneficiary and refBps are optional and forwarded to every pool.
+type Route struct {
+	Path        []sdk.Address `json:"path"`
+	AssetIn     sdk.Asset     `json:"assetIn"`
+	AmountIn    uint64        `json:"amountIn"`
+	MinOut      uint64        `json:"minOut"`
+	Deadline    sdk.Deadline  `json:"deadline"`
+	Beneficiary sdk.Address   `json:"beneficiary,omitempty"`
+	RefBps      uint64        `json:"refBps,omitempty"`
+}
+
+// Hop is one pool of a route with the direction it is traded in.
+type Hop struct {
+	Pool     sdk.Address
+	Dir      string // "0to1" or "1to0"
+	AssetIn  sdk.Asset
+	AssetOut sdk.Asset
+}
+
+// ParseRoute decodes and validates a swap payload.
+func ParseRoute(payload string) Route {
+	var r Route
+	if json.Unmarshal([]byte(payload), &r) != nil {
+		sdk.Abort("router: invalid payload")
+	}
+	r.Validate()
+	return r
+}
+
+// Validate aborts on a malformed route. It does not read the pools.
+func (r Route) Validate() {
+	if len(r.Path) == 0 || len(r.Path) > MaxHops {
+		sdk.Abort("router: path must have 1-" + strconv.Itoa(MaxHops) + " pools")
+	}
+	for _, p := range r.Path {
+		if addr, err := sdk.ParseAddress(p.String()); err != nil || addr.Type() != sdk.AddressTypeContract {
+			sdk.Abort("router: " + p.String() + " is not a contract")
+		}
+	}
+	if _, err := sdk.ParseToken(r.AssetIn.String()); err != nil {
+		sdk.Abort("router: unknown asset " + r.AssetIn.String())
+	}
+	if r.AmountIn == 0 || r.AmountIn > math.MaxInt64 {
+		sdk.Abort("router: bad amountIn")
+	}
+	if r.RefBps != 0 || r.Beneficiary != "" {
+		if _, err := sdk.ParseAddress(r.Beneficiary.String()); err != nil || r.RefBps < 1 || r.RefBps > 1000 {
+			sdk.Abort("router: referral needs a beneficiary and refBps 1-1000")
+		}
+	}
+}
+
+func readKey(contract sdk.Address, key string) string {
+	v := sdk.ContractRead(contract, key)
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// Hops resolves the direction of every pool from the assets it holds. Each
+// pool must trade the asset the previous hop produced.
+func (r Route) Hops() []Hop {
+	hops := make([]Hop, len(r.Path))
+	asset := r.AssetIn
+	for i, pool := range r.Path {
+		a0 := sdk.Asset(readKey(pool, keyPoolAsset0))
+		a1 := sdk.Asset(readKey(pool, keyPoolAsset1))
+		switch asset {
+		case a0:
+			hops[i] = Hop{Pool: pool, Dir: "0to1", AssetIn: a0, AssetOut: a1}
+		case a1:
+			hops[i] = Hop{Pool: pool, Dir: "1to0", AssetIn: a1, AssetOut: a0}
+		default:
+			sdk.Abort("router: pool " + pool.String() + " does not trade " + asset.String())
+		}
+		asset = hops[i].AssetOut
+	}
+	return hops
+}
+
+func (r Route) swapPayload(h Hop, amount uint64) string {
+	p := []string{h.Dir, strconv.FormatUint(amount, 10)}
+	if r.RefBps > 0 {
+		p = append(p, "", r.Beneficiary.String(), strconv.FormatUint(r.RefBps, 10))
+	}
+	return strings.Join(p, ",")
+}
+
+// SwapExactIn draws r.AmountIn of r.AssetIn from the caller, swaps it
+// through every pool of the path and sends the output to the caller. It
+// returns the output amount and aborts if it is below r.MinOut or the
+// deadline has passed.
+func SwapExactIn(r Route) uint64 {
+	env := sdk.GetEnv()
+	if r.Deadline.Expired(env) {
+		sdk.Abort("router: deadline passed")
+	}
+	hops := r.Hops()
+	reentrancy.Enter(lockName)
+
+	self := sdk.Address(env.ContractId)
+	sdk.TokenOf(r.AssetIn).Draw(int64(r.AmountIn))
+	amount := r.AmountIn
+	for _, h := range hops {
+		out := sdk.TokenOf(h.AssetOut)
+		before := out.BalanceOf(self)
+		opts := sdk.AllowDraw(sdk.TokenOf(h.AssetIn), h.Pool, int64(amount))
+		sdk.ContractCall(h.Pool, "swap", r.swapPayload(h, amount), opts)
+		after := out.BalanceOf(self)
+		if after <= before {
+			sdk.Abort("router: no output from " + h.Pool.String())
+		}
+		amount = uint64(after - before)
+	}
+	if amount < r.MinOut {
+		sdk.Abort("router: output " + strconv.FormatUint(amount, 10) + " below minOut " + strconv.FormatUint(r.MinOut, 10))
+	}
+	last := hops[len(hops)-1].AssetOut
+	sdk.TokenOf(last).Transfer(env.Caller.Address, int64(amount))
+
+	reentrancy.Exit(lockName)
+	path := make([]string, len(r.Path))
+	for i, p := range r.Path {
+		path[i] = p.String()
+	}
+	sdk.EmitEvent("swap", "account", env.Caller.Address.String(), "path", strings.Join(path, ","),
+		"assetIn", r.AssetIn.String(), "amountIn", strconv.FormatUint(r.AmountIn, 10),
+		"assetOut", last.String(), "amountOut", strconv.FormatUint(amount, 10))
+	return amount
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"contract-template/examples/v2-amm/router"
+	"contract-template/sdk"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	poolHiveHbd = sdk.Address("contract:hive_hbd") // hbd/hive
+	poolHbdTok  = sdk.Address("contract:hbd_tok")  // hbd/TOK
+	poolTokHive = sdk.Address("contract:tok_hive") // TOK/hive
+)
+
+// setupRouter runs three v2-amm pools and a router, with liquidity from
+// hive:lp, and leaves the router as the running contract.
+func setupRouter(t *testing.T) sdk.ContractToken {
+	t.Helper()
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:router")
+	tok := sdk.ShimRegisterToken("contract:tok", "TOK", 3)
+	tokAsset := sdk.Asset(tok.Id())
+	pool := map[string]func(*string) *string{"swap": Swap, "add_liquidity": AddLiquidity}
+	lp := sdk.Address("hive:lp")
+	sdk.ShimSetSender(lp)
+	sdk.ShimSetBalance(lp, sdk.AssetHbd, 10_000_000)
+	sdk.ShimSetBalance(lp, sdk.AssetHive, 10_000_000)
+	sdk.ShimSetBalance(lp, tokAsset, 10_000_000)
+	for _, p := range []struct {
+		id       sdk.Address
+		init     string
+		amt0     int64
+		amt1     int64
+		tokDrawn int64
+	}{
+		{poolHiveHbd, "hbd,hive", 1_000_000, 4_000_000, 0},
+		{poolHbdTok, "hbd,contract:tok/TOK", 500_000, 2_500_000, 2_500_000},
+		{poolTokHive, "contract:tok/TOK,hive", 1_000_000, 800_000, 1_000_000},
+	} {
+		sdk.ShimRegisterContract(p.id, pool)
+		if p.tokDrawn > 0 {
+			sdk.ShimApproveToken(tok, lp, p.id, p.tokDrawn)
+		}
+		sdk.ShimWithContract(p.id, func() {
+			Init(sptr(p.init))
+			AddLiquidity(sptr(strconv.FormatInt(p.amt0, 10) + "," + strconv.FormatInt(p.amt1, 10)))
+		})
+	}
+	return tok
+}
+
+func route(path []sdk.Address, assetIn string, amountIn, minOut uint64, extra string) string {
+	ids := make([]string, len(path))
+	for i, p := range path {
+		ids[i] = `"` + p.String() + `"`
+	}
+	s := `{"path":[` + strings.Join(ids, ",") + `],"assetIn":"` + assetIn + `","amountIn":` +
+		strconv.FormatUint(amountIn, 10) + `,"minOut":` + strconv.FormatUint(minOut, 10)
+	if extra != "" {
+		s += "," + extra
+	}
+	return s + "}"
+}
+
+// expectPanicMsg is expectPanic for aborts whose message starts with prefix.
+func expectPanicMsg(t *testing.T, prefix string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if msg, _ := r.(string); !strings.HasPrefix(msg, prefix) {
+			t.Fatalf("abort = %v, want %q", r, prefix)
+		}
+	}()
+	f()
+}
+
+func swapVia(payload string) uint64 {
+	return router.SwapExactIn(router.ParseRoute(payload))
+}
+
+func TestRouter_TwoHopsMatchSequentialSwaps(t *testing.T) {
+	tok := setupRouter(t)
+	tokAsset := sdk.Asset(tok.Id())
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 100_000)
+
+	// alice swapping HIVE -> HBD -> TOK herself, in two transactions
+	restore := sdk.ShimSnapshot()
+	sdk.ShimWithContract(poolHiveHbd, func() { Swap(sptr("1to0,100000")) })
+	hbd := sdk.ShimGetBalance(alice, sdk.AssetHbd)
+	sdk.ShimWithContract(poolHbdTok, func() { Swap(sptr("0to1," + strconv.FormatInt(hbd, 10))) })
+	want := sdk.ShimGetBalance(alice, tokAsset)
+	restore()
+	if hbd <= 0 || want <= 0 {
+		t.Fatal("direct swaps paid nothing")
+	}
+
+	out := swapVia(route([]sdk.Address{poolHiveHbd, poolHbdTok}, "hive", 100_000, uint64(want), ""))
+	if int64(out) != want || sdk.ShimGetBalance(alice, tokAsset) != want || sdk.ShimGetBalance(alice, sdk.AssetHive) != 0 {
+		t.Fatalf("routed %d TOK, sequential swaps give %d", out, want)
+	}
+	for _, a := range []sdk.Asset{sdk.AssetHive, sdk.AssetHbd, tokAsset} {
+		if sdk.ShimGetBalance("contract:router", a) != 0 {
+			t.Fatalf("router kept %s", a)
+		}
+	}
+	if e := sdk.ShimEvents(); len(e) != 1 || e[0].Type != "swap" || e[0].Attrs["amountOut"] != strconv.FormatUint(out, 10) ||
+		e[0].Attrs["path"] != "contract:hive_hbd,contract:hbd_tok" {
+		t.Fatalf("events = %+v", e)
+	}
+
+	// three hops back to the start asset
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 10_000)
+	out = swapVia(route([]sdk.Address{poolTokHive, poolHbdTok, poolHiveHbd}, "hive", 10_000, 1, ""))
+	if out == 0 || sdk.ShimGetBalance(alice, sdk.AssetHive) != int64(out) {
+		t.Fatalf("three hop output %d", out)
+	}
+}
+
+func TestRouter_MinOutDeadlineAndPath(t *testing.T) {
+	tok := setupRouter(t)
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 50_000)
+	path := []sdk.Address{poolHiveHbd, poolHbdTok}
+
+	// the host reverts every hop when the end-to-end minimum is missed
+	restore := sdk.ShimSnapshot()
+	expectPanicMsg(t, "router: output", func() { swapVia(route(path, "hive", 50_000, 1_000_000, "")) })
+	restore()
+	if sdk.ShimGetBalance(alice, sdk.AssetHive) != 50_000 {
+		t.Fatal("input not kept")
+	}
+
+	sdk.ShimSetEnv("anchor.height", "101")
+	expectPanicMsg(t, "router: deadline passed", func() { swapVia(route(path, "hive", 50_000, 1, `"deadline":100`)) })
+	sdk.ShimSetTimestamp("2025-06-01T00:00:01")
+	expectPanicMsg(t, "router: deadline passed", func() {
+		swapVia(route(path, "hive", 50_000, 1, `"deadline":"2025-06-01T00:00:00Z"`))
+	})
+	swapVia(route(path, "hive", 50_000, 1, `"deadline":101`))
+	if sdk.ShimGetBalance(alice, sdk.Asset(tok.Id())) == 0 {
+		t.Fatal("swap at the deadline must succeed")
+	}
+
+	expectPanicMsg(t, "router: pool contract:hive_hbd does not trade contract:tok/TOK", func() {
+		swapVia(route([]sdk.Address{poolHbdTok, poolHiveHbd, poolHiveHbd}, "hbd", 1000, 1, ""))
+	})
+	expectPanicMsg(t, "router: pool contract:nowhere does not trade hive", func() {
+		swapVia(route([]sdk.Address{"contract:nowhere"}, "hive", 1000, 1, ""))
+	})
+}
+
+func TestRouter_ForwardsReferral(t *testing.T) {
+	setupRouter(t)
+	alice, ui := sdk.Address("hive:alice"), sdk.Address("hive:frontend")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 200_000)
+
+	restore := sdk.ShimSnapshot()
+	plain := swapVia(route([]sdk.Address{poolHiveHbd}, "hive", 100_000, 1, ""))
+	restore()
+	// HIVE -> HBD: the referral comes out of the HBD output
+	ref := swapVia(route([]sdk.Address{poolHiveHbd}, "hive", 100_000, 1, `"beneficiary":"hive:frontend","refBps":100`))
+	paid := sdk.ShimGetBalance(ui, sdk.AssetHbd)
+	if paid <= 0 || ref+uint64(paid) != plain {
+		t.Fatalf("referral %d, output %d, without referral %d", paid, ref, plain)
+	}
+
+	// HBD -> TOK: the referral is a share of the pool's HBD base fee
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 100_000)
+	swapVia(route([]sdk.Address{poolHbdTok}, "hbd", 100_000, 1, `"beneficiary":"hive:frontend","refBps":1000`))
+	if sdk.ShimGetBalance(ui, sdk.AssetHbd) != paid+8 { // 10% of the 80 HBD unit base fee
+		t.Fatalf("beneficiary has %d", sdk.ShimGetBalance(ui, sdk.AssetHbd))
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"contract-template/sdk/scenario"
+	"testing"
+)
+
+func TestScenario(t *testing.T) {
+	scenario.Run(t, "testdata/scenario.json", scenario.Entrypoints{
+		"init":                   Init,
+		"add_liquidity":          AddLiquidity,
+		"remove_liquidity":       RemoveLiquidity,
+		"quote_add_liquidity":    QuoteAddLiquidity,
+		"quote_remove_liquidity": QuoteRemoveLiquidity,
+		"swap":                   Swap,
+		"swap_exact_out":         SwapExactOut,
+		"donate":                 Donate,
+		"claim_fees":             ClaimFees,
+		"burn":                   Burn,
+		"transfer":               Transfer,
+		"si_unwind":              SIUnwind,
+		"si_withdraw":            SIWithdraw,
+		"set_base_fee":           SetBaseFee,
+		"set_fee_claim_interval": SetFeeClaimInterval,
+		"set_slip_params":        SetSlipParams,
+		"set_paused":             SetPaused,
+		"pause":                  Pause,
+		"set_guardian":           SetGuardian,
+		"observe":                Observe,
+		"get_reserves":           GetReserves,
+		"get_amount_out":         GetAmountOut,
+		"get_amount_in":          GetAmountIn,
+		"get_lp_balance":         GetLPBalance,
+		"get_pool_config":        GetPoolConfig,
+		"get_claimable_fees":     GetClaimableFees,
+	})
+}
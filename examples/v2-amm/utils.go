@@ -2,25 +2,23 @@ package main
 
 import (
 	"contract-template/sdk"
+	"contract-template/sdk/twap"
+	"encoding/json"
+	"math"
 	"math/bits"
 	"strconv"
 )
 
-// Keys
+// lockFlash is held while a flash swap callback runs; every entrypoint that
+// moves funds or reserves refuses to run under it.
+const lockFlash = "pool"
+
+// minimumLiquidity LP units of the first mint are credited to lockedLP, an
+// address no one can sign for, so the pool never returns to an empty LP
+// supply whose share price a donation could inflate.
 const (
-	keyAsset0            = "pool/asset0"
-	keyAsset1            = "pool/asset1"
-	keyReserve0          = "pool/reserve0"
-	keyReserve1          = "pool/reserve1"
-	keyFee0              = "pool/fee0"
-	keyFee1              = "pool/fee1"
-	keyFeeLastClaimUnix  = "pool/fee_last_claim"
-	keyBaseFeeBps        = "pool/base_fee_bps"
-	keyFeeClaimIntervalS = "pool/fee_claim_interval_s"
-	keyTotalLP           = "pool/total_lp"
-	keyLPPrefix          = "lps/" // lps/<address>
-	keySlipBaselineBps   = "pool/slip_baseline_bps"
-	keySlipShareBps      = "pool/slip_share_bps"
+	minimumLiquidity = 1000
+	lockedLP         = sdk.Address("system:locked_lp")
 )
 
 const (
@@ -28,6 +26,8 @@ const (
 	defaultFeeClaimIntervalS = 86400 // 1 day
 	defaultSlipBaselineBps   = 0     // off by default
 	defaultSlipShareBps      = 0     // off by default
+	feeConvertMaxSlipBps     = 100   // 1% price impact and spot deviation per fee conversion
+	feeConvertWindowS        = 3600  // shortest TWAP window a fee conversion is checked against
 )
 
 // Utilities
@@ -41,20 +41,20 @@ func mustParseInt(s string) int64 {
 	return v
 }
 
-func getStr(key string) string {
-	v := sdk.StateGetObject(key)
+func getStr(key stateKey) string {
+	v := sdk.StateGetObject(string(key))
 	if v == nil {
 		return ""
 	}
 	return *v
 }
 
-func setStr(key string, val string) {
-	sdk.StateSetObject(key, val)
+func setStr(key stateKey, val string) {
+	sdk.StateSetObject(string(key), val)
 }
 
-func getUint(key string) uint64 {
-	v := sdk.StateGetObject(key)
+func getUint(key stateKey) uint64 {
+	v := sdk.StateGetObject(string(key))
 	if v == nil {
 		return 0
 	}
@@ -62,12 +62,12 @@ func getUint(key string) uint64 {
 	return n
 }
 
-func setUint(key string, val uint64) {
-	sdk.StateSetObject(key, strconv.FormatUint(val, 10))
+func setUint(key stateKey, val uint64) {
+	sdk.StateSetObject(string(key), strconv.FormatUint(val, 10))
 }
 
-func getInt(key string) int64 {
-	v := sdk.StateGetObject(key)
+func getInt(key stateKey) int64 {
+	v := sdk.StateGetObject(string(key))
 	if v == nil {
 		return 0
 	}
@@ -75,8 +75,8 @@ func getInt(key string) int64 {
 	return n
 }
 
-func setInt(key string, val int64) {
-	sdk.StateSetObject(key, strconv.FormatInt(val, 10))
+func setInt(key stateKey, val int64) {
+	sdk.StateSetObject(string(key), strconv.FormatInt(val, 10))
 }
 
 func min64(a, b uint64) uint64 {
@@ -125,8 +125,13 @@ func parseUintStrict(s string) uint64 {
 	return v
 }
 
-func lpKey(addr sdk.Address) string {
-	return keyLPPrefix + addr.String()
+// parseAddress rejects malformed payload addresses before funds move to them.
+func parseAddress(s string) sdk.Address {
+	addr, err := sdk.ParseAddress(s)
+	if err != nil {
+		panic("bad address")
+	}
+	return addr
 }
 
 func assert(cond bool) {
@@ -135,17 +140,6 @@ func assert(cond bool) {
 	}
 }
 
-func isSystemSender() bool {
-	env := sdk.GetEnv()
-	if env.Sender.Address.Domain() == sdk.AddressDomainSystem {
-		return true
-	}
-	if len(env.Sender.RequiredAuths) > 0 && env.Sender.RequiredAuths[0].Domain() == sdk.AddressDomainSystem {
-		return true
-	}
-	return false
-}
-
 func getAssets() (sdk.Asset, sdk.Asset) {
 	a0 := getStr(keyAsset0)
 	a1 := getStr(keyAsset1)
@@ -154,16 +148,28 @@ func getAssets() (sdk.Asset, sdk.Asset) {
 
 func isHbd(a sdk.Asset) bool { return a == sdk.AssetHbd }
 
-// Token adapter wrappers. Today we only support native Hive assets through the host.
-// These helpers allow future swap-in of contract token calls without changing core logic.
-func drawAsset(amount int64, asset sdk.Asset) { sdk.HiveDraw(amount, asset) }
+// Token adapter wrappers. Assets are native Hive assets or token contracts
+// ("contract:xyz/TOKEN"); zero amounts are a no-op.
+func drawAsset(amount int64, asset sdk.Asset) {
+	if amount != 0 {
+		sdk.TokenOf(asset).Draw(amount)
+	}
+}
+
 func transferAsset(to sdk.Address, amount int64, asset sdk.Asset) {
-	sdk.HiveTransfer(to, amount, asset)
+	if amount != 0 {
+		sdk.TokenOf(asset).Transfer(to, amount)
+	}
+}
+
+func validAsset(s string) bool {
+	_, err := sdk.ParseToken(s)
+	return err == nil
 }
 
 // State helpers for LP balances
 func getLP(addr sdk.Address) uint64 {
-	v := sdk.StateGetObject(lpKey(addr))
+	v := sdk.StateGetObject(string(lpKey(addr)))
 
 	//This may not return nil. It might be ""
 	if v == nil {
@@ -173,6 +179,404 @@ func getLP(addr sdk.Address) uint64 {
 	return n
 }
 
+// setLP also keeps the LP holder index (see holderAt): addresses enter it
+// when they get LP and leave it when their balance drops to 0. Holders from
+// before the index existed enter it on their next LP change, or when
+// si_index_holders lists them.
 func setLP(addr sdk.Address, amount uint64) {
 	setUint(lpKey(addr), amount)
+	idx := getUint(holderIdxKey(addr))
+	if amount > 0 && idx == 0 {
+		n := getUint(keyHolderCount)
+		setStr(holderKey(n), addr.String())
+		setUint(holderIdxKey(addr), n+1)
+		setUint(keyHolderCount, n+1)
+	} else if amount == 0 && idx > 0 {
+		// keep the list dense: the last holder takes the freed slot
+		last := getUint(keyHolderCount) - 1
+		if idx-1 != last {
+			moved := holderAt(last)
+			setStr(holderKey(idx-1), moved.String())
+			setUint(holderIdxKey(moved), idx)
+		}
+		sdk.StateDeleteObject(string(holderKey(last)))
+		sdk.StateDeleteObject(string(holderIdxKey(addr)))
+		setUint(keyHolderCount, last)
+	}
+}
+
+// holderAt returns the LP holder at position i of the index, i below
+// pool/holder_count. Positions change as holders leave.
+func holderAt(i uint64) sdk.Address {
+	return sdk.Address(getStr(holderKey(i)))
+}
+
+// withdrawLP burns lp of addr's LP and pays addr its share of the reserves,
+// aborting if that is below min0 or min1. The caller checks the balance.
+func withdrawLP(addr sdk.Address, lp, min0, min1 uint64) liquidityQuote {
+	totalLP := getUint(keyTotalLP)
+	r0 := uint64(getInt(keyReserve0))
+	r1 := uint64(getInt(keyReserve1))
+	q := quoteRemove(lp, r0, r1, totalLP)
+	assert(q.Amount0 >= min0 && q.Amount1 >= min1)
+
+	// book-keep first
+	setLP(addr, getLP(addr)-lp)
+	setUint(keyTotalLP, totalLP-lp)
+	setInt(keyReserve0, int64(r0-q.Amount0))
+	setInt(keyReserve1, int64(r1-q.Amount1))
+
+	// transfer out
+	asset0, asset1 := getAssets()
+	if q.Amount0 > 0 {
+		transferAsset(addr, int64(q.Amount0), asset0)
+	}
+	if q.Amount1 > 0 {
+		transferAsset(addr, int64(q.Amount1), asset1)
+	}
+	return q
+}
+
+// Price oracle. The accumulators must advance with the reserves that were in
+// effect, so updateOracle runs before every reserve change.
+func getObservation() twap.Observation {
+	p0, _ := twap.ParseCumulative(getStr(keyPrice0Cumulative))
+	p1, _ := twap.ParseCumulative(getStr(keyPrice1Cumulative))
+	return twap.Observation{Time: getUint(keyPriceTime), Price0: p0, Price1: p1}
+}
+
+func setObservation(o twap.Observation) {
+	setStr(keyPrice0Cumulative, o.Price0.String())
+	setStr(keyPrice1Cumulative, o.Price1.String())
+	setUint(keyPriceTime, o.Time)
+}
+
+// currentObservation is the stored observation advanced to this block.
+func currentObservation() twap.Observation {
+	r0 := uint64(getInt(keyReserve0))
+	r1 := uint64(getInt(keyReserve1))
+	return getObservation().Advance(sdk.GetEnv().BlockTime(), r0, r1)
+}
+
+func updateOracle() {
+	setObservation(currentObservation())
+}
+
+// Swap math, shared by every path that prices a swap.
+type swapQuote struct {
+	dxEff uint64 // input added to the input reserve, after the base fee
+	fee   uint64 // base fee kept from the input (HBD asset0 input only)
+	out   uint64 // output after the slip fee, before any referral
+	slip  uint64 // slip fee kept in the output reserve
+}
+
+// swapParams are the pool settings quote depends on, read once per call.
+type swapParams struct {
+	asset0          sdk.Asset
+	feeBps          uint64
+	baselineSlipBps uint64
+	shareSlipBps    uint64
+}
+
+func loadSwapParams() swapParams {
+	asset0, _ := getAssets()
+	return swapParams{
+		asset0:          asset0,
+		feeBps:          getUint(keyBaseFeeBps),
+		baselineSlipBps: getUint(keySlipBaselineBps),
+		shareSlipBps:    getUint(keySlipShareBps),
+	}
+}
+
+// quote prices amountIn in direction dir against reserves r0, r1: the base
+// fee applies only to HBD input on the asset0 side, the output follows x*y=k
+// on the effective input, and the slip fee keeps a share of slippage above
+// the baseline in the reserves for LPs. out is 0 when the swap would pay
+// nothing or drain the output reserve.
+func (p swapParams) quote(dir string, amountIn, r0, r1 uint64) swapQuote {
+	rIn, rOut := r0, r1
+	dxEff := amountIn
+	switch dir {
+	case "0to1":
+		if isHbd(p.asset0) && p.feeBps > 0 {
+			dxEff = mulDiv(amountIn, 10_000-p.feeBps, 10_000)
+		}
+		if dxEff <= 0 {
+			dxEff = 1
+		}
+	case "1to0":
+		rIn, rOut = r1, r0
+	default:
+		assert(false)
+	}
+	q := swapQuote{dxEff: dxEff, fee: amountIn - dxEff}
+
+	// constant product x*y=k, output dy = rOut - k/(rIn+dxEff), with k in
+	// 128 bits
+	newIn := rIn + dxEff
+	if rIn == 0 || rOut == 0 || newIn < rIn {
+		return q
+	}
+	dy := rOut - mulDiv(rIn, rOut, newIn)
+	if dy == 0 || dy >= rOut {
+		return q
+	}
+
+	// slippage-adjusted extra fee to LPs (reduce user output and keep in reserves)
+	q.out = dy
+	if p.shareSlipBps > 0 {
+		nominal := mulDivSat(rOut, dxEff, rIn)
+		if nominal > 0 {
+			slipBps := mulDiv(nominal-dy, 10_000, nominal)
+			if slipBps > p.baselineSlipBps {
+				excess := slipBps - p.baselineSlipBps
+				// outExtra = dy * excessBps * shareBps / 1e8
+				outExtra := mulDiv(dy, excess*p.shareSlipBps, 10_000*10_000)
+				if outExtra >= q.out {
+					outExtra = q.out - 1
+				}
+				q.out -= outExtra
+				q.slip = outExtra
+			}
+		}
+	}
+	return q
+}
+
+// quoteSwap is quote with the current settings; it aborts if the swap pays
+// nothing.
+func quoteSwap(dir string, amountIn, r0, r1 uint64) swapQuote {
+	q := loadSwapParams().quote(dir, amountIn, r0, r1)
+	assert(q.out > 0)
+	return q
+}
+
+// referralOut is the share of a 1to0 output paid to the referral
+// beneficiary; the caller always keeps at least one unit.
+func referralOut(out, refBps uint64) uint64 {
+	ref := mulDiv(out, refBps, 10_000)
+	if ref >= out && out > 0 {
+		ref = out - 1
+	}
+	return ref
+}
+
+// payout splits a quoted swap between the caller and the referral
+// beneficiary: the 0to1 referral comes out of the base fee, the 1to0 one out
+// of the output.
+func (q swapQuote) payout(dir string, refBps uint64) (paid, ref uint64) {
+	if dir == "0to1" {
+		return q.out, mulDiv(q.fee, refBps, 10_000)
+	}
+	ref = referralOut(q.out, refBps)
+	return q.out - ref, ref
+}
+
+// inputFor returns the smallest input, at most maxIn, whose swap pays the
+// caller at least net after the slip fee and the 1to0 referral. It aborts
+// if maxIn is not enough.
+func inputFor(dir string, net, maxIn, refBps, r0, r1 uint64) uint64 {
+	p := loadSwapParams()
+	paid := func(in uint64) uint64 {
+		out, _ := p.quote(dir, in, r0, r1).payout(dir, refBps)
+		return out
+	}
+	if maxIn > math.MaxInt64 {
+		maxIn = math.MaxInt64
+	}
+	// larger inputs would drain the output reserve, which quote prices at 0
+	rIn, rOut := r0, r1
+	if dir == "1to0" {
+		rIn, rOut = r1, r0
+	}
+	if rOut == 0 {
+		maxIn = 0
+	} else if hi, lim := bits.Mul64(rIn, rOut-1); hi == 0 && maxIn > lim {
+		maxIn = lim
+	}
+	assert(maxIn > 0 && paid(maxIn) >= net)
+	lo, hi := uint64(1), maxIn
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if paid(mid) >= net {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+// mulDiv returns a*b/c without overflowing the intermediate product.
+func mulDiv(a, b, c uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	assert(hi < c)
+	q, _ := bits.Div64(hi, lo, c)
+	return q
+}
+
+// mulDivSat is mulDiv saturating at MaxUint64 instead of aborting.
+func mulDivSat(a, b, c uint64) uint64 {
+	if hi, _ := bits.Mul64(a, b); hi >= c {
+		return math.MaxUint64
+	}
+	return mulDiv(a, b, c)
+}
+
+// liquidityQuote is a deposit or withdrawal: the asset amounts moved and the
+// LP minted or burned.
+type liquidityQuote struct {
+	Amount0 uint64 `json:"amount0"`
+	Amount1 uint64 `json:"amount1"`
+	LP      uint64 `json:"lp"`
+}
+
+// quoteAdd scales the deposit amt0, amt1 down to the reserve ratio so that
+// nothing is donated to existing LPs, and returns the amounts to draw and the
+// LP they mint. The first deposit sets the ratio, is taken in full and mints
+// its geometric mean less minimumLiquidity.
+func quoteAdd(amt0, amt1, r0, r1, totalLP uint64) liquidityQuote {
+	if totalLP == 0 {
+		hi, lo := bits.Mul64(amt0, amt1)
+		q := liquidityQuote{Amount0: amt0, Amount1: amt1}
+		if lp := sqrt128(hi, lo); lp > minimumLiquidity {
+			q.LP = lp - minimumLiquidity
+		}
+		return q
+	}
+	if r0 == 0 || r1 == 0 {
+		return liquidityQuote{}
+	}
+	q := liquidityQuote{Amount0: amt0, Amount1: amt1}
+	if opt1 := mulDiv(amt0, r1, r0); opt1 <= amt1 {
+		q.Amount1 = opt1
+	} else {
+		q.Amount0 = mulDiv(amt1, r0, r1)
+	}
+	q.LP = min64(mulDiv(q.Amount0, totalLP, r0), mulDiv(q.Amount1, totalLP, r1))
+	return q
+}
+
+// quoteRemove returns the reserve share paid out for burning lp.
+func quoteRemove(lp, r0, r1, totalLP uint64) liquidityQuote {
+	if totalLP == 0 {
+		return liquidityQuote{}
+	}
+	return liquidityQuote{Amount0: mulDiv(r0, lp, totalLP), Amount1: mulDiv(r1, lp, totalLP), LP: lp}
+}
+
+// optUint parses the optional payload field i, 0 when absent or empty.
+func optUint(parts []string, i int) uint64 {
+	s := optField(parts, i)
+	if s == "" {
+		return 0
+	}
+	return parseUintStrict(s)
+}
+
+// optField returns the optional payload field i, "" when absent.
+func optField(parts []string, i int) string {
+	if i >= len(parts) {
+		return ""
+	}
+	return parts[i]
+}
+
+// retJSON marshals a view result.
+func retJSON(v interface{}) *string {
+	b, _ := json.Marshal(v)
+	s := string(b)
+	return &s
+}
+
+func setFeeConvertObs(o twap.Observation) {
+	b, _ := json.Marshal(o)
+	setStr(keyFeeConvertObs, string(b))
+}
+
+// mulPrice returns floor(p * x), saturating at MaxUint64.
+func mulPrice(p twap.UQ64x64, x uint64) uint64 {
+	hh, hl := bits.Mul64(p.Hi, x)
+	lh, _ := bits.Mul64(p.Lo, x)
+	sum, carry := bits.Add64(hl, lh, 0)
+	if hh != 0 || carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
+
+// within reports whether v is at most bps basis points away from ref.
+func within(v, ref, bps uint64) bool {
+	diff := v - ref
+	if v < ref {
+		diff = ref - v
+	}
+	return diff <= ref/10_000*bps+ref%10_000*bps/10_000
+}
+
+// nextFeeClaim is the block time from which claim_fees may run. Pools
+// initialised before block time was parsed stored the raw timestamp.
+func nextFeeClaim() uint64 {
+	last, _ := sdk.ParseTimestamp(getStr(keyFeeLastClaimUnix))
+	return last + getUint(keyFeeClaimIntervalS)
+}
+
+// convertFees sells the fee bucket of the non-HBD asset to the pool for HBD,
+// booked into the HBD bucket, with the regular swap quote. Spot can be moved
+// within a block, so the sale is checked against the TWAP since the last
+// conversion (at least feeConvertWindowS long): it is skipped while spot is
+// more than feeConvertMaxSlipBps away from the average, and it sells at most
+// what moves the price another feeConvertMaxSlipBps (an output dx/(rIn+dx)
+// short of spot). A manipulated price can therefore cost a claim about twice
+// that; the rest waits for later claims. Pools without an HBD side would
+// need a router and keep their fees. The oracle must be current.
+func convertFees() {
+	a0, a1 := getAssets()
+	r0 := uint64(getInt(keyReserve0))
+	r1 := uint64(getInt(keyReserve1))
+	dir, keyIn, keyOut, rIn, rOut := "1to0", keyFee1, keyFee0, r1, r0
+	switch {
+	case isHbd(a0) && !isHbd(a1):
+	case isHbd(a1) && !isHbd(a0):
+		dir, keyIn, keyOut, rIn, rOut = "0to1", keyFee0, keyFee1, r0, r1
+	default:
+		return
+	}
+	now := getObservation()
+	start, _ := twap.ParseObservation(getStr(keyFeeConvertObs))
+	if start.Time == 0 || start.Time > now.Time {
+		// pools created before the window was tracked start it now
+		setFeeConvertObs(now)
+		return
+	}
+	if now.Time-start.Time < feeConvertWindowS {
+		return
+	}
+	setFeeConvertObs(now)
+	p0, p1, _ := twap.Average(start, now)
+	price := p1 // input asset priced in the output asset
+	if dir == "0to1" {
+		price = p0
+	}
+	if fair := mulPrice(price, rIn); !within(rOut, fair, feeConvertMaxSlipBps) {
+		return
+	}
+	fee := uint64(getInt(keyIn))
+	dx := min64(fee, mulDiv(rIn, feeConvertMaxSlipBps, 10_000-feeConvertMaxSlipBps))
+	if dx == 0 {
+		return
+	}
+	q := loadSwapParams().quote(dir, dx, r0, r1)
+	if q.out == 0 {
+		return
+	}
+	setInt(keyIn, int64(fee-dx))
+	setInt(keyOut, getInt(keyOut)+int64(q.out))
+	if dir == "1to0" {
+		setInt(keyReserve1, int64(r1+q.dxEff))
+		setInt(keyReserve0, int64(r0-q.out))
+	} else {
+		setInt(keyReserve0, int64(r0+q.dxEff))
+		setInt(keyReserve1, int64(r1-q.out))
+	}
 }
@@ -3,6 +3,9 @@ package main
 import (
 	"contract-template/sdk"
 	_ "contract-template/sdk"
+	"contract-template/sdk/access"
+	"contract-template/sdk/pause"
+	"contract-template/sdk/reentrancy"
 	"math/bits"
 	"strconv"
 	"strings"
@@ -13,6 +16,7 @@ func main() {}
 // Contract initialization
 // Payload: "asset0,asset1,baseFeeBps(optional)" e.g. "hbd,hive,8"
 //
+//abi:payload asset0:asset,asset1:asset,baseFeeBps:uint64?
 //go:wasmexport init
 func Init(payload *string) *string {
 	parts := strings.Split(strings.TrimSpace(*payload), ",")
@@ -34,22 +38,26 @@ func Init(payload *string) *string {
 	setInt(keyFee1, 0)
 	setUint(keyFeeClaimIntervalS, defaultFeeClaimIntervalS)
 	setStr(keyFeeLastClaimUnix, sdk.GetEnv().Timestamp)
-	setUint(keyPaused, 0)
-	setUint(keyReentrancy, 0)
 
 	return nil
 }
 
 // Add liquidity
-// Payload: "amt0,amt1"
+// Payload: "amt0,amt1[,deadline]"
+// The first deposit locks minimumLiquidity LP to lockedLP. Aborts once the
+// block is past deadline (see sdk.ParseDeadline).
 //
+//abi:payload amt0:uint64,amt1:uint64,deadline:string?
+//abi:mutability payable
 //go:wasmexport add_liquidity
 func AddLiquidity(payload *string) *string {
-	requireNotPaused()
-	enterReentrancy()
-	defer exitReentrancy()
+	requireNotPaused("add_liquidity")
+	reentrancy.Enter(lockPool)
 	params := strings.Split(strings.TrimSpace(*payload), ",")
-	assert(len(params) == 2)
+	assert(len(params) == 2 || len(params) == 3)
+	if len(params) == 3 {
+		sdk.RequireDeadline(params[2])
+	}
 	amt0U := parseUintStrict(params[0])
 	amt1U := parseUintStrict(params[1])
 
@@ -69,9 +77,18 @@ func AddLiquidity(payload *string) *string {
 
 	var minted uint64
 	if totalLP == 0 {
-		// geometric mean using 128-bit product
+		// geometric mean using 128-bit product, less the locked minimum
 		hi, lo := bits.Mul64(amt0U, amt1U)
 		minted = sqrt128(hi, lo)
+		assert(minted > minimumLiquidity)
+		minted -= minimumLiquidity
+		// reserves left by LP that was all burned belong to nobody; they go
+		// to the fee buckets instead of the new depositor
+		setInt(keyFee0, getInt(keyFee0)+int64(r0))
+		setInt(keyFee1, getInt(keyFee1)+int64(r1))
+		r0, r1 = 0, 0
+		setLP(lockedLP, getLP(lockedLP)+minimumLiquidity)
+		totalLP = minimumLiquidity
 	} else {
 		// proportional
 		m0 := amt0U * totalLP / r0
@@ -81,27 +98,29 @@ func AddLiquidity(payload *string) *string {
 	assert(minted > 0)
 
 	env := sdk.GetEnv()
-	if totalLP == 0 {
-		setLP(env.Sender.Address, minted)
-	} else {
-		setLP(env.Sender.Address, getLP(env.Sender.Address)+minted)
-	}
+	setLP(env.Sender.Address, getLP(env.Sender.Address)+minted)
 	setUint(keyTotalLP, totalLP+minted)
 	setInt(keyReserve0, int64(r0+amt0U))
 	setInt(keyReserve1, int64(r1+amt1U))
 
+	reentrancy.Exit(lockPool)
 	return nil
 }
 
 // Remove liquidity
-// Payload: "lpAmount"
+// Payload: "lpAmount[,deadline]"
 //
+//abi:payload lpAmount:uint64,deadline:string?
 //go:wasmexport remove_liquidity
 func RemoveLiquidity(payload *string) *string {
-	requireNotPaused()
-	enterReentrancy()
-	defer exitReentrancy()
-	lpToBurnU, _ := strconv.ParseUint(strings.TrimSpace(*payload), 10, 64)
+	requireNotPaused("remove_liquidity")
+	reentrancy.Enter(lockPool)
+	params := strings.Split(strings.TrimSpace(*payload), ",")
+	assert(len(params) == 1 || len(params) == 2)
+	if len(params) == 2 {
+		sdk.RequireDeadline(params[1])
+	}
+	lpToBurnU, _ := strconv.ParseUint(params[0], 10, 64)
 	env := sdk.GetEnv()
 	userLP := getLP(env.Sender.Address)
 	totalLP := getUint(keyTotalLP)
@@ -127,26 +146,30 @@ func RemoveLiquidity(payload *string) *string {
 	if amt1 > 0 {
 		sdk.HiveTransfer(env.Sender.Address, amt1, asset1)
 	}
+	reentrancy.Exit(lockPool)
 	return nil
 }
 
 // Swap
-// Payload: "dir,amountIn" where dir is "0to1" or "1to0"
-// @todo: add MinAmount to receive
+// Payload: "dir,amountIn[,minOut[,deadline]]" where dir is "0to1" or "1to0"
 //
+//abi:payload dir:enum(0to1|1to0),amountIn:uint64,minOut:uint64?,deadline:string?
+//abi:mutability payable
 //go:wasmexport swap
 func Swap(payload *string) *string {
-	requireNotPaused()
-	enterReentrancy()
-	defer exitReentrancy()
+	requireNotPaused("swap")
+	reentrancy.Enter(lockPool)
 	parts := strings.Split(strings.TrimSpace(*payload), ",")
-	assert(len(parts) == 2 || len(parts) == 3)
+	assert(len(parts) >= 2 && len(parts) <= 4)
 	dir := parts[0]
 	amountInU := parseUintStrict(parts[1])
 	minOutU := uint64(0)
-	if len(parts) == 3 && parts[2] != "" {
+	if len(parts) >= 3 && parts[2] != "" {
 		minOutU = parseUintStrict(parts[2])
 	}
+	if len(parts) == 4 {
+		sdk.RequireDeadline(parts[3])
+	}
 	assert(amountInU > 0)
 
 	feeBps := getUint(keyBaseFeeBps) // 0.08% irrespective of CLP dynamic
@@ -204,17 +227,19 @@ func Swap(payload *string) *string {
 	} else {
 		assert(false)
 	}
+	reentrancy.Exit(lockPool)
 	return nil
 }
 
 // Donate liquidity (no LP minted)
 // Payload: "amt0,amt1"
 //
+//abi:payload amt0:uint64,amt1:uint64
+//abi:mutability payable
 //go:wasmexport donate
 func Donate(payload *string) *string {
-	requireNotPaused()
-	enterReentrancy()
-	defer exitReentrancy()
+	requireNotPaused("donate")
+	reentrancy.Enter(lockPool)
 	params := strings.Split(strings.TrimSpace(*payload), ",")
 	assert(len(params) == 2)
 	amt0U, _ := strconv.ParseUint(params[0], 10, 64)
@@ -228,14 +253,16 @@ func Donate(payload *string) *string {
 		sdk.HiveDraw(int64(amt1U), a1)
 		setInt(keyReserve1, getInt(keyReserve1)+int64(amt1U))
 	}
+	reentrancy.Exit(lockPool)
 	return nil
 }
 
 // Claim reserve fees; send HBD fees to system account. Non-HBD conversion is left as a TODO.
 //
+//abi:payload none
 //go:wasmexport claim_fees
 func ClaimFees(_ *string) *string {
-	assert(isSystemSender())
+	access.RequireSystem()
 	systemFR := sdk.Address("hive:vsc.dao")
 	a0, a1 := getAssets()
 	f0 := getInt(keyFee0)
@@ -256,9 +283,10 @@ func ClaimFees(_ *string) *string {
 // Burn LP balances (permanently reduces total LP, locking proportion of reserves)
 // Payload: "lpAmount"
 //
+//abi:payload lpAmount:uint64
 //go:wasmexport burn
 func Burn(payload *string) *string {
-	requireNotPaused()
+	requireNotPaused("burn")
 	amt, _ := strconv.ParseUint(strings.TrimSpace(*payload), 10, 64)
 	env := sdk.GetEnv()
 	bal := getLP(env.Sender.Address)
@@ -272,9 +300,10 @@ func Burn(payload *string) *string {
 // Transfer LP tokens to another address
 // Payload: "toAddress,amount"
 //
+//abi:payload to:address,amount:uint64
 //go:wasmexport transfer
 func Transfer(payload *string) *string {
-	requireNotPaused()
+	requireNotPaused("transfer")
 	parts := strings.Split(strings.TrimSpace(*payload), ",")
 	assert(len(parts) == 2)
 	to := sdk.Address(parts[0])
@@ -290,12 +319,12 @@ func Transfer(payload *string) *string {
 // Safety interface: consensus-only emergency withdrawal by burning LP
 // Payload: "lpAmount"
 //
+//abi:payload address:address,lpAmount:uint64
 //go:wasmexport si_withdraw
 func SIWithdraw(payload *string) *string {
-	assert(isSystemSender())
-	requireNotPaused()
-	enterReentrancy()
-	defer exitReentrancy()
+	access.RequireSystem()
+	requireNotPaused("si_withdraw")
+	reentrancy.Enter(lockPool)
 	// burn from all LP proportionally is complex; here we burn from caller-specified LP (system must specify address and amount)
 	// For simplicity, we accept "address,lpAmount" here.
 	parts := strings.Split(strings.TrimSpace(*payload), ",")
@@ -305,7 +334,7 @@ func SIWithdraw(payload *string) *string {
 
 	totalLP := getUint(keyTotalLP)
 	bal := getLP(addr)
-	assert(amt > 0 && amt <= bal && totalLP > 0)
+	assert(addr != lockedLP && amt > 0 && amt <= bal && totalLP > 0)
 
 	r0 := uint64(getInt(keyReserve0))
 	r1 := uint64(getInt(keyReserve1))
@@ -326,29 +355,68 @@ func SIWithdraw(payload *string) *string {
 	if out1 > 0 {
 		sdk.HiveTransfer(addr, out1, a1)
 	}
+	reentrancy.Exit(lockPool)
 	return nil
 }
 
 // System function: set base fee (bps). Consensus-only.
 // Payload: "newBps"
 //
+//abi:payload newBps:uint64
 //go:wasmexport set_base_fee
 func SetBaseFee(payload *string) *string {
-	assert(isSystemSender())
+	access.RequireSystem()
 	v, _ := strconv.ParseUint(strings.TrimSpace(*payload), 10, 64)
 	assert(v <= 10_000)
 	setUint(keyBaseFeeBps, v)
 	return nil
 }
 
-// System function: pause/unpause
-// Payload: "0|1"
+// System function: pause/unpause the whole contract or a single entrypoint. Consensus-only.
+// Payload: "0|1" or "entrypoint,0|1"
 //
+//abi:payload paused:enum(0|1)
+//abi:payload entrypoint:string,paused:enum(0|1)
+//abi:auth system
 //go:wasmexport set_paused
 func SetPaused(payload *string) *string {
-	assert(isSystemSender())
-	v := parseUintStrict(strings.TrimSpace(*payload))
+	parts := strings.Split(strings.TrimSpace(*payload), ",")
+	assert(len(parts) == 1 || len(parts) == 2)
+	ep := pause.All
+	if len(parts) == 2 {
+		ep = parts[0]
+	}
+	v := parseUintStrict(parts[len(parts)-1])
+	assert(v == 0 || v == 1)
+	pause.SetPaused(ep, v == 1)
+	if ep == pause.All {
+		sdk.StateDeleteObject(keyLegacyPaused)
+	}
+	return nil
+}
+
+// Emergency pause by a guardian. Only consensus can unpause via set_paused.
+// Payload: "" for the whole contract or "entrypoint"
+//
+//abi:payload entrypoint:string?
+//abi:auth role:guardian
+//go:wasmexport pause
+func Pause(payload *string) *string {
+	pause.Pause(strings.TrimSpace(*payload))
+	return nil
+}
+
+// System function: add or remove a guardian. Consensus-only.
+// Payload: "address,0|1"
+//
+//abi:payload address:address,enabled:enum(0|1)
+//go:wasmexport set_guardian
+func SetGuardian(payload *string) *string {
+	access.RequireSystem()
+	parts := strings.Split(strings.TrimSpace(*payload), ",")
+	assert(len(parts) == 2)
+	v := parseUintStrict(parts[1])
 	assert(v == 0 || v == 1)
-	setUint(keyPaused, v)
+	pause.SetGuardian(sdk.Address(parts[0]), v == 1)
 	return nil
 }
@@ -187,3 +187,133 @@ func TestV2_Donate_Transfer_Burn_ClaimFees_System(t *testing.T) {
 		t.Fatal("total LP not reduced after SIWithdraw")
 	}
 }
+
+func expectPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic but none occurred")
+		}
+	}()
+	f()
+}
+
+func TestV2_MinimumLiquidity_InflationAttack(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	mallory, bob := sdk.Address("hive:mallory"), sdk.Address("hive:bob")
+	sdk.ShimSetSender(mallory)
+	sdk.ShimSetBalance(mallory, sdk.AssetHbd, 200_000)
+	sdk.ShimSetBalance(mallory, sdk.AssetHive, 200_000)
+	Init(sptr("hbd,hive,30"))
+
+	// the first deposit must mint more than the locked minimum
+	restore := sdk.ShimSnapshot()
+	expectPanic(t, func() { AddLiquidity(sptr("1000,1000")) })
+	restore()
+
+	// mallory mints a single LP unit and donates to inflate its price
+	AddLiquidity(sptr("1001,1001"))
+	if getLP(mallory) != 1 || getLP(lockedLP) != minimumLiquidity || getUint(keyTotalLP) != 1001 {
+		t.Fatalf("first mint: mallory %d, locked %d", getLP(mallory), getLP(lockedLP))
+	}
+	Donate(sptr("100000,100000"))
+
+	// bob's deposit still mints a fair share: the donation mostly accrues
+	// to the locked LP, not to mallory
+	sdk.ShimSetSender(bob)
+	sdk.ShimSetBalance(bob, sdk.AssetHbd, 50_000)
+	sdk.ShimSetBalance(bob, sdk.AssetHive, 50_000)
+	AddLiquidity(sptr("50000,50000"))
+	RemoveLiquidity(sptr(strconv.FormatUint(getLP(bob), 10)))
+	if got := sdk.ShimGetBalance(bob, sdk.AssetHbd); got < 49_950 {
+		t.Fatalf("bob lost %d of 50000 to rounding", 50_000-got)
+	}
+
+	sdk.ShimSetSender(mallory)
+	RemoveLiquidity(sptr("1"))
+	if got := sdk.ShimGetBalance(mallory, sdk.AssetHbd); got > 100_000 {
+		t.Fatalf("mallory kept %d hbd, the attack must cost the donation", got)
+	}
+
+	// the locked LP cannot be withdrawn
+	sdk.ShimSetEnv("msg.required_auths", `["system:consensus"]`)
+	expectPanic(t, func() { SIWithdraw(sptr("system:locked_lp,1000")) })
+	if getUint(keyTotalLP) != minimumLiquidity {
+		t.Fatal("total LP must stay at the locked minimum")
+	}
+}
+
+func TestV2_MinimumLiquidity_EmptiedPool(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 100_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 100_000)
+	Init(sptr("hbd,hive,30"))
+
+	// a pool whose LP was all burned before the lock existed
+	setInt(keyReserve0, 7_000)
+	setInt(keyReserve1, 3_000)
+	sdk.ShimSetBalance("contract:v2", sdk.AssetHbd, 7_000)
+	sdk.ShimSetBalance("contract:v2", sdk.AssetHive, 3_000)
+
+	AddLiquidity(sptr("10000,40000"))
+	if getInt(keyReserve0) != 10_000 || getInt(keyReserve1) != 40_000 || getInt(keyFee0) != 7_000 || getInt(keyFee1) != 3_000 {
+		t.Fatal("orphaned reserves must move to the fee buckets, not to the new depositor")
+	}
+	if getLP(alice) != 20_000-minimumLiquidity || getUint(keyTotalLP) != 20_000 {
+		t.Fatalf("re-initialising mint: alice %d, total %d", getLP(alice), getUint(keyTotalLP))
+	}
+	Burn(sptr(strconv.FormatUint(getLP(alice), 10)))
+	if getUint(keyTotalLP) != minimumLiquidity {
+		t.Fatal("burning every holder's LP leaves the locked minimum")
+	}
+}
+
+func TestV2_LegacyPauseFlag(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 100_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 100_000)
+	Init(sptr("hbd,hive,30"))
+	AddLiquidity(sptr("10000,10000"))
+	// a pool consensus paused before the upgrade stays paused
+	setUint(keyLegacyPaused, 1)
+	expectPanic(t, func() { Swap(sptr("0to1,1000")) })
+	expectPanic(t, func() { RemoveLiquidity(sptr("1000")) })
+
+	sdk.ShimSetSender("system:consensus")
+	SetPaused(sptr("swap,0"))
+	sdk.ShimSetSender(alice)
+	expectPanic(t, func() { Swap(sptr("0to1,1000")) })
+
+	sdk.ShimSetSender("system:consensus")
+	SetPaused(sptr("0"))
+	sdk.ShimSetSender(alice)
+	Swap(sptr("0to1,1000"))
+}
+
+func TestV2_Deadlines(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:v2")
+	alice := sdk.Address("hive:alice")
+	sdk.ShimSetSender(alice)
+	sdk.ShimSetBalance(alice, sdk.AssetHbd, 100_000)
+	sdk.ShimSetBalance(alice, sdk.AssetHive, 100_000)
+	Init(sptr("hbd,hive,30"))
+	sdk.ShimSetEnv("anchor.height", "100")
+	sdk.ShimSetTimestamp("2025-06-01T12:00:00")
+
+	AddLiquidity(sptr("10000,10000,100"))
+	Swap(sptr("0to1,1000,0,2025-06-01T12:00:00Z"))
+	RemoveLiquidity(sptr("1000,101"))
+	for _, p := range []string{"1000,1000,99", "1000,1000,2025-06-01T11:59:59"} {
+		expectPanic(t, func() { AddLiquidity(sptr(p)) })
+	}
+	expectPanic(t, func() { Swap(sptr("0to1,1000,0,99")) })
+	expectPanic(t, func() { RemoveLiquidity(sptr("1000,2025-06-01T11:00:00")) })
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"contract-template/sdk"
+	"contract-template/sdk/pause"
 	"math/bits"
 	"strconv"
 )
@@ -18,9 +19,19 @@ const (
 	keyBaseFeeBps        = "pool/base_fee_bps"
 	keyFeeClaimIntervalS = "pool/fee_claim_interval_s"
 	keyTotalLP           = "pool/total_lp"
-	keyLPPrefix          = "lps/" // lps/<address>
-	keyPaused            = "pool/paused"
-	keyReentrancy        = "pool/reentrancy"
+	keyLPPrefix          = "lps/"        // lps/<address>
+	keyLegacyPaused      = "pool/paused" // contract-wide pause of pools from before sdk/pause
+)
+
+// Reentrancy lock shared by every entrypoint that moves funds
+const lockPool = "pool"
+
+// minimumLiquidity LP units of the first mint are credited to lockedLP, an
+// address no one can sign for, so the LP supply never returns to zero and a
+// donation cannot inflate the share price of a near-empty pool.
+const (
+	minimumLiquidity = 1000
+	lockedLP         = sdk.Address("system:locked_lp")
 )
 
 const (
@@ -28,6 +39,16 @@ const (
 	defaultFeeClaimIntervalS = 86400 // 1 day
 )
 
+// requireNotPaused is pause.RequireNotPaused that also honours the legacy
+// pool/paused flag until consensus lifts the contract-wide pause with
+// set_paused.
+func requireNotPaused(entrypoint string) {
+	if getUint(keyLegacyPaused) != 0 {
+		sdk.Abort("pause: contract is paused")
+	}
+	pause.RequireNotPaused(entrypoint)
+}
+
 // Utilities
 func mustParseUint(s string) uint64 {
 	v, _ := strconv.ParseUint(s, 10, 64)
@@ -123,11 +144,6 @@ func parseUintStrict(s string) uint64 {
 	return v
 }
 
-func requireNotPaused() { assert(getUint(keyPaused) == 0) }
-
-func enterReentrancy() { assert(getUint(keyReentrancy) == 0); setUint(keyReentrancy, 1) }
-func exitReentrancy()  { setUint(keyReentrancy, 0) }
-
 func lpKey(addr sdk.Address) string {
 	return keyLPPrefix + addr.String()
 }
@@ -138,11 +154,6 @@ func assert(cond bool) {
 	}
 }
 
-func isSystemSender() bool {
-	env := sdk.GetEnv()
-	return env.Sender.Address.Domain() == sdk.AddressDomainSystem
-}
-
 func getAssets() (sdk.Asset, sdk.Asset) {
 	a0 := getStr(keyAsset0)
 	a1 := getStr(keyAsset1)
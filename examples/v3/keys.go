@@ -0,0 +1,33 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"strconv"
+)
+
+// stateKey names an entry in contract state. Every key the pool writes is
+// declared here so its state layout reads in one place.
+type stateKey string
+
+const (
+	KeyAsset0      stateKey = "asset0"
+	KeyAsset1      stateKey = "asset1"
+	KeyFeeBps      stateKey = "fee_bps"
+	KeySqrtP       stateKey = "sqrt_price_q32"
+	KeyLiquidity   stateKey = "liquidity"
+	KeyActiveLower stateKey = "active_lower_q32"
+	KeyActiveUpper stateKey = "active_upper_q32"
+	KeyFeeGrowth0  stateKey = "fee_growth0_q32"
+	KeyFeeGrowth1  stateKey = "fee_growth1_q32"
+
+	// schema v0 only, removed by migrateV0toV1
+	keyLegacyPaused  stateKey = "paused"
+	keyLegacyFeeAcc0 stateKey = "fee_acc0"
+	keyLegacyFeeAcc1 stateKey = "fee_acc1"
+)
+
+// posKey is the suffix field of the position of addr over [lower, upper]:
+// pos/<address>/<lower>/<upper>/<suffix>.
+func posKey(addr sdk.Address, lower, upper uint64, suffix string) stateKey {
+	return stateKey("pos/" + addr.String() + "/" + strconv.FormatUint(lower, 10) + "/" + strconv.FormatUint(upper, 10) + "/" + suffix)
+}
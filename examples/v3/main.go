@@ -2,13 +2,19 @@ package main
 
 import (
 	"contract-template/sdk"
-	"math/big"
+	"contract-template/sdk/access"
+	"contract-template/sdk/migrate"
+	"contract-template/sdk/pause"
+	"math/big" //contractlint:allow import -- Q64.64 sqrt price math needs 256-bit intermediates
 	"strconv"
 	"strings"
 )
 
+func main() {}
+
 // Minimal v3-style AMM with a single active price range and per-position fee growth snapshots.
 
+//abi:payload asset0:asset,asset1:asset,baseFeeBps:uint64,initSqrtQ32:uint64,activeLowerQ32:uint64,activeUpperQ32:uint64
 //go:wasmexport init
 func Init(arg *string) *string {
 	// args: asset0,asset1,base_fee_bps,init_sqrt_q32,active_lower_q32,active_upper_q32
@@ -44,17 +50,34 @@ func Init(arg *string) *string {
 	setU(KeyLiquidity, 0)
 	setU(KeyFeeGrowth0, 0)
 	setU(KeyFeeGrowth1, 0)
+	migrate.Init(migrations)
+	return nil
+}
+
+// Upgrade state written by an older version of this contract. Owner or consensus only.
+//
+//abi:payload none
+//abi:auth owner
+//go:wasmexport migrate
+func Migrate(_ *string) *string {
+	migrate.Run(migrations)
 	return nil
 }
 
+//abi:payload lowerQ32:uint64,upperQ32:uint64,maxAmount0:uint64,maxAmount1:uint64,deadline:string?
+//abi:mutability payable
 //go:wasmexport mint
 func Mint(arg *string) *string {
-	//RequireNotPaused()
-	// args: lower_q32,upper_q32,max_amount0,max_amount1
+	migrate.RequireCurrent(migrations)
+	pause.RequireNotPaused("mint")
+	// args: lower_q32,upper_q32,max_amount0,max_amount1(,deadline)
 	p := strings.Split(strings.TrimSpace(*arg), ",")
-	if len(p) != 4 {
+	if len(p) != 4 && len(p) != 5 {
 		sdk.Abort("invalid args")
 	}
+	if len(p) == 5 {
+		sdk.RequireDeadline(p[4])
+	}
 	lower, err := strconv.ParseUint(p[0], 10, 64)
 	if err != nil {
 		sdk.Abort("parse error")
@@ -110,14 +133,19 @@ func Mint(arg *string) *string {
 	return nil
 }
 
+//abi:payload lowerQ32:uint64,upperQ32:uint64,liquidity:uint64,deadline:string?
 //go:wasmexport burn
 func Burn(arg *string) *string {
-	//RequireNotPaused()
-	// args: lower_q32,upper_q32,liquidity
+	migrate.RequireCurrent(migrations)
+	pause.RequireNotPaused("burn")
+	// args: lower_q32,upper_q32,liquidity(,deadline)
 	p := strings.Split(strings.TrimSpace(*arg), ",")
-	if len(p) != 3 {
+	if len(p) != 3 && len(p) != 4 {
 		sdk.Abort("invalid args")
 	}
+	if len(p) == 4 {
+		sdk.RequireDeadline(p[3])
+	}
 	lower, err := strconv.ParseUint(p[0], 10, 64)
 	if err != nil {
 		sdk.Abort("parse error")
@@ -157,9 +185,11 @@ func Burn(arg *string) *string {
 	return nil
 }
 
+//abi:payload lowerQ32:uint64,upperQ32:uint64
 //go:wasmexport collect
 func Collect(arg *string) *string {
-	//RequireNotPaused()
+	migrate.RequireCurrent(migrations)
+	pause.RequireNotPaused("collect")
 	// args: lower_q32,upper_q32
 	p := strings.Split(strings.TrimSpace(*arg), ",")
 	if len(p) != 2 {
@@ -190,11 +220,10 @@ func Collect(arg *string) *string {
 	return nil
 }
 
+//abi:payload bps:uint64
 //go:wasmexport set_fee
 func SetFee(arg *string) *string {
-	if !isSystemSender() {
-		sdk.Abort("only system")
-	}
+	access.RequireSystem()
 	v, err := strconv.ParseUint(strings.TrimSpace(*arg), 10, 64)
 	if err != nil || v > 10_000 {
 		sdk.Abort("bad bps")
@@ -203,11 +232,10 @@ func SetFee(arg *string) *string {
 	return nil
 }
 
+//abi:payload lowerQ32:uint64,upperQ32:uint64
 //go:wasmexport set_active_range
 func SetActiveRange(arg *string) *string {
-	if !isSystemSender() {
-		sdk.Abort("only system")
-	}
+	access.RequireSystem()
 	p := strings.Split(strings.TrimSpace(*arg), ",")
 	if len(p) != 2 {
 		sdk.Abort("invalid args")
@@ -232,27 +260,80 @@ func SetActiveRange(arg *string) *string {
 	return nil
 }
 
+// args: "0|1" or "entrypoint,0|1"; consensus-only
+//
+//abi:payload paused:enum(0|1)
+//abi:payload entrypoint:string,paused:enum(0|1)
+//abi:auth system
+//go:wasmexport set_paused
+func SetPaused(arg *string) *string {
+	p := strings.Split(strings.TrimSpace(*arg), ",")
+	if len(p) != 1 && len(p) != 2 {
+		sdk.Abort("invalid args")
+	}
+	ep := pause.All
+	if len(p) == 2 {
+		ep = p[0]
+	}
+	v := p[len(p)-1]
+	if v != "0" && v != "1" {
+		sdk.Abort("invalid args")
+	}
+	pause.SetPaused(ep, v == "1")
+	return nil
+}
+
+// args: "" for the whole contract or "entrypoint"; guardian emergency stop
+//
+//abi:payload entrypoint:string?
+//abi:auth role:guardian
+//go:wasmexport pause
+func Pause(arg *string) *string {
+	pause.Pause(strings.TrimSpace(*arg))
+	return nil
+}
+
+// args: "address,0|1"; consensus-only
+//
+//abi:payload address:address,enabled:enum(0|1)
+//go:wasmexport set_guardian
+func SetGuardian(arg *string) *string {
+	access.RequireSystem()
+	p := strings.Split(strings.TrimSpace(*arg), ",")
+	if len(p) != 2 || (p[1] != "0" && p[1] != "1") {
+		sdk.Abort("invalid args")
+	}
+	pause.SetGuardian(sdk.Address(p[0]), p[1] == "1")
+	return nil
+}
+
+//abi:payload dir:enum(0to1|1to0),amountIn:uint64,minOut:uint64?,deadline:string?
+//abi:mutability payable
 //go:wasmexport swap
 func Swap(arg *string) *string {
-	// args: dir,amountIn(,minOut)
+	// args: dir,amountIn(,minOut(,deadline))
 	p := strings.Split(strings.TrimSpace(*arg), ",")
-	if len(p) != 2 && len(p) != 3 {
+	if len(p) < 2 || len(p) > 4 {
 		sdk.Abort("invalid args")
 	}
+	if len(p) == 4 {
+		sdk.RequireDeadline(p[3])
+	}
 	dir := p[0]
 	amtIn, err := strconv.ParseUint(p[1], 10, 64)
 	if err != nil {
 		sdk.Abort("parse error")
 	}
 	minOut := uint64(0)
-	if len(p) == 3 && p[2] != "" {
+	if len(p) >= 3 && p[2] != "" {
 		m, err := strconv.ParseUint(p[2], 10, 64)
 		if err != nil {
 			sdk.Abort("parse error")
 		}
 		minOut = m
 	}
-	//RequireNotPaused()
+	migrate.RequireCurrent(migrations)
+	pause.RequireNotPaused("swap")
 	feeBps := getU(KeyFeeBps)
 	sqrtP := getU(KeySqrtP)
 	L := getU(KeyLiquidity)
@@ -370,6 +451,9 @@ func Swap(arg *string) *string {
 	return nil
 }
 
+//abi:payload number:string
+//abi:returns string
+//abi:mutability view
 //go:wasmexport format_number
 func FormatNumber(arg *string) *string {
 	// Accept a single number (decimal or 0x-hex). Validate it fits in 256 bits.
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/migrate"
+	"testing"
+)
+
+func sptr(s string) *string { return &s }
+
+func expectPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic but none occurred")
+		}
+	}()
+	f()
+}
+
+func TestV3_Init_StampsLatestSchema(t *testing.T) {
+	sdk.ShimReset()
+	Init(sptr("hbd,hive,30,4294967296,2147483648,8589934592"))
+	if migrate.Version() != migrate.Latest(migrations) {
+		t.Fatalf("schema version = %d, want %d", migrate.Version(), migrate.Latest(migrations))
+	}
+	if migrate.Pending(migrations) {
+		t.Fatal("fresh state must not need migration")
+	}
+}
+
+func TestV3_Migrate_FromV0Fixture(t *testing.T) {
+	sdk.ShimReset()
+	if err := sdk.ShimLoadStateFile("testdata/state_v0.json"); err != nil {
+		t.Fatal(err)
+	}
+	sdk.ShimSetContractId("contract:v3")
+	sdk.ShimSetSender(sdk.Address("hive:alice"))
+
+	// entrypoints refuse to run against the old layout
+	expectPanic(t, func() { _ = Collect(sptr("2147483648,8589934592")) })
+	// only owner or consensus may migrate
+	expectPanic(t, func() { _ = Migrate(nil) })
+
+	sdk.ShimSetSender(sdk.Address("system:consensus"))
+	Migrate(nil)
+	if migrate.Version() != 1 {
+		t.Fatalf("schema version = %d, want 1", migrate.Version())
+	}
+	if getStr("paused") != "" || getStr("fee_acc0") != "" {
+		t.Fatal("legacy keys not removed")
+	}
+	if getStr("pause/all") != "1" {
+		t.Fatal("legacy pause flag not carried over")
+	}
+	// migrating twice is a no-op
+	Migrate(nil)
+
+	// still paused until consensus lifts it
+	sdk.ShimSetSender(sdk.Address("hive:alice"))
+	expectPanic(t, func() { _ = Collect(sptr("2147483648,8589934592")) })
+	sdk.ShimSetSender(sdk.Address("system:consensus"))
+	SetPaused(sptr("0"))
+
+	// position data survived and accrued fees are collectable
+	sdk.ShimSetSender(sdk.Address("hive:alice"))
+	sdk.ShimSetBalance(sdk.Address("contract:v3"), sdk.AssetHbd, 1000)
+	Collect(sptr("2147483648,8589934592"))
+	if sdk.ShimGetBalance(sdk.Address("hive:alice"), sdk.AssetHbd) != 30 {
+		t.Fatalf("collected %d, want 30", sdk.ShimGetBalance(sdk.Address("hive:alice"), sdk.AssetHbd))
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"contract-template/sdk/migrate"
+	"contract-template/sdk/pause"
+)
+
+// Schema history. Version 0 is the layout in main.old_txt.
+var migrations = []migrate.Step{
+	{Name: "adopt sdk pause flags, drop fee_acc", Up: migrateV0toV1}, // v0 -> v1
+}
+
+// v0 kept its own "paused" flag and duplicated fee accounting in fee_acc0/1,
+// which fee growth already tracks.
+func migrateV0toV1() {
+	if getStr(keyLegacyPaused) == "1" {
+		pause.SetFlag(pause.All, true)
+	}
+	delKey(keyLegacyPaused)
+	delKey(keyLegacyFeeAcc0)
+	delKey(keyLegacyFeeAcc1)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"contract-template/sdk/scenario"
+	"testing"
+)
+
+func TestScenario(t *testing.T) {
+	scenario.Run(t, "testdata/scenario.json", scenario.Entrypoints{
+		"init":             Init,
+		"migrate":          Migrate,
+		"mint":             Mint,
+		"burn":             Burn,
+		"collect":          Collect,
+		"set_fee":          SetFee,
+		"set_active_range": SetActiveRange,
+		"set_paused":       SetPaused,
+		"pause":            Pause,
+		"set_guardian":     SetGuardian,
+		"swap":             Swap,
+		"format_number":    FormatNumber,
+	})
+}
@@ -2,23 +2,11 @@ package main
 
 import (
 	"contract-template/sdk"
-	"math/big"
+	"math/big" //contractlint:allow import -- Q64.64 sqrt price math needs 256-bit intermediates
 	"strconv"
 	"strings"
 )
 
-const (
-	KeyAsset0      = "asset0"
-	KeyAsset1      = "asset1"
-	KeyFeeBps      = "fee_bps"
-	KeySqrtP       = "sqrt_price_q32"
-	KeyLiquidity   = "liquidity"
-	KeyActiveLower = "active_lower_q32"
-	KeyActiveUpper = "active_upper_q32"
-	KeyFeeGrowth0  = "fee_growth0_q32"
-	KeyFeeGrowth1  = "fee_growth1_q32"
-)
-
 const qShift = 32
 
 func qMul(a, b uint64) uint64 {
@@ -54,15 +42,16 @@ func minU64(a, b uint64) uint64 {
 	return b
 }
 
-func getStr(key string) string {
-	v := sdk.StateGetObject(key)
+func getStr(key stateKey) string {
+	v := sdk.StateGetObject(string(key))
 	if v == nil {
 		return ""
 	}
 	return *v
 }
-func setStr(key, v string) { sdk.StateSetObject(key, v) }
-func getU(key string) uint64 {
+func setStr(key stateKey, v string) { sdk.StateSetObject(string(key), v) }
+func delKey(key stateKey)           { sdk.StateDeleteObject(string(key)) }
+func getU(key stateKey) uint64 {
 	s := getStr(key)
 	if s == "" {
 		return 0
@@ -73,10 +62,7 @@ func getU(key string) uint64 {
 	}
 	return n
 }
-func setU(key string, v uint64) { sdk.StateSetObject(key, strconv.FormatUint(v, 10)) }
-func posKey(addr sdk.Address, lower, upper uint64, suffix string) string {
-	return "pos/" + addr.String() + "/" + strconv.FormatUint(lower, 10) + "/" + strconv.FormatUint(upper, 10) + "/" + suffix
-}
+func setU(key stateKey, v uint64) { sdk.StateSetObject(string(key), strconv.FormatUint(v, 10)) }
 
 func getAssets() (sdk.Asset, sdk.Asset) {
 	return sdk.Asset(getStr(KeyAsset0)), sdk.Asset(getStr(KeyAsset1))
@@ -177,14 +163,6 @@ func amountOwedFromLiquidity(liq, sqrtA, sqrtB, sqrtP uint64) (amt0, amt1 uint64
 	return
 }
 
-// Governance utilities
-func isSystemSender() bool {
-	env := sdk.GetEnv()
-	return env.Sender.Address.Domain() == sdk.AddressDomainSystem
-}
-
-// pause feature removed
-
 // Number formatting utilities (supports up to 256-bit unsigned)
 type numFormat struct {
 	base         int
@@ -1,3 +1,15 @@
 module contract-template
 
 go 1.23.2
+
+require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.0
+	golang.org/x/crypto v0.38.0
+	golang.org/x/tools v0.33.0
+)
+
+require (
+	golang.org/x/mod v0.24.0 // indirect
+	golang.org/x/sync v0.14.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+)
@@ -0,0 +1,203 @@
+// Package access provides ownership and role based authorization backed by
+// contract state.
+//
+// State layout:
+//
+//	access/owner                  current owner address
+//	access/pending_owner          proposed owner awaiting acceptance
+//	access/roles/<role>/<address> "1" while the role is held
+//
+// Checks are made against the immediate caller (sdk.Env.Caller), so a contract
+// calling into another cannot borrow the authority of the transaction signer.
+// The "system" role is a pseudo-role held implicitly by consensus; it can
+// never be granted or revoked.
+package access
+
+import "contract-template/sdk"
+
+const (
+	keyOwner        = "access/owner"
+	keyPendingOwner = "access/pending_owner"
+	keyRolePrefix   = "access/roles/" // access/roles/<role>/<address>
+)
+
+// RoleSystem is held by system-domain addresses and by transactions whose
+// first required auth is a system address.
+const RoleSystem = "system"
+
+func getStr(key string) string {
+	v := sdk.StateGetObject(key)
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func roleKey(role string, addr sdk.Address) string {
+	return keyRolePrefix + role + "/" + addr.String()
+}
+
+func caller() sdk.Address {
+	return sdk.GetEnv().Caller.Address
+}
+
+// Owner returns the current owner, or "" before InitOwner.
+func Owner() sdk.Address {
+	return sdk.Address(getStr(keyOwner))
+}
+
+// PendingOwner returns the address proposed by TransferOwnership, if any.
+func PendingOwner() sdk.Address {
+	return sdk.Address(getStr(keyPendingOwner))
+}
+
+func IsOwner(addr sdk.Address) bool {
+	owner := Owner()
+	return owner != "" && owner == addr
+}
+
+// InitOwner sets the first owner. It aborts if an owner is already set.
+func InitOwner(owner sdk.Address) {
+	if Owner() != "" {
+		sdk.Abort("access: owner already set")
+	}
+	if owner == "" {
+		sdk.Abort("access: empty owner")
+	}
+	sdk.StateSetObject(keyOwner, owner.String())
+}
+
+// RequireOwner aborts unless the caller is the owner.
+func RequireOwner() {
+	if !IsOwner(caller()) {
+		sdk.Abort("access: caller is not the owner")
+	}
+}
+
+// TransferOwnership proposes newOwner. Ownership only moves once the proposed
+// address calls AcceptOwnership, so a typo cannot brick the contract.
+// Proposing "" cancels a pending transfer.
+func TransferOwnership(newOwner sdk.Address) {
+	RequireOwner()
+	if newOwner == "" {
+		sdk.StateDeleteObject(keyPendingOwner)
+		return
+	}
+	sdk.StateSetObject(keyPendingOwner, newOwner.String())
+}
+
+// AcceptOwnership completes a transfer started by TransferOwnership.
+func AcceptOwnership() {
+	pending := PendingOwner()
+	if pending == "" || pending != caller() {
+		sdk.Abort("access: caller is not the pending owner")
+	}
+	sdk.StateSetObject(keyOwner, pending.String())
+	sdk.StateDeleteObject(keyPendingOwner)
+}
+
+// RenounceOwnership permanently removes the owner. Owner-only operations are
+// unreachable afterwards.
+func RenounceOwnership() {
+	RequireOwner()
+	sdk.StateDeleteObject(keyOwner)
+	sdk.StateDeleteObject(keyPendingOwner)
+}
+
+// IsSystem reports whether the current call carries consensus authority.
+// Only the transaction itself does: a contract it calls into does not.
+func IsSystem() bool {
+	env := sdk.GetEnv()
+	if env.Caller.Address != env.Sender.Address {
+		return false
+	}
+	if env.Sender.Address.Domain() == sdk.AddressDomainSystem {
+		return true
+	}
+	if len(env.Sender.RequiredAuths) > 0 && env.Sender.RequiredAuths[0].Domain() == sdk.AddressDomainSystem {
+		return true
+	}
+	return false
+}
+
+// HasRole reports whether addr holds role. For RoleSystem only the address
+// domain is checked; use RequireSystem to check the current call.
+func HasRole(role string, addr sdk.Address) bool {
+	if role == RoleSystem {
+		return addr.Domain() == sdk.AddressDomainSystem
+	}
+	return getStr(roleKey(role, addr)) == "1"
+}
+
+func checkRoleName(role string) {
+	if role == "" {
+		sdk.Abort("access: empty role")
+	}
+	if role == RoleSystem {
+		sdk.Abort("access: system role is not assignable")
+	}
+}
+
+// SetRole grants or revokes role without any authorization check. It exists
+// for modules that define their own admin rules; callers must authorize first.
+func SetRole(role string, addr sdk.Address, granted bool) {
+	checkRoleName(role)
+	if granted {
+		sdk.StateSetObject(roleKey(role, addr), "1")
+	} else {
+		sdk.StateDeleteObject(roleKey(role, addr))
+	}
+}
+
+// GrantRole gives role to addr. Owner-only.
+func GrantRole(role string, addr sdk.Address) {
+	RequireOwner()
+	SetRole(role, addr, true)
+}
+
+// RevokeRole removes role from addr. Owner-only.
+func RevokeRole(role string, addr sdk.Address) {
+	RequireOwner()
+	SetRole(role, addr, false)
+}
+
+// RenounceRole removes role from the caller.
+func RenounceRole(role string) {
+	checkRoleName(role)
+	c := caller()
+	if !HasRole(role, c) {
+		sdk.Abort("access: caller does not hold role " + role)
+	}
+	sdk.StateDeleteObject(roleKey(role, c))
+}
+
+// RequireRole aborts unless the caller holds role. RoleSystem is checked
+// against the call's consensus authority.
+func RequireRole(role string) {
+	if role == RoleSystem {
+		RequireSystem()
+		return
+	}
+	if !HasRole(role, caller()) {
+		sdk.Abort("access: caller is missing role " + role)
+	}
+}
+
+// RequireSystem aborts unless the call carries consensus authority.
+func RequireSystem() {
+	if !IsSystem() {
+		sdk.Abort("access: system only")
+	}
+}
+
+// RequireActiveAuth aborts unless the transaction sender signed with active
+// authority. Hive accounts must use their active key to move funds.
+func RequireActiveAuth() {
+	env := sdk.GetEnv()
+	for _, a := range env.Sender.RequiredAuths {
+		if a == env.Sender.Address {
+			return
+		}
+	}
+	sdk.Abort("access: active auth required")
+}
@@ -0,0 +1,110 @@
+package access
+
+import (
+	"contract-template/sdk"
+	"testing"
+)
+
+func expectAbort(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected abort %q", want)
+		}
+		if msg, _ := r.(string); msg != want {
+			t.Fatalf("abort = %q, want %q", msg, want)
+		}
+	}()
+	f()
+}
+
+func TestAccess_OwnershipTwoStep(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	InitOwner("hive:owner")
+	expectAbort(t, "access: owner already set", func() { InitOwner("hive:other") })
+
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "access: caller is not the owner", func() { TransferOwnership("hive:mallory") })
+
+	sdk.ShimSetSender("hive:owner")
+	TransferOwnership("hive:bob")
+	if Owner() != "hive:owner" || PendingOwner() != "hive:bob" {
+		t.Fatal("ownership must not move before acceptance")
+	}
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "access: caller is not the pending owner", AcceptOwnership)
+
+	sdk.ShimSetSender("hive:bob")
+	AcceptOwnership()
+	if Owner() != "hive:bob" || PendingOwner() != "" {
+		t.Fatal("ownership not transferred")
+	}
+	RenounceOwnership()
+	if Owner() != "" {
+		t.Fatal("owner not cleared")
+	}
+	expectAbort(t, "access: caller is not the owner", RequireOwner)
+}
+
+func TestAccess_CallerNotSender(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	InitOwner("hive:owner")
+	// a contract invoked by the owner's transaction does not inherit ownership
+	sdk.ShimSetCaller("contract:proxy")
+	expectAbort(t, "access: caller is not the owner", RequireOwner)
+}
+
+func TestAccess_Roles(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	InitOwner("hive:owner")
+	GrantRole("minter", "hive:alice")
+	expectAbort(t, "access: system role is not assignable", func() { GrantRole(RoleSystem, "hive:alice") })
+
+	sdk.ShimSetSender("hive:alice")
+	RequireRole("minter")
+	expectAbort(t, "access: caller is missing role pauser", func() { RequireRole("pauser") })
+	expectAbort(t, "access: caller is not the owner", func() { GrantRole("minter", "hive:bob") })
+	RenounceRole("minter")
+	if HasRole("minter", "hive:alice") {
+		t.Fatal("role not renounced")
+	}
+
+	sdk.ShimSetSender("hive:owner")
+	GrantRole("minter", "hive:bob")
+	RevokeRole("minter", "hive:bob")
+	if HasRole("minter", "hive:bob") {
+		t.Fatal("role not revoked")
+	}
+}
+
+func TestAccess_SystemAndActiveAuth(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:alice")
+	expectAbort(t, "access: system only", RequireSystem)
+	expectAbort(t, "access: system only", func() { RequireRole(RoleSystem) })
+	expectAbort(t, "access: active auth required", RequireActiveAuth)
+
+	sdk.ShimSetAuths([]sdk.Address{"hive:alice"}, nil)
+	RequireActiveAuth()
+	sdk.ShimSetAuths(nil, []sdk.Address{"hive:alice"})
+	expectAbort(t, "access: active auth required", RequireActiveAuth)
+
+	sdk.ShimSetSender("system:consensus")
+	RequireSystem()
+	// a contract the consensus transaction calls cannot pass its authority on
+	sdk.ShimSetContractId("contract:proxy")
+	sdk.ShimRegisterContract("contract:pool", map[string]func(*string) *string{
+		"claim_fees": func(*string) *string { RequireSystem(); return nil },
+	})
+	expectAbort(t, "access: system only", func() { sdk.ContractCall("contract:pool", "claim_fees", "", nil) })
+
+	sdk.ShimSetSender("hive:alice")
+	sdk.ShimSetAuths([]sdk.Address{"system:consensus"}, nil)
+	RequireRole(RoleSystem)
+	sdk.ShimSetCaller("contract:proxy")
+	expectAbort(t, "access: system only", RequireSystem)
+}
@@ -1,12 +1,20 @@
 package sdk
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+)
 
 type Caller struct {
 	Address Address `json:"-"`
 }
 
-type Intent struct{}
+// Intent grants permission to act for the signer, e.g. a transfer.allow
+// with "limit" and "token" args.
+type Intent struct {
+	Type string            `json:"type"`
+	Args map[string]string `json:"args"`
+}
 
 type Sender struct {
 	Address              Address   `json:"id"`
@@ -26,12 +34,13 @@ const (
 type AddressType string
 
 const (
-	AddressTypeEVM     AddressType = "evm"
-	AddressTypeKey     AddressType = "key"
-	AddressTypeHive    AddressType = "hive"
-	AddressTypeSystem  AddressType = "system"
-	AddressTypeBLS     AddressType = "bls"
-	AddressTypeUnknown AddressType = "unknown"
+	AddressTypeEVM      AddressType = "evm"
+	AddressTypeKey      AddressType = "key"
+	AddressTypeHive     AddressType = "hive"
+	AddressTypeSystem   AddressType = "system"
+	AddressTypeBLS      AddressType = "bls"
+	AddressTypeContract AddressType = "contract"
+	AddressTypeUnknown  AddressType = "unknown"
 )
 
 type Address string
@@ -54,20 +63,255 @@ func (a Address) Type() AddressType {
 	if strings.HasPrefix(a.String(), "did:pkh:eip155") {
 		return AddressTypeEVM
 	} else if strings.HasPrefix(a.String(), "did:key:") {
+		if codec, _, ok := didKeyCodec(a.String()); ok && (codec == codecBLS12381G1 || codec == codecBLS12381G2) {
+			return AddressTypeBLS
+		}
 		return AddressTypeKey
 	} else if strings.HasPrefix(a.String(), "hive:") {
 		return AddressTypeHive
 	} else if strings.HasPrefix(a.String(), "system:") {
 		return AddressTypeSystem
+	} else if strings.HasPrefix(a.String(), "contract:") {
+		return AddressTypeContract
 	} else {
 		return AddressTypeUnknown
 	}
-	//TODO: Detect BLS address type, though it is not used or planned to be supported.
 }
 
+// IsValid reports whether ParseAddress accepts a.
 func (a Address) IsValid() bool {
-	if a.Type() == AddressTypeUnknown {
+	_, err := ParseAddress(a.String())
+	return err == nil
+}
+
+// AddressError describes why ParseAddress rejected an address.
+type AddressError struct {
+	Address string
+	Reason  string
+}
+
+func (e *AddressError) Error() string {
+	return "invalid address " + strconv.Quote(e.Address) + ": " + e.Reason
+}
+
+// ParseAddress validates s and returns it as an Address. Accepted forms:
+//
+//	hive:<account>                     Hive account naming rules
+//	did:pkh:eip155:<chain id>:0x<hex>  decimal chain id, 20 byte address, EIP-55 checksum if mixed case
+//	did:key:z<base58btc>               ed25519, secp256k1 or BLS12-381 public key multicodec
+//	contract:<id>                      1-64 letters, digits, '-' or '_'
+//	system:<name>                      lower case letters, digits, '-', '_' or '.'
+func ParseAddress(s string) (Address, error) {
+	fail := func(reason string) (Address, error) {
+		return "", &AddressError{Address: s, Reason: reason}
+	}
+	switch {
+	case strings.HasPrefix(s, "hive:"):
+		if !isHiveAccount(s[len("hive:"):]) {
+			return fail("not a valid hive account name")
+		}
+	case strings.HasPrefix(s, "did:pkh:eip155:"):
+		chain, addr, ok := strings.Cut(s[len("did:pkh:eip155:"):], ":")
+		if !ok || !isChainId(chain) {
+			return fail("eip155 chain id must be a decimal number")
+		}
+		if reason := checkEVMAddress(addr); reason != "" {
+			return fail(reason)
+		}
+	case strings.HasPrefix(s, "did:key:"):
+		codec, key, ok := didKeyCodec(s)
+		if !ok {
+			return fail("did:key must be base58btc multibase ('z') with a multicodec prefix")
+		}
+		if size, known := didKeySizes[codec]; !known || len(key) != size {
+			return fail("unsupported or truncated did:key public key")
+		}
+	case strings.HasPrefix(s, "contract:"):
+		id := s[len("contract:"):]
+		if len(id) == 0 || len(id) > 64 || !allChars(id, "-_") {
+			return fail("contract id must be 1-64 letters, digits, '-' or '_'")
+		}
+	case strings.HasPrefix(s, "system:"):
+		name := s[len("system:"):]
+		if len(name) == 0 || strings.ToLower(name) != name || !allChars(name, "-_.") {
+			return fail("system name must be lower case letters, digits, '-', '_' or '.'")
+		}
+	default:
+		return fail("unknown address type")
+	}
+	return Address(s), nil
+}
+
+// isHiveAccount applies Hive's account name rules: 3-16 characters, dot
+// separated segments of at least 3 characters that start with a letter, end
+// with a letter or digit and contain only a-z, 0-9 and single dashes.
+func isHiveAccount(name string) bool {
+	if len(name) < 3 || len(name) > 16 {
+		return false
+	}
+	for _, seg := range strings.Split(name, ".") {
+		if len(seg) < 3 {
+			return false
+		}
+		if seg[0] < 'a' || seg[0] > 'z' {
+			return false
+		}
+		if last := seg[len(seg)-1]; !isLowerAlnum(last) {
+			return false
+		}
+		for i := 1; i < len(seg)-1; i++ {
+			c := seg[i]
+			if c == '-' {
+				if seg[i-1] == '-' {
+					return false
+				}
+			} else if !isLowerAlnum(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isLowerAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
+
+func allChars(s, extra string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.IndexByte(extra, c) >= 0 {
+			continue
+		}
 		return false
 	}
 	return true
 }
+
+// isChainId accepts a CAIP-2 eip155 reference: 1-32 decimal digits without
+// leading zeros.
+func isChainId(s string) bool {
+	if len(s) == 0 || len(s) > 32 || (s[0] == '0' && len(s) > 1) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkEVMAddress validates 0x plus 40 hex digits. All lower or all upper
+// case digits carry no checksum; mixed case must match EIP-55.
+func checkEVMAddress(addr string) string {
+	if len(addr) != 42 || addr[0] != '0' || addr[1] != 'x' {
+		return "evm address must be 0x followed by 40 hex digits"
+	}
+	hex := addr[2:]
+	lower, upper := false, false
+	for i := 0; i < len(hex); i++ {
+		c := hex[i]
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'f':
+			lower = true
+		case c >= 'A' && c <= 'F':
+			upper = true
+		default:
+			return "evm address must be 0x followed by 40 hex digits"
+		}
+	}
+	if lower && upper && hex != eip55(hex) {
+		return "evm address fails the EIP-55 checksum"
+	}
+	return ""
+}
+
+// eip55 returns the checksummed form of 40 hex digits.
+func eip55(hex string) string {
+	lower := []byte(strings.ToLower(hex))
+	hash := keccak256(lower)
+	out := make([]byte, len(lower))
+	for i, c := range lower {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			c -= 'a' - 'A'
+		}
+		out[i] = c
+	}
+	return string(out)
+}
+
+// multicodec public key codes used by did:key
+const (
+	codecSecp256k1  = 0xe7
+	codecBLS12381G1 = 0xea
+	codecBLS12381G2 = 0xeb
+	codecEd25519    = 0xed
+)
+
+var didKeySizes = map[uint64]int{
+	codecSecp256k1:  33,
+	codecBLS12381G1: 48,
+	codecBLS12381G2: 96,
+	codecEd25519:    32,
+}
+
+// didKeyCodec decodes a did:key and splits its multicodec prefix from the key.
+func didKeyCodec(s string) (codec uint64, key []byte, ok bool) {
+	mb, found := strings.CutPrefix(s, "did:key:z")
+	if !found {
+		return 0, nil, false
+	}
+	raw, ok := base58Decode(mb)
+	if !ok {
+		return 0, nil, false
+	}
+	for i := 0; i < len(raw) && i < 9; i++ {
+		codec |= uint64(raw[i]&0x7f) << (7 * i)
+		if raw[i]&0x80 == 0 {
+			return codec, raw[i+1:], true
+		}
+	}
+	return 0, nil, false
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Decode decodes the Bitcoin alphabet without math/big, which is too
+// heavy for contracts.
+func base58Decode(s string) ([]byte, bool) {
+	if len(s) == 0 {
+		return nil, false
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	// little endian base 256 accumulator
+	var acc []byte
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, false
+		}
+		for j := range acc {
+			carry += int(acc[j]) * 58
+			acc[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			acc = append(acc, byte(carry))
+			carry >>= 8
+		}
+	}
+	out := make([]byte, zeros+len(acc))
+	for i, b := range acc {
+		out[len(out)-1-i] = b
+	}
+	return out, true
+}
@@ -0,0 +1,116 @@
+package sdk
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// base58Encode is the reference encoder, used to build did:key fixtures.
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	var out []byte
+	mod := new(big.Int)
+	for x.Sign() > 0 {
+		x.DivMod(x, big.NewInt(58), mod)
+		out = append([]byte{base58Alphabet[mod.Int64()]}, out...)
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append([]byte{'1'}, out...)
+	}
+	return string(out)
+}
+
+func didKey(codec byte, size int) string {
+	key := make([]byte, size)
+	for i := range key {
+		key[i] = byte(i + 1)
+	}
+	return "did:key:z" + base58Encode(append([]byte{codec, 0x01}, key...))
+}
+
+func TestKeccak256(t *testing.T) {
+	got := keccak256(nil)
+	if hex.EncodeToString(got[:]) != "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470" {
+		t.Fatalf("keccak256(\"\") = %x", got)
+	}
+	got = keccak256([]byte("abc"))
+	if hex.EncodeToString(got[:]) != "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45" {
+		t.Fatalf("keccak256(\"abc\") = %x", got)
+	}
+	// lengths around the 136 byte rate exercise padding and multi-block input
+	for _, n := range []int{135, 136, 137, 300} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		ref := sha3.NewLegacyKeccak256()
+		ref.Write(data)
+		if got := keccak256(data); hex.EncodeToString(got[:]) != hex.EncodeToString(ref.Sum(nil)) {
+			t.Fatalf("keccak256 of %d bytes = %x", n, got)
+		}
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	valid := []struct {
+		addr string
+		typ  AddressType
+	}{
+		{"hive:alice", AddressTypeHive},
+		{"hive:vsc.gateway", AddressTypeHive},
+		{"hive:hive-io", AddressTypeHive},
+		{"hive:abc123", AddressTypeHive},
+		{"did:pkh:eip155:1:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", AddressTypeEVM},
+		{"did:pkh:eip155:137:0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359", AddressTypeEVM},
+		{"did:pkh:eip155:1:0xFB6916095CA1DF60BB79CE92CE3EA74C37C5D359", AddressTypeEVM},
+		{"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK", AddressTypeKey},
+		{didKey(0xe7, 33), AddressTypeKey},
+		{didKey(0xea, 48), AddressTypeBLS},
+		{didKey(0xeb, 96), AddressTypeBLS},
+		{"contract:v2", AddressTypeContract},
+		{"contract:vsc1BdrQ6EtbQ64rq2PkPd21x4MaLnVRcJj85d", AddressTypeContract},
+		{"system:consensus", AddressTypeSystem},
+	}
+	for _, c := range valid {
+		a, err := ParseAddress(c.addr)
+		if err != nil {
+			t.Errorf("%s: %v", c.addr, err)
+			continue
+		}
+		if a.Type() != c.typ || !a.IsValid() {
+			t.Errorf("%s: type %s, want %s", c.addr, a.Type(), c.typ)
+		}
+	}
+
+	invalid := []string{
+		"", "alice", "hive:", "hive:ab", "hive:Alice", "hive:1abc", "hive:abc-", "hive:ab--cd",
+		"hive:abc..def", "hive:ab.cdef", "hive:averyverylongname", "hive:al ice",
+		"did:pkh:eip155:", "did:pkh:eip155:1", "did:pkh:eip155:01:0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"did:pkh:eip155:x:0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"did:pkh:eip155:1:0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea",
+		"did:pkh:eip155:1:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", // bad checksum
+		"did:pkh:eip155:1:5aaeb6053f3e94c9b9a09f33669435e7ef1beaed00",
+		"did:key:", "did:key:z", "did:key:6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK",
+		"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2do0", // '0' is not base58
+		"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2d",   // truncated key
+		didKey(0x12, 32), // unknown codec
+		"contract:", "contract:a,b", "contract:" + string(make([]byte, 65)),
+		"system:", "system:Consensus",
+	}
+	for _, s := range invalid {
+		if a, err := ParseAddress(s); err == nil {
+			t.Errorf("%q accepted as %s", s, a)
+		} else if _, ok := err.(*AddressError); !ok {
+			t.Errorf("%q: error %T, want *AddressError", s, err)
+		}
+		if Address(s).IsValid() {
+			t.Errorf("%q: IsValid", s)
+		}
+	}
+}
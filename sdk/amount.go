@@ -0,0 +1,207 @@
+package sdk
+
+import (
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+type assetMeta struct {
+	symbol    string
+	precision int
+}
+
+var assetInfo = map[Asset]assetMeta{
+	AssetHive:       {"HIVE", 3},
+	AssetHiveCons:   {"HIVE_CONSENSUS", 3},
+	AssetHbd:        {"HBD", 3},
+	AssetHbdSavings: {"HBD_SAVINGS", 3},
+}
+
+// Known reports whether the asset has precision metadata.
+func (a Asset) Known() bool {
+	_, ok := assetInfo[a]
+	return ok
+}
+
+// Precision is the number of decimals of one unit, e.g. 3 for hbd where
+// 1.000 HBD is 1000 units. Unknown assets have precision 0.
+func (a Asset) Precision() int {
+	return assetInfo[a].precision
+}
+
+// Symbol is the upper case display name, e.g. "HBD".
+func (a Asset) Symbol() string {
+	if m, ok := assetInfo[a]; ok {
+		return m.symbol
+	}
+	return strings.ToUpper(a.String())
+}
+
+// Amount is a quantity of an asset in its smallest unit.
+type Amount struct {
+	Asset Asset
+	Units int64
+}
+
+func NewAmount(asset Asset, units int64) Amount {
+	return Amount{Asset: asset, Units: units}
+}
+
+// ParseAmount parses "1.234 HBD". The symbol is case insensitive and may
+// also be the asset id ("1.234 hbd"); at most Precision decimals are allowed.
+func ParseAmount(s string) (Amount, error) {
+	num, sym, ok := strings.Cut(strings.TrimSpace(s), " ")
+	if !ok {
+		return Amount{}, errors.New("amount: want \"<number> <SYMBOL>\"")
+	}
+	asset, ok := assetBySymbol(strings.TrimSpace(sym))
+	if !ok {
+		return Amount{}, errors.New("amount: unknown asset " + strconv.Quote(sym))
+	}
+	units, err := parseUnits(num, asset.Precision())
+	if err != nil {
+		return Amount{}, err
+	}
+	return Amount{Asset: asset, Units: units}, nil
+}
+
+func assetBySymbol(sym string) (Asset, bool) {
+	for a, m := range assetInfo {
+		if strings.EqualFold(sym, m.symbol) {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+func parseUnits(num string, precision int) (int64, error) {
+	whole, frac, hasDot := strings.Cut(num, ".")
+	if whole == "" || (hasDot && frac == "") || len(frac) > precision {
+		return 0, errors.New("amount: bad number " + strconv.Quote(num))
+	}
+	digits := whole + frac + strings.Repeat("0", precision-len(frac))
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, errors.New("amount: bad number " + strconv.Quote(num))
+		}
+	}
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, errors.New("amount: " + strconv.Quote(num) + " out of range")
+	}
+	return v, nil
+}
+
+// String formats the amount with its precision and symbol, e.g. "1.234 HBD".
+func (a Amount) String() string {
+	p := a.Asset.Precision()
+	neg := a.Units < 0
+	u := uint64(a.Units)
+	if neg {
+		u = -u
+	}
+	s := strconv.FormatUint(u, 10)
+	if p > 0 {
+		if len(s) <= p {
+			s = strings.Repeat("0", p-len(s)+1) + s
+		}
+		s = s[:len(s)-p] + "." + s[len(s)-p:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s + " " + a.Asset.Symbol()
+}
+
+func (a Amount) IsZero() bool { return a.Units == 0 }
+
+func (a Amount) same(b Amount) {
+	if a.Asset != b.Asset {
+		Abort("amount: cannot mix " + a.Asset.String() + " and " + b.Asset.String())
+	}
+}
+
+// Add returns a+b. It aborts on mixed assets or overflow.
+func (a Amount) Add(b Amount) Amount {
+	a.same(b)
+	sum := a.Units + b.Units
+	if (b.Units > 0 && sum < a.Units) || (b.Units < 0 && sum > a.Units) {
+		Abort("amount: overflow")
+	}
+	return Amount{Asset: a.Asset, Units: sum}
+}
+
+// Sub returns a-b. It aborts on mixed assets, overflow or a negative result.
+func (a Amount) Sub(b Amount) Amount {
+	a.same(b)
+	if b.Units > a.Units {
+		Abort("amount: insufficient " + a.Asset.String())
+	}
+	if b.Units < 0 && a.Units-b.Units < a.Units {
+		Abort("amount: overflow")
+	}
+	return Amount{Asset: a.Asset, Units: a.Units - b.Units}
+}
+
+// Cmp compares a and b (-1, 0, 1). It aborts on mixed assets.
+func (a Amount) Cmp(b Amount) int {
+	a.same(b)
+	switch {
+	case a.Units < b.Units:
+		return -1
+	case a.Units > b.Units:
+		return 1
+	}
+	return 0
+}
+
+// MulDiv returns a*mul/div rounded down, with a 128 bit intermediate, e.g.
+// a.MulDiv(30, 10_000) for a 30 bps fee. a must not be negative.
+func (a Amount) MulDiv(mul, div uint64) Amount {
+	if a.Units < 0 {
+		Abort("amount: negative")
+	}
+	if div == 0 {
+		Abort("amount: division by zero")
+	}
+	hi, lo := bits.Mul64(uint64(a.Units), mul)
+	if hi >= div {
+		Abort("amount: overflow")
+	}
+	q, _ := bits.Div64(hi, lo, div)
+	if q > 1<<63-1 {
+		Abort("amount: overflow")
+	}
+	return Amount{Asset: a.Asset, Units: int64(q)}
+}
+
+func (a Amount) positive() {
+	if a.Units <= 0 {
+		Abort("amount: must be positive")
+	}
+}
+
+// GetBalanceAmount is GetBalance returning an Amount.
+func GetBalanceAmount(address Address, asset Asset) Amount {
+	return Amount{Asset: asset, Units: GetBalance(address, asset)}
+}
+
+// HiveDrawAmount is HiveDraw for an Amount; it aborts unless a is positive.
+func HiveDrawAmount(a Amount) {
+	a.positive()
+	HiveDraw(a.Units, a.Asset)
+}
+
+// HiveTransferAmount is HiveTransfer for an Amount; it aborts unless a is positive.
+func HiveTransferAmount(to Address, a Amount) {
+	a.positive()
+	HiveTransfer(to, a.Units, a.Asset)
+}
+
+// HiveWithdrawAmount is HiveWithdraw for an Amount; it aborts unless a is positive.
+func HiveWithdrawAmount(to Address, a Amount) {
+	a.positive()
+	HiveWithdraw(to, a.Units, a.Asset)
+}
@@ -0,0 +1,110 @@
+package sdk
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func mustAbort(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected abort %q", want)
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, want) {
+			t.Fatalf("abort = %v, want %q", r, want)
+		}
+	}()
+	f()
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Amount
+	}{
+		{"1.234 HBD", Amount{AssetHbd, 1234}},
+		{"1 HIVE", Amount{AssetHive, 1000}},
+		{"0.5 hive", Amount{AssetHive, 500}},
+		{" 10.01 HBD_SAVINGS ", Amount{AssetHbdSavings, 10010}},
+		{"0.001 hive_consensus", Amount{AssetHiveCons, 1}},
+		{"9223372036854775.807 HBD", Amount{AssetHbd, math.MaxInt64}},
+	}
+	for _, c := range cases {
+		got, err := ParseAmount(c.in)
+		if err != nil || got != c.want {
+			t.Errorf("ParseAmount(%q) = %v, %v; want %v", c.in, got, err, c.want)
+		}
+	}
+	for _, in := range []string{"", "1.234", "1.2345 HBD", "1. HBD", ".5 HBD", "-1 HBD", "1,000 HBD", "1 BTC", "9223372036854775.808 HBD"} {
+		if a, err := ParseAmount(in); err == nil {
+			t.Errorf("ParseAmount(%q) = %v, want error", in, a)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	cases := map[Amount]string{
+		{AssetHbd, 1234}:          "1.234 HBD",
+		{AssetHive, 5}:            "0.005 HIVE",
+		{AssetHive, 0}:            "0.000 HIVE",
+		{AssetHbdSavings, 1000}:   "1.000 HBD_SAVINGS",
+		{AssetHbd, -1500}:         "-1.500 HBD",
+		{AssetHbd, math.MinInt64}: "-9223372036854775.808 HBD",
+	}
+	for a, want := range cases {
+		if got := a.String(); got != want {
+			t.Errorf("%#v.String() = %q, want %q", a, got, want)
+		}
+		if a.Units >= 0 {
+			if back, err := ParseAmount(want); err != nil || back != a {
+				t.Errorf("ParseAmount(%q) = %v, %v", want, back, err)
+			}
+		}
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a := NewAmount(AssetHbd, 1500)
+	b := NewAmount(AssetHbd, 250)
+	if a.Add(b).Units != 1750 || a.Sub(b).Units != 1250 || a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(a) != 0 {
+		t.Fatal("basic arithmetic")
+	}
+	if got := a.MulDiv(30, 10_000); got != NewAmount(AssetHbd, 4) {
+		t.Fatalf("MulDiv = %v", got)
+	}
+	if got := NewAmount(AssetHive, math.MaxInt64).MulDiv(3, 4); got.Units != math.MaxInt64/4*3+2 {
+		t.Fatalf("MulDiv 128 bit = %v", got)
+	}
+
+	mustAbort(t, "cannot mix", func() { a.Add(NewAmount(AssetHive, 1)) })
+	mustAbort(t, "cannot mix", func() { a.Sub(NewAmount(AssetHbdSavings, 1)) })
+	mustAbort(t, "cannot mix", func() { a.Cmp(NewAmount(AssetHive, 1)) })
+	mustAbort(t, "overflow", func() { NewAmount(AssetHbd, math.MaxInt64).Add(NewAmount(AssetHbd, 1)) })
+	mustAbort(t, "insufficient hbd", func() { b.Sub(a) })
+	mustAbort(t, "overflow", func() { NewAmount(AssetHive, math.MaxInt64).MulDiv(2, 1) })
+	mustAbort(t, "division by zero", func() { a.MulDiv(1, 0) })
+}
+
+func TestAmountHostCalls(t *testing.T) {
+	ShimReset()
+	alice := Address("hive:alice")
+	bob := Address("hive:bob")
+	ShimSetSender(alice)
+	ShimSetBalance(alice, AssetHbd, 5000)
+
+	if got := GetBalanceAmount(alice, AssetHbd); got != NewAmount(AssetHbd, 5000) {
+		t.Fatalf("balance = %v", got)
+	}
+	amt, _ := ParseAmount("1.234 HBD")
+	HiveDrawAmount(amt)
+	HiveTransferAmount(bob, NewAmount(AssetHbd, 234))
+	if ShimGetBalance(alice, AssetHbd) != 3766 || ShimGetBalance(bob, AssetHbd) != 234 ||
+		GetBalanceAmount(Address("contract:test"), AssetHbd).String() != "1.000 HBD" {
+		t.Fatal("balances after draw and transfer")
+	}
+	mustAbort(t, "must be positive", func() { HiveDrawAmount(NewAmount(AssetHbd, 0)) })
+	mustAbort(t, "must be positive", func() { HiveTransferAmount(bob, NewAmount(AssetHbd, -1)) })
+}
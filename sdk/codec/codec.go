@@ -0,0 +1,229 @@
+// Package codec implements a compact, canonical binary record format for
+// contract state.
+//
+// A record is a sequence of fields written in a fixed order:
+//
+//   - unsigned integers are LEB128 uvarints
+//   - signed integers are zig-zag encoded uvarints
+//   - byte strings and strings are a uvarint length followed by the raw bytes
+//   - booleans are a single 0x00 or 0x01 byte
+//
+// Every encoding has exactly one valid byte form. The Reader rejects overlong
+// varints, out of range booleans and trailing bytes, so equal values always
+// produce equal state and two nodes can never disagree about a decode.
+//
+// Versioned records prefix the fields with a uvarint schema version so a struct
+// can grow new fields while old entries remain readable.
+package codec
+
+import (
+	"encoding/base64"
+	"errors"
+)
+
+var (
+	ErrShortBuffer  = errors.New("codec: short buffer")
+	ErrOverflow     = errors.New("codec: varint overflows 64 bits")
+	ErrNonCanonical = errors.New("codec: non-canonical encoding")
+	ErrTrailing     = errors.New("codec: trailing bytes after record")
+	ErrBadBase64    = errors.New("codec: invalid base64")
+)
+
+// Writer appends fields to a record. The zero value is ready to use.
+type Writer struct {
+	buf []byte
+}
+
+// Bytes returns the encoded record.
+func (w *Writer) Bytes() []byte { return w.buf }
+
+// Len returns the number of bytes written so far.
+func (w *Writer) Len() int { return len(w.buf) }
+
+// Reset clears the writer so it can be reused.
+func (w *Writer) Reset() { w.buf = w.buf[:0] }
+
+func (w *Writer) Uvarint(v uint64) {
+	for v >= 0x80 {
+		w.buf = append(w.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	w.buf = append(w.buf, byte(v))
+}
+
+func (w *Writer) Varint(v int64) {
+	w.Uvarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (w *Writer) Bool(v bool) {
+	if v {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *Writer) ByteSlice(b []byte) {
+	w.Uvarint(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *Writer) String(s string) {
+	w.Uvarint(uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// Reader decodes fields from a record. The first error is sticky: once a read
+// fails every following read returns the zero value and Err reports the cause.
+type Reader struct {
+	buf []byte
+	off int
+	err error
+}
+
+func NewReader(b []byte) *Reader { return &Reader{buf: b} }
+
+// Err returns the first decoding error, if any.
+func (r *Reader) Err() error { return r.err }
+
+// Remaining returns the number of unread bytes.
+func (r *Reader) Remaining() int { return len(r.buf) - r.off }
+
+// Finish reports the first decoding error, or ErrTrailing if the record was
+// not fully consumed.
+func (r *Reader) Finish() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.off != len(r.buf) {
+		return ErrTrailing
+	}
+	return nil
+}
+
+func (r *Reader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Reader) Uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	var v uint64
+	var shift uint
+	for i := 0; ; i++ {
+		if r.off >= len(r.buf) {
+			r.fail(ErrShortBuffer)
+			return 0
+		}
+		b := r.buf[r.off]
+		r.off++
+		if i == 9 && b > 1 {
+			r.fail(ErrOverflow)
+			return 0
+		}
+		v |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			// a zero final byte after a continuation is an overlong form
+			if i > 0 && b == 0 {
+				r.fail(ErrNonCanonical)
+				return 0
+			}
+			return v
+		}
+		shift += 7
+	}
+}
+
+func (r *Reader) Varint() int64 {
+	u := r.Uvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *Reader) Bool() bool {
+	if r.err != nil {
+		return false
+	}
+	if r.off >= len(r.buf) {
+		r.fail(ErrShortBuffer)
+		return false
+	}
+	b := r.buf[r.off]
+	r.off++
+	if b > 1 {
+		r.fail(ErrNonCanonical)
+		return false
+	}
+	return b == 1
+}
+
+// ByteSlice returns a length-prefixed byte string. The result aliases the
+// reader's buffer.
+func (r *Reader) ByteSlice() []byte {
+	n := r.Uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(r.Remaining()) {
+		r.fail(ErrShortBuffer)
+		return nil
+	}
+	b := r.buf[r.off : r.off+int(n)]
+	r.off += int(n)
+	return b
+}
+
+func (r *Reader) String() string {
+	return string(r.ByteSlice())
+}
+
+// Marshaler is implemented by state structs that encode themselves as a record.
+type Marshaler interface {
+	MarshalRecord(w *Writer)
+}
+
+// Unmarshaler is implemented by state structs that decode themselves from a
+// record. version is the schema version the record was written with, letting
+// the decoder fill defaults for fields that did not exist yet.
+type Unmarshaler interface {
+	UnmarshalRecord(r *Reader, version uint64) error
+}
+
+// Marshal encodes m as a versioned record.
+func Marshal(version uint64, m Marshaler) []byte {
+	w := &Writer{}
+	w.Uvarint(version)
+	m.MarshalRecord(w)
+	return w.Bytes()
+}
+
+// Unmarshal decodes a versioned record into u and returns the stored version.
+// The whole input must be consumed.
+func Unmarshal(b []byte, u Unmarshaler) (uint64, error) {
+	r := NewReader(b)
+	version := r.Uvarint()
+	if err := r.Err(); err != nil {
+		return 0, err
+	}
+	if err := u.UnmarshalRecord(r, version); err != nil {
+		return version, err
+	}
+	return version, r.Finish()
+}
+
+// EncodeString maps binary record bytes to a string that is safe to pass
+// through the string-only host ABI (unpadded standard base64).
+func EncodeString(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+// DecodeString reverses EncodeString.
+func DecodeString(s string) ([]byte, error) {
+	b, err := base64.RawStdEncoding.Strict().DecodeString(s)
+	if err != nil {
+		return nil, ErrBadBase64
+	}
+	return b, nil
+}
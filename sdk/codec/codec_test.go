@@ -0,0 +1,154 @@
+package codec
+
+import (
+	"bytes"
+	"contract-template/sdk"
+	"math"
+	"testing"
+)
+
+// position mirrors the v3 per-position keys collapsed into one record.
+// Version 1 had no owed amounts; version 2 added them.
+type position struct {
+	Liquidity uint64
+	FG0Last   uint64
+	FG1Last   uint64
+	Owed0     uint64
+	Owed1     uint64
+}
+
+func (p *position) MarshalRecord(w *Writer) {
+	w.Uvarint(p.Liquidity)
+	w.Uvarint(p.FG0Last)
+	w.Uvarint(p.FG1Last)
+	w.Uvarint(p.Owed0)
+	w.Uvarint(p.Owed1)
+}
+
+func (p *position) UnmarshalRecord(r *Reader, version uint64) error {
+	p.Liquidity = r.Uvarint()
+	p.FG0Last = r.Uvarint()
+	p.FG1Last = r.Uvarint()
+	if version >= 2 {
+		p.Owed0 = r.Uvarint()
+		p.Owed1 = r.Uvarint()
+	}
+	return r.Err()
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	w := &Writer{}
+	w.Uvarint(0)
+	w.Uvarint(math.MaxUint64)
+	w.Varint(-1)
+	w.Varint(math.MinInt64)
+	w.Varint(math.MaxInt64)
+	w.Bool(true)
+	w.Bool(false)
+	w.String("hive:alice")
+	w.ByteSlice(nil)
+
+	r := NewReader(w.Bytes())
+	if r.Uvarint() != 0 || r.Uvarint() != math.MaxUint64 {
+		t.Fatal("uvarint mismatch")
+	}
+	if r.Varint() != -1 || r.Varint() != math.MinInt64 || r.Varint() != math.MaxInt64 {
+		t.Fatal("varint mismatch")
+	}
+	if !r.Bool() || r.Bool() {
+		t.Fatal("bool mismatch")
+	}
+	if r.String() != "hive:alice" || len(r.ByteSlice()) != 0 {
+		t.Fatal("bytes mismatch")
+	}
+	if err := r.Finish(); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+}
+
+func TestCodec_RejectsNonCanonical(t *testing.T) {
+	cases := []struct {
+		name string
+		in   []byte
+		read func(r *Reader)
+		want error
+	}{
+		{"overlong zero", []byte{0x80, 0x00}, func(r *Reader) { r.Uvarint() }, ErrNonCanonical},
+		{"overlong one", []byte{0x81, 0x80, 0x00}, func(r *Reader) { r.Uvarint() }, ErrNonCanonical},
+		{"overflow", bytes.Repeat([]byte{0xff}, 10), func(r *Reader) { r.Uvarint() }, ErrOverflow},
+		{"truncated", []byte{0x80}, func(r *Reader) { r.Uvarint() }, ErrShortBuffer},
+		{"bool 2", []byte{0x02}, func(r *Reader) { r.Bool() }, ErrNonCanonical},
+		{"short bytes", []byte{0x05, 'a'}, func(r *Reader) { r.ByteSlice() }, ErrShortBuffer},
+		{"trailing", []byte{0x01, 0x00}, func(r *Reader) { r.Uvarint() }, ErrTrailing},
+	}
+	for _, c := range cases {
+		r := NewReader(c.in)
+		c.read(r)
+		if err := r.Finish(); err != c.want {
+			t.Fatalf("%s: err=%v want %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestCodec_VersionedRecord(t *testing.T) {
+	// a version 1 record written before owed amounts existed
+	w := &Writer{}
+	w.Uvarint(1)
+	w.Uvarint(500)
+	w.Uvarint(7)
+	w.Uvarint(9)
+	var old position
+	v, err := Unmarshal(w.Bytes(), &old)
+	if err != nil || v != 1 {
+		t.Fatalf("v1 decode: v=%d err=%v", v, err)
+	}
+	if old.Liquidity != 500 || old.FG1Last != 9 || old.Owed0 != 0 {
+		t.Fatalf("v1 fields: %+v", old)
+	}
+
+	cur := position{Liquidity: 1 << 40, FG0Last: 3, FG1Last: 4, Owed0: 5, Owed1: 6}
+	enc := Marshal(2, &cur)
+	var got position
+	if v, err := Unmarshal(enc, &got); err != nil || v != 2 || got != cur {
+		t.Fatalf("v2 round trip: v=%d err=%v got=%+v", v, err, got)
+	}
+	// decoding a v2 body as v1 leaves trailing bytes
+	enc[0] = 1
+	if _, err := Unmarshal(enc, &got); err != ErrTrailing {
+		t.Fatalf("expected trailing error, got %v", err)
+	}
+}
+
+func TestCodec_StoreLoad(t *testing.T) {
+	sdk.ShimReset()
+	var p position
+	if _, ok := Load("pos/hive:alice/1/2", &p); ok {
+		t.Fatal("unset key must not load")
+	}
+	want := position{Liquidity: 1000, Owed1: 12}
+	Store("pos/hive:alice/1/2", 2, &want)
+	v, ok := Load("pos/hive:alice/1/2", &p)
+	if !ok || v != 2 || p != want {
+		t.Fatalf("load: ok=%v v=%d p=%+v", ok, v, p)
+	}
+
+	sdk.StateSetObject("pos/bad", "!!")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("corrupt record must abort")
+		}
+	}()
+	Load("pos/bad", &p)
+}
+
+func TestCodec_Base64(t *testing.T) {
+	b := []byte{0x00, 0xff, 0x10}
+	s := EncodeString(b)
+	got, err := DecodeString(s)
+	if err != nil || !bytes.Equal(got, b) {
+		t.Fatalf("base64 round trip: %v %v", got, err)
+	}
+	if _, err := DecodeString(s + "="); err != ErrBadBase64 {
+		t.Fatal("padded input must be rejected")
+	}
+}
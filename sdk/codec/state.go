@@ -0,0 +1,27 @@
+package codec
+
+import "contract-template/sdk"
+
+// Store writes m under key as a base64 encoded versioned record.
+func Store(key string, version uint64, m Marshaler) {
+	sdk.StateSetObject(key, EncodeString(Marshal(version, m)))
+}
+
+// Load reads the record stored under key into u. It returns the stored schema
+// version and false when the key is unset. A corrupt record aborts execution,
+// since continuing with partially decoded state is never safe.
+func Load(key string, u Unmarshaler) (uint64, bool) {
+	v := sdk.StateGetObject(key)
+	if v == nil || *v == "" {
+		return 0, false
+	}
+	b, err := DecodeString(*v)
+	if err != nil {
+		sdk.Abort("corrupt record: " + key)
+	}
+	version, err := Unmarshal(b, u)
+	if err != nil {
+		sdk.Abort("corrupt record: " + key)
+	}
+	return version, true
+}
@@ -1,5 +1,11 @@
 package sdk
 
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
 type Env struct {
 	ContractId string `json:"contract.id"`
 
@@ -22,3 +28,153 @@ type Env struct {
 	//Proper RC payer support is not implemented yet.
 	Payer Address `json:"payer"`
 }
+
+// BlockTime returns the block timestamp in unix seconds. It aborts if the
+// host reported a timestamp ParseTimestamp does not accept.
+func (e Env) BlockTime() uint64 {
+	t, err := ParseTimestamp(e.Timestamp)
+	if err != nil {
+		Abort(err.Error())
+	}
+	return t
+}
+
+// ParseTimestamp parses a block timestamp into unix seconds. Hosts report
+// UTC ISO 8601 times such as "2025-06-01T12:00:00", optionally followed by
+// fractional seconds and "Z"; plain unix seconds are accepted as well.
+// Fractions are truncated.
+func ParseTimestamp(s string) (uint64, error) {
+	bad := errors.New("env: bad timestamp " + strconv.Quote(s))
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return n, nil
+	}
+	s = strings.TrimSuffix(s, "Z")
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		if !allDigits(s[i+1:]) || i+1 == len(s) {
+			return 0, bad
+		}
+		s = s[:i]
+	}
+	// 2006-01-02T15:04:05
+	if len(s) != 19 || s[4] != '-' || s[7] != '-' || s[10] != 'T' || s[13] != ':' || s[16] != ':' {
+		return 0, bad
+	}
+	field := func(i, n int) int {
+		if !allDigits(s[i : i+n]) {
+			return -1
+		}
+		v, _ := strconv.Atoi(s[i : i+n])
+		return v
+	}
+	y, mo, d := field(0, 4), field(5, 2), field(8, 2)
+	h, mi, sec := field(11, 2), field(14, 2), field(17, 2)
+	if y < 1970 || mo < 1 || mo > 12 || d < 1 || d > daysIn(y, mo) || h < 0 || h > 23 || mi < 0 || mi > 59 || sec < 0 || sec > 59 {
+		return 0, bad
+	}
+	return uint64(daysFromCivil(y, mo, d))*86400 + uint64(h*3600+mi*60+sec), nil
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func daysIn(y, m int) int {
+	switch m {
+	case 2:
+		if y%4 == 0 && (y%100 != 0 || y%400 == 0) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+// daysFromCivil is the number of days from 1970-01-01 to y-m-d in the
+// proleptic Gregorian calendar (Howard Hinnant's algorithm).
+func daysFromCivil(y, m, d int) int {
+	if m <= 2 {
+		y--
+	}
+	era := y / 400
+	yoe := y - era*400
+	mp := (m + 9) % 12
+	doy := (153*mp+2)/5 + d - 1
+	doe := yoe*365 + yoe/4 - yoe/100 + doy
+	return era*146097 + doe - 719468
+}
+
+// Deadline is the last block a transaction may execute in, by block height,
+// block time or both. The zero Deadline never expires.
+type Deadline struct {
+	Height uint64 // last block height, 0 for none
+	Time   uint64 // last block time in unix seconds, 0 for none
+}
+
+// ParseDeadline parses a deadline payload field: "" or "0" for none, a
+// block height such as "95000000", or a block time as an ISO 8601 timestamp
+// ("2025-06-01T12:00:00Z", see ParseTimestamp). Plain numbers are always
+// heights.
+func ParseDeadline(s string) (Deadline, error) {
+	if s == "" {
+		return Deadline{}, nil
+	}
+	if allDigits(s) {
+		h, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return Deadline{}, errors.New("deadline: bad value " + strconv.Quote(s))
+		}
+		return Deadline{Height: h}, nil
+	}
+	t, err := ParseTimestamp(s)
+	if err != nil || t == 0 {
+		return Deadline{}, errors.New("deadline: bad value " + strconv.Quote(s))
+	}
+	return Deadline{Time: t}, nil
+}
+
+// UnmarshalJSON accepts a block height as a JSON number or any ParseDeadline
+// form as a JSON string, so JSON payloads take the same deadlines.
+func (d *Deadline) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		*d = Deadline{}
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	v, err := ParseDeadline(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Expired reports whether the block of env is past d.
+func (d Deadline) Expired(env Env) bool {
+	if d.Height != 0 && env.BlockHeight > d.Height {
+		return true
+	}
+	return d.Time != 0 && env.BlockTime() > d.Time
+}
+
+// RequireDeadline aborts if the deadline payload field s does not parse or
+// the current block is past it, so that a transaction left waiting does not
+// execute at a much later price.
+func RequireDeadline(s string) {
+	d, err := ParseDeadline(s)
+	if err != nil {
+		Abort(err.Error())
+	}
+	if d.Expired(GetEnv()) {
+		Abort("deadline: expired")
+	}
+}
@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	cases := map[string]uint64{
+		"0":                        0,
+		"1700000000":               1700000000,
+		"1970-01-01T00:00:00":      0,
+		"2000-02-29T12:30:15":      951827415,
+		"2024-12-31T23:59:59Z":     1735689599,
+		"2025-06-01T12:00:00.250":  1748779200,
+		"2025-06-01T12:00:00.999Z": 1748779200,
+	}
+	for in, want := range cases {
+		if got, err := ParseTimestamp(in); err != nil || got != want {
+			t.Errorf("ParseTimestamp(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "-1", "2025-06-01", "2025-06-01 12:00:00", "2025-02-29T00:00:00", "2025-06-01T24:00:00",
+		"1969-12-31T23:59:59", "2025-06-01T12:00:00.", "2025-06-01T12:00:00+02:00", "2025-6-01T12:00:000"} {
+		if got, err := ParseTimestamp(in); err == nil {
+			t.Errorf("ParseTimestamp(%q) = %d, want error", in, got)
+		}
+	}
+}
+
+func TestEnvBlockTime(t *testing.T) {
+	ShimReset()
+	ShimSetTimestamp("2025-06-01T12:00:03")
+	if GetEnv().BlockTime() != 1748779203 {
+		t.Fatal("block time")
+	}
+	ShimSetTimestamp("yesterday")
+	mustAbort(t, "env: bad timestamp", func() { GetEnv().BlockTime() })
+}
+
+func TestDeadline(t *testing.T) {
+	cases := map[string]Deadline{
+		"":                     {},
+		"0":                    {},
+		"95000000":             {Height: 95000000},
+		"2025-06-01T12:00:00Z": {Time: 1748779200},
+	}
+	for in, want := range cases {
+		if got, err := ParseDeadline(in); err != nil || got != want {
+			t.Errorf("ParseDeadline(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"-1", "soon", "99999999999999999999", "1970-01-01T00:00:00", "2025-06-01"} {
+		if got, err := ParseDeadline(in); err == nil {
+			t.Errorf("ParseDeadline(%q) = %+v, want error", in, got)
+		}
+	}
+
+	var v struct{ A, B, C Deadline }
+	if err := json.Unmarshal([]byte(`{"A":1200,"B":"2025-06-01T12:00:00Z","C":null}`), &v); err != nil ||
+		v.A != (Deadline{Height: 1200}) || v.B != (Deadline{Time: 1748779200}) || v.C != (Deadline{}) {
+		t.Fatalf("json deadlines = %+v, %v", v, err)
+	}
+	if err := json.Unmarshal([]byte(`{"A":"soon"}`), &v); err == nil {
+		t.Fatal("bad json deadline accepted")
+	}
+
+	ShimReset()
+	ShimSetEnv("anchor.height", "100")
+	ShimSetTimestamp("2025-06-01T12:00:00")
+	for _, ok := range []string{"", "100", "2025-06-01T12:00:00"} {
+		RequireDeadline(ok)
+	}
+	mustAbort(t, "deadline: expired", func() { RequireDeadline("99") })
+	mustAbort(t, "deadline: expired", func() { RequireDeadline("2025-06-01T11:59:59") })
+	mustAbort(t, "deadline: bad value", func() { RequireDeadline("tomorrow") })
+}
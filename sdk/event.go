@@ -0,0 +1,33 @@
+package sdk
+
+import "encoding/json"
+
+// Event is a structured log line for indexers. It is written through
+// console.log as {"event":"transfer","attrs":{"from":"hive:alice",...}}.
+type Event struct {
+	Type  string            `json:"event"`
+	Attrs map[string]string `json:"attrs"`
+}
+
+// EmitEvent logs an event of type typ with attributes given as key, value
+// pairs, e.g. EmitEvent("transfer", "from", from, "to", to, "amount", amt).
+func EmitEvent(typ string, kv ...string) {
+	if len(kv)%2 != 0 {
+		Abort("event: odd number of attribute arguments")
+	}
+	e := Event{Type: typ, Attrs: make(map[string]string, len(kv)/2)}
+	for i := 0; i < len(kv); i += 2 {
+		e.Attrs[kv[i]] = kv[i+1]
+	}
+	b, _ := json.Marshal(e)
+	Log(string(b))
+}
+
+// ParseEvent decodes a log line written by EmitEvent.
+func ParseEvent(line string) (Event, bool) {
+	var e Event
+	if json.Unmarshal([]byte(line), &e) != nil || e.Type == "" {
+		return Event{}, false
+	}
+	return e, true
+}
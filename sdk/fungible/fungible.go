@@ -0,0 +1,275 @@
+// Package fungible implements a fungible token: balances, allowances, a
+// capped supply and the events indexers need to follow them.
+//
+// State layout:
+//
+//	token/name                        display name
+//	token/symbol                      ticker, e.g. "TOKEN"; set once by Init
+//	token/precision                   decimals of one unit
+//	token/max_supply                  supply cap in units, "0" for none
+//	supply                            total supply in units
+//	accs/<address>/bal                balance in units
+//	accs/<address>/allow/<spender>    amount spender may move for address
+//
+// Tokens deployed before this package kept "isInit" and "owner" instead of
+// the config and access owner; MigrateLegacy converts them.
+//
+// All operations act for the immediate caller (sdk.Env.Caller), so a
+// contract holding tokens moves its own balance, never the signer's. Minting
+// is limited to the access owner and holders of RoleMinter.
+//
+// Events: "transfer" (from, to, amount), "mint" (to, amount), "burn" (from,
+// amount) and "approval" (owner, spender, amount).
+package fungible
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"math"
+	"strconv"
+)
+
+const (
+	keyName      = "token/name"
+	keySymbol    = "token/symbol"
+	keyPrecision = "token/precision"
+	keyMaxSupply = "token/max_supply"
+	keySupply    = "supply"
+	keyAccPrefix = "accs/" // accs/<address>/...
+
+	keyLegacyInit  = "isInit"
+	keyLegacyOwner = "owner"
+)
+
+// RoleMinter may mint in addition to the owner.
+const RoleMinter = "minter"
+
+// MaxPrecision keeps one whole token representable in a uint64.
+const MaxPrecision = 18
+
+// Unlimited is an allowance that transfers never decrease.
+const Unlimited = math.MaxUint64
+
+// Config describes the token. MaxSupply 0 means no cap.
+type Config struct {
+	Name      string
+	Symbol    string
+	Precision uint8
+	MaxSupply uint64
+}
+
+func getStr(key string) string {
+	v := sdk.StateGetObject(key)
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func getUint(key string) uint64 {
+	n, _ := strconv.ParseUint(getStr(key), 10, 64)
+	return n
+}
+
+func setUint(key string, v uint64) {
+	sdk.StateSetObject(key, strconv.FormatUint(v, 10))
+}
+
+func balanceKey(addr sdk.Address) string {
+	return keyAccPrefix + addr.String() + "/bal"
+}
+
+func allowanceKey(owner, spender sdk.Address) string {
+	return keyAccPrefix + owner.String() + "/allow/" + spender.String()
+}
+
+func caller() sdk.Address {
+	return sdk.GetEnv().Caller.Address
+}
+
+func u(v uint64) string { return strconv.FormatUint(v, 10) }
+
+// IsInit reports whether Init has run, in this layout or the legacy one.
+func IsInit() bool {
+	return getStr(keySymbol) != "" || getStr(keyLegacyInit) != ""
+}
+
+// RequireInit aborts before Init.
+func RequireInit() {
+	if !IsInit() {
+		sdk.Abort("fungible: not initialized")
+	}
+}
+
+// Init stores the token config and makes the caller the owner. It aborts if
+// the token is already initialized.
+func Init(cfg Config) {
+	if IsInit() {
+		sdk.Abort("fungible: already initialized")
+	}
+	checkConfig(cfg)
+	access.InitOwner(caller())
+	setConfig(cfg)
+	setUint(keySupply, 0)
+}
+
+func checkConfig(cfg Config) {
+	if cfg.Symbol == "" || len(cfg.Symbol) > 16 {
+		sdk.Abort("fungible: symbol must be 1-16 characters")
+	}
+	if cfg.Precision > MaxPrecision {
+		sdk.Abort("fungible: precision above " + strconv.Itoa(MaxPrecision))
+	}
+}
+
+func setConfig(cfg Config) {
+	sdk.StateSetObject(keyName, cfg.Name)
+	sdk.StateSetObject(keySymbol, cfg.Symbol)
+	setUint(keyPrecision, uint64(cfg.Precision))
+	setUint(keyMaxSupply, cfg.MaxSupply)
+}
+
+// MigrateLegacy converts a token in the legacy layout: its owner becomes the
+// access owner and cfg supplies the config the old contract hard coded.
+// Balances and supply are kept. State in this layout is left unchanged.
+func MigrateLegacy(cfg Config) {
+	if getStr(keyLegacyInit) == "" {
+		return
+	}
+	checkConfig(cfg)
+	if owner := getStr(keyLegacyOwner); owner != "" && access.Owner() == "" {
+		access.InitOwner(sdk.Address(owner))
+	}
+	setConfig(cfg)
+	setUint(keySupply, Supply())
+	sdk.StateDeleteObject(keyLegacyInit)
+	sdk.StateDeleteObject(keyLegacyOwner)
+}
+
+// GetConfig returns the config stored by Init.
+func GetConfig() Config {
+	return Config{
+		Name:      getStr(keyName),
+		Symbol:    getStr(keySymbol),
+		Precision: uint8(getUint(keyPrecision)),
+		MaxSupply: getUint(keyMaxSupply),
+	}
+}
+
+func Supply() uint64 { return getUint(keySupply) }
+
+func BalanceOf(addr sdk.Address) uint64 { return getUint(balanceKey(addr)) }
+
+func Allowance(owner, spender sdk.Address) uint64 {
+	return getUint(allowanceKey(owner, spender))
+}
+
+func requireAmount(amount uint64) {
+	if amount == 0 {
+		sdk.Abort("fungible: zero amount")
+	}
+}
+
+func requireAddress(addr sdk.Address) {
+	if _, err := sdk.ParseAddress(addr.String()); err != nil {
+		sdk.Abort("fungible: bad address " + addr.String())
+	}
+}
+
+func credit(addr sdk.Address, amount uint64) {
+	bal := BalanceOf(addr)
+	if bal > math.MaxUint64-amount {
+		sdk.Abort("fungible: balance overflow")
+	}
+	setUint(balanceKey(addr), bal+amount)
+}
+
+func debit(addr sdk.Address, amount uint64) {
+	bal := BalanceOf(addr)
+	if bal < amount {
+		sdk.Abort("fungible: insufficient balance")
+	}
+	if bal == amount {
+		sdk.StateDeleteObject(balanceKey(addr))
+		return
+	}
+	setUint(balanceKey(addr), bal-amount)
+}
+
+func move(from, to sdk.Address, amount uint64) {
+	requireAmount(amount)
+	requireAddress(to)
+	debit(from, amount)
+	credit(to, amount)
+	sdk.EmitEvent("transfer", "from", from.String(), "to", to.String(), "amount", u(amount))
+}
+
+// Mint creates amount for to. Owner or RoleMinter only; the supply may not
+// exceed MaxSupply.
+func Mint(to sdk.Address, amount uint64) {
+	RequireInit()
+	if c := caller(); !access.IsOwner(c) && !access.HasRole(RoleMinter, c) {
+		sdk.Abort("fungible: caller cannot mint")
+	}
+	requireAmount(amount)
+	requireAddress(to)
+	supply := Supply()
+	if supply > math.MaxUint64-amount {
+		sdk.Abort("fungible: supply overflow")
+	}
+	if limit := getUint(keyMaxSupply); limit != 0 && supply+amount > limit {
+		sdk.Abort("fungible: max supply exceeded")
+	}
+	setUint(keySupply, supply+amount)
+	credit(to, amount)
+	sdk.EmitEvent("mint", "to", to.String(), "amount", u(amount))
+}
+
+// Burn destroys amount of the caller's balance.
+func Burn(amount uint64) {
+	RequireInit()
+	requireAmount(amount)
+	from := caller()
+	debit(from, amount)
+	setUint(keySupply, Supply()-amount)
+	sdk.EmitEvent("burn", "from", from.String(), "amount", u(amount))
+}
+
+// Transfer moves amount from the caller to to.
+func Transfer(to sdk.Address, amount uint64) {
+	RequireInit()
+	move(caller(), to, amount)
+}
+
+// Approve sets the amount spender may move from the caller's balance,
+// replacing any previous allowance. 0 revokes; Unlimited never decreases.
+func Approve(spender sdk.Address, amount uint64) {
+	RequireInit()
+	requireAddress(spender)
+	owner := caller()
+	if amount == 0 {
+		sdk.StateDeleteObject(allowanceKey(owner, spender))
+	} else {
+		setUint(allowanceKey(owner, spender), amount)
+	}
+	sdk.EmitEvent("approval", "owner", owner.String(), "spender", spender.String(), "amount", u(amount))
+}
+
+// TransferFrom moves amount from from to to, spending the allowance from
+// granted to the caller.
+func TransferFrom(from, to sdk.Address, amount uint64) {
+	RequireInit()
+	spender := caller()
+	allowed := Allowance(from, spender)
+	if allowed < amount {
+		sdk.Abort("fungible: allowance exceeded")
+	}
+	if allowed != Unlimited {
+		if allowed == amount {
+			sdk.StateDeleteObject(allowanceKey(from, spender))
+		} else {
+			setUint(allowanceKey(from, spender), allowed-amount)
+		}
+	}
+	move(from, to, amount)
+}
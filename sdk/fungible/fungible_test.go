@@ -0,0 +1,204 @@
+package fungible
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"testing"
+)
+
+func expectAbort(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected abort %q", want)
+		}
+		if msg, _ := r.(string); msg != want {
+			t.Fatalf("abort = %q, want %q", msg, want)
+		}
+	}()
+	f()
+}
+
+func setup(t *testing.T, maxSupply uint64) {
+	t.Helper()
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:token")
+	sdk.ShimSetSender("hive:owner")
+	Init(Config{Name: "Test", Symbol: "TST", Precision: 3, MaxSupply: maxSupply})
+}
+
+func TestFungible_Init(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	expectAbort(t, "fungible: not initialized", func() { Mint("hive:owner", 1) })
+	expectAbort(t, "fungible: not initialized", func() { Transfer("hive:bob", 1) })
+	expectAbort(t, "fungible: symbol must be 1-16 characters", func() { Init(Config{}) })
+	expectAbort(t, "fungible: precision above 18", func() { Init(Config{Symbol: "X", Precision: 19}) })
+
+	Init(Config{Name: "Test", Symbol: "TST", Precision: 6, MaxSupply: 100})
+	if !IsInit() || GetConfig() != (Config{Name: "Test", Symbol: "TST", Precision: 6, MaxSupply: 100}) || Supply() != 0 {
+		t.Fatalf("config = %+v", GetConfig())
+	}
+	if access.Owner() != "hive:owner" {
+		t.Fatal("caller is not the owner")
+	}
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "fungible: already initialized", func() { Init(Config{Symbol: "EVIL"}) })
+}
+
+func TestFungible_LegacyLayout(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:token")
+	sdk.StateSetObject("isInit", "1")
+	sdk.StateSetObject("owner", "hive:creator")
+	sdk.StateSetObject("supply", "700")
+	sdk.StateSetObject("accs/hive:alice/bal", "700")
+
+	// a deployed token is not open to a new init after the upgrade
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "fungible: already initialized", func() { Init(Config{Symbol: "EVIL"}) })
+	expectAbort(t, "fungible: caller cannot mint", func() { Mint("hive:mallory", 1) })
+
+	cfg := Config{Name: "Token", Symbol: "TOKEN", Precision: 3, MaxSupply: 1000}
+	MigrateLegacy(cfg)
+	if access.Owner() != "hive:creator" || GetConfig() != cfg || Supply() != 700 || BalanceOf("hive:alice") != 700 {
+		t.Fatalf("migrated: owner %s, config %+v, supply %d", access.Owner(), GetConfig(), Supply())
+	}
+	if getStr("isInit") != "" || getStr("owner") != "" {
+		t.Fatal("legacy keys not removed")
+	}
+	sdk.ShimSetSender("hive:creator")
+	expectAbort(t, "fungible: max supply exceeded", func() { Mint("hive:creator", 301) })
+	Mint("hive:creator", 300)
+	// running it again is a no-op
+	MigrateLegacy(Config{Symbol: "EVIL"})
+	if GetConfig() != cfg {
+		t.Fatal("second migration changed the config")
+	}
+}
+
+func TestFungible_MintBurnSupply(t *testing.T) {
+	setup(t, 1000)
+	Mint("hive:alice", 600)
+	Mint("hive:bob", 400)
+	if Supply() != 1000 || BalanceOf("hive:alice") != 600 || BalanceOf("hive:bob") != 400 {
+		t.Fatal("mint balances")
+	}
+	expectAbort(t, "fungible: max supply exceeded", func() { Mint("hive:alice", 1) })
+	expectAbort(t, "fungible: zero amount", func() { Mint("hive:alice", 0) })
+	expectAbort(t, "fungible: bad address hive:x", func() { Mint("hive:x", 1) })
+
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "fungible: caller cannot mint", func() { Mint("hive:mallory", 1) })
+
+	sdk.ShimSetSender("hive:bob")
+	expectAbort(t, "fungible: insufficient balance", func() { Burn(401) })
+	Burn(400)
+	if Supply() != 600 || BalanceOf("hive:bob") != 0 || *sdk.StateGetObject("accs/hive:bob/bal") != "" {
+		t.Fatal("burn must reduce supply and clear the empty balance")
+	}
+	// burned supply can be minted again under the cap
+	sdk.ShimSetSender("hive:owner")
+	Mint("hive:bob", 400)
+}
+
+func TestFungible_Minters(t *testing.T) {
+	setup(t, 0)
+	sdk.ShimSetSender("hive:owner")
+	access.GrantRole(RoleMinter, "contract:bridge")
+	sdk.ShimSetCaller("contract:bridge")
+	Mint("hive:alice", 1<<63)
+	Mint("hive:alice", 1<<63-1)
+	expectAbort(t, "fungible: supply overflow", func() { Mint("hive:bob", 2) })
+
+	// the signer being the owner does not let another contract mint
+	sdk.ShimSetCaller("contract:other")
+	expectAbort(t, "fungible: caller cannot mint", func() { Mint("hive:alice", 1) })
+}
+
+func TestFungible_Transfer(t *testing.T) {
+	setup(t, 0)
+	Mint("hive:alice", 100)
+	sdk.ShimSetSender("hive:alice")
+	Transfer("hive:bob", 30)
+	Transfer("hive:alice", 10) // self transfer keeps the balance
+	if BalanceOf("hive:alice") != 70 || BalanceOf("hive:bob") != 30 || Supply() != 100 {
+		t.Fatal("transfer balances")
+	}
+	expectAbort(t, "fungible: insufficient balance", func() { Transfer("hive:bob", 71) })
+	expectAbort(t, "fungible: zero amount", func() { Transfer("hive:bob", 0) })
+	expectAbort(t, "fungible: bad address bob", func() { Transfer("bob", 1) })
+
+	// a contract moves its own balance, not the signer's
+	sdk.ShimSetCaller("contract:pool")
+	expectAbort(t, "fungible: insufficient balance", func() { Transfer("hive:bob", 1) })
+}
+
+func TestFungible_Allowances(t *testing.T) {
+	setup(t, 0)
+	Mint("hive:alice", 100)
+	sdk.ShimSetSender("hive:alice")
+	Approve("contract:pool", 40)
+	if Allowance("hive:alice", "contract:pool") != 40 {
+		t.Fatal("allowance not set")
+	}
+
+	sdk.ShimSetCaller("contract:pool")
+	expectAbort(t, "fungible: allowance exceeded", func() { TransferFrom("hive:alice", "contract:pool", 41) })
+	TransferFrom("hive:alice", "contract:pool", 25)
+	if Allowance("hive:alice", "contract:pool") != 15 || BalanceOf("contract:pool") != 25 {
+		t.Fatal("allowance not spent")
+	}
+	TransferFrom("hive:alice", "hive:carol", 15)
+	if Allowance("hive:alice", "contract:pool") != 0 || BalanceOf("hive:carol") != 15 {
+		t.Fatal("allowance not exhausted")
+	}
+	// allowances are per spender
+	sdk.ShimSetCaller("contract:other")
+	expectAbort(t, "fungible: allowance exceeded", func() { TransferFrom("hive:alice", "contract:other", 1) })
+
+	// unlimited allowances are not decreased; 0 revokes
+	sdk.ShimSetCaller("")
+	Approve("hive:bob", Unlimited)
+	sdk.ShimSetSender("hive:bob")
+	TransferFrom("hive:alice", "hive:bob", 10)
+	if Allowance("hive:alice", "hive:bob") != Unlimited {
+		t.Fatal("unlimited allowance decreased")
+	}
+	expectAbort(t, "fungible: insufficient balance", func() { TransferFrom("hive:alice", "hive:bob", 51) })
+	sdk.ShimSetSender("hive:alice")
+	Approve("hive:bob", 0)
+	if Allowance("hive:alice", "hive:bob") != 0 {
+		t.Fatal("approve 0 must revoke")
+	}
+}
+
+func TestFungible_Events(t *testing.T) {
+	setup(t, 0)
+	Mint("hive:alice", 5)
+	sdk.ShimSetSender("hive:alice")
+	Approve("hive:bob", 3)
+	Transfer("hive:bob", 2)
+	Burn(1)
+	want := []sdk.Event{
+		{Type: "mint", Attrs: map[string]string{"to": "hive:alice", "amount": "5"}},
+		{Type: "approval", Attrs: map[string]string{"owner": "hive:alice", "spender": "hive:bob", "amount": "3"}},
+		{Type: "transfer", Attrs: map[string]string{"from": "hive:alice", "to": "hive:bob", "amount": "2"}},
+		{Type: "burn", Attrs: map[string]string{"from": "hive:alice", "amount": "1"}},
+	}
+	got := sdk.ShimEvents()
+	if len(got) != len(want) {
+		t.Fatalf("events = %+v", got)
+	}
+	for i := range want {
+		if got[i].Type != want[i].Type || len(got[i].Attrs) != len(want[i].Attrs) {
+			t.Fatalf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+		for k, v := range want[i].Attrs {
+			if got[i].Attrs[k] != v {
+				t.Fatalf("event %d = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@ package sdk
 
 import (
 	"encoding/json"
+	"os"
 	"strconv"
 	"sync"
 )
@@ -22,14 +23,21 @@ var (
 		"anchor.tx_index":            "0",
 		"anchor.op_index":            "0",
 		"msg.sender":                 "hive:alice",
+		"msg.caller":                 "",
 		"msg.required_auths":         "[]",
 		"msg.required_posting_auths": "[]",
 	}
-	shimMu sync.RWMutex
+	shimLogs []string
+	shimMu   sync.RWMutex
 )
 
 // wasmimport-compatible function signatures
-func log(s *string) *string { return nil }
+func log(s *string) *string {
+	shimMu.Lock()
+	defer shimMu.Unlock()
+	shimLogs = append(shimLogs, *s)
+	return nil
+}
 
 func abort(msg, file *string, line, column *int32) {
 	panic(*msg)
@@ -80,6 +88,7 @@ func getEnv(_ *string) *string {
 		"block.height":               0,
 		"block.timestamp":            shimEnv["anchor.timestamp"],
 		"msg.sender":                 shimEnv["msg.sender"],
+		"msg.caller":                 shimEnv["msg.caller"],
 		"msg.required_auths":         requiredAuths,
 		"msg.required_posting_auths": postingAuths,
 	}
@@ -113,9 +122,14 @@ func hiveDraw(amount *string, asset *string) *string {
 	shimMu.Lock()
 	defer shimMu.Unlock()
 	amt, _ := strconv.ParseInt(*amount, 10, 64)
-	sender := shimEnv["msg.sender"]
+	// draws come from the immediate caller, like the host's transfer.allow
+	// intents: the sender, or the calling contract during contracts.call
+	from := shimEnv["msg.caller"]
+	if from == "" {
+		from = shimEnv["msg.sender"]
+	}
 	contract := shimEnv["contract_id"]
-	decBal(sender, *asset, amt)
+	decBal(from, *asset, amt)
 	incBal(contract, *asset, amt)
 	return nil
 }
@@ -140,6 +154,9 @@ func ShimReset() {
 	defer shimMu.Unlock()
 	shimState = map[string]string{}
 	shimBalances = map[string]map[string]int64{}
+	shimContracts = map[string]map[string]func(*string) *string{}
+	shimContractStates = map[string]map[string]string{}
+	shimLogs = nil
 	shimEnv = map[string]string{
 		"contract_id":                "contract:test",
 		"anchor.id":                  "tx:0",
@@ -149,6 +166,7 @@ func ShimReset() {
 		"anchor.tx_index":            "0",
 		"anchor.op_index":            "0",
 		"msg.sender":                 "hive:alice",
+		"msg.caller":                 "",
 		"msg.required_auths":         "[]",
 		"msg.required_posting_auths": "[]",
 	}
@@ -156,6 +174,7 @@ func ShimReset() {
 
 func ShimSetEnv(key, val string)  { shimMu.Lock(); shimEnv[key] = val; shimMu.Unlock() }
 func ShimSetSender(addr Address)  { ShimSetEnv("msg.sender", addr.String()) }
+func ShimSetCaller(addr Address)  { ShimSetEnv("msg.caller", addr.String()) }
 func ShimSetTimestamp(ts string)  { ShimSetEnv("anchor.timestamp", ts) }
 func ShimSetContractId(id string) { ShimSetEnv("contract_id", id) }
 
@@ -181,6 +200,87 @@ func ShimSetAuths(requiredAuths []Address, postingAuths []Address) {
 	ShimSetEnv("msg.required_posting_auths", string(pa))
 }
 
+// ShimLogs returns the lines logged since ShimReset, including events.
+func ShimLogs() []string {
+	shimMu.RLock()
+	defer shimMu.RUnlock()
+	return append([]string(nil), shimLogs...)
+}
+
+// ShimEvents returns the events emitted since ShimReset, oldest first.
+func ShimEvents() []Event {
+	var out []Event
+	for _, l := range ShimLogs() {
+		if e, ok := ParseEvent(l); ok {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ShimDumpState returns contract state as a JSON object of key/value strings
+// with sorted keys, suitable for saving as a test fixture.
+func ShimDumpState() []byte {
+	shimMu.RLock()
+	defer shimMu.RUnlock()
+	b, _ := json.MarshalIndent(shimState, "", "  ")
+	return b
+}
+
+// ShimLoadState replaces contract state with a fixture written by ShimDumpState.
+func ShimLoadState(data []byte) error {
+	st := map[string]string{}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	shimMu.Lock()
+	defer shimMu.Unlock()
+	shimState = st
+	return nil
+}
+
+// ShimLoadStateFile loads a state fixture from disk, e.g. testdata/state_v1.json.
+func ShimLoadStateFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return ShimLoadState(b)
+}
+
+// ShimSnapshot captures state, balances, env, logs and the state of
+// registered contracts. Calling the returned function restores them,
+// mimicking the host reverting an aborted call.
+func ShimSnapshot() (restore func()) {
+	shimMu.RLock()
+	state := copyMap(shimState)
+	env := copyMap(shimEnv)
+	balances := make(map[string]map[string]int64, len(shimBalances))
+	for addr, b := range shimBalances {
+		balances[addr] = copyMap(b)
+	}
+	contracts := make(map[string]map[string]string, len(shimContractStates))
+	for id, st := range shimContractStates {
+		contracts[id] = copyMap(st)
+	}
+	logs := append([]string(nil), shimLogs...)
+	shimMu.RUnlock()
+	return func() {
+		shimMu.Lock()
+		defer shimMu.Unlock()
+		shimState, shimEnv, shimBalances, shimContractStates = state, env, balances, contracts
+		shimLogs = logs
+	}
+}
+
+func copyMap[V any](m map[string]V) map[string]V {
+	out := make(map[string]V, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
 func incBal(addr, asset string, amt int64) {
 	if _, ok := shimBalances[addr]; !ok {
 		shimBalances[addr] = map[string]int64{}
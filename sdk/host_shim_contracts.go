@@ -0,0 +1,158 @@
+//go:build !gc.custom
+
+package sdk
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Contracts reachable through contracts.call in tests. Each contract keeps
+// its own state; the running contract's state is shimState.
+var (
+	shimContracts      = map[string]map[string]func(*string) *string{}
+	shimContractStates = map[string]map[string]string{}
+)
+
+func contractCall(contractId *string, method *string, payload *string, _ *string) *string {
+	shimMu.RLock()
+	fn := shimContracts[*contractId][*method]
+	shimMu.RUnlock()
+	if fn == nil {
+		Abort("contract call: " + *contractId + " has no method " + *method)
+	}
+	leave := enterContract(*contractId, true)
+	defer leave()
+	return fn(payload)
+}
+
+// enterContract makes id the running contract with its own state. The
+// returned function switches back to the previous contract.
+func enterContract(id string, asCaller bool) (leave func()) {
+	shimMu.Lock()
+	defer shimMu.Unlock()
+	prev := shimEnv["contract_id"]
+	prevCaller := shimEnv["msg.caller"]
+	shimContractStates[prev] = shimState
+	if shimContractStates[id] == nil {
+		shimContractStates[id] = map[string]string{}
+	}
+	shimState = shimContractStates[id]
+	shimEnv["contract_id"] = id
+	if asCaller {
+		shimEnv["msg.caller"] = prev
+	}
+	return func() {
+		shimMu.Lock()
+		defer shimMu.Unlock()
+		shimState = shimContractStates[prev]
+		shimEnv["contract_id"] = prev
+		shimEnv["msg.caller"] = prevCaller
+	}
+}
+
+// ShimWithContract runs f as contract id with that contract's state, e.g. to
+// initialize a registered contract. Sender and caller are left unchanged.
+func ShimWithContract(id Address, f func()) {
+	leave := enterContract(id.String(), false)
+	defer leave()
+	f()
+}
+
+func contractRead(contractId *string, key *string) *string {
+	shimMu.RLock()
+	defer shimMu.RUnlock()
+	st := shimContractStates[*contractId]
+	if *contractId == shimEnv["contract_id"] {
+		st = shimState
+	}
+	v := st[*key]
+	return &v
+}
+
+// ShimRegisterContract makes a contract callable with ContractCall. The
+// entrypoints run with the callee's own state and contract id, and see the
+// calling contract as msg.caller.
+func ShimRegisterContract(id Address, entrypoints map[string]func(*string) *string) {
+	shimMu.Lock()
+	defer shimMu.Unlock()
+	shimContracts[id.String()] = entrypoints
+}
+
+// ShimRegisterToken registers a mock token contract implementing the
+// ContractToken methods plus approve ("spender,amount"). Balances are kept
+// with the shim balances under the token id, so ShimSetBalance and
+// ShimGetBalance work with Asset("contract:<id>/<SYMBOL>").
+func ShimRegisterToken(contract Address, symbol string, decimals int) ContractToken {
+	t := ContractToken{Contract: contract, Symbol: symbol}
+	asset := t.Id()
+	amount := func(s string) int64 {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n <= 0 {
+			Abort("token: bad amount")
+		}
+		return n
+	}
+	move := func(from, to string, amt int64) {
+		shimMu.Lock()
+		bal := shimBalances[from][asset]
+		if bal >= amt {
+			decBal(from, asset, amt)
+			incBal(to, asset, amt)
+		}
+		shimMu.Unlock()
+		if bal < amt {
+			Abort("token: insufficient balance")
+		}
+	}
+	allowKey := func(owner, spender string) string { return "allow/" + owner + "/" + spender }
+	ShimRegisterContract(contract, map[string]func(*string) *string{
+		"transfer": func(p *string) *string {
+			to, amt, _ := strings.Cut(*p, ",")
+			move(GetEnv().Caller.Address.String(), to, amount(amt))
+			return nil
+		},
+		"approve": func(p *string) *string {
+			spender, amt, _ := strings.Cut(*p, ",")
+			StateSetObject(allowKey(GetEnv().Caller.Address.String(), spender), amt)
+			return nil
+		},
+		"transfer_from": func(p *string) *string {
+			parts := strings.Split(*p, ",")
+			if len(parts) != 3 {
+				Abort("token: bad payload")
+			}
+			amt := amount(parts[2])
+			key := allowKey(parts[0], GetEnv().Caller.Address.String())
+			allowed, _ := strconv.ParseInt(*StateGetObject(key), 10, 64)
+			if allowed < amt {
+				Abort("token: allowance exceeded")
+			}
+			StateSetObject(key, strconv.FormatInt(allowed-amt, 10))
+			move(parts[0], parts[1], amt)
+			return nil
+		},
+		"balance_of": func(p *string) *string {
+			s := strconv.FormatInt(ShimGetBalance(Address(*p), Asset(asset)), 10)
+			return &s
+		},
+		"decimals": func(*string) *string {
+			s := strconv.Itoa(decimals)
+			return &s
+		},
+	})
+	return t
+}
+
+// ShimApproveToken lets spender draw up to amount of owner's mock token, as
+// if owner had called approve on the token contract.
+func ShimApproveToken(t ContractToken, owner, spender Address, amount int64) {
+	shimMu.Lock()
+	defer shimMu.Unlock()
+	st := shimContractStates[t.Contract.String()]
+	if st == nil {
+		st = map[string]string{}
+		shimContractStates[t.Contract.String()] = st
+	}
+	st["allow/"+owner.String()+"/"+spender.String()] = strconv.FormatInt(amount, 10)
+}
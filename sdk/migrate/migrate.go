@@ -0,0 +1,114 @@
+// Package migrate records the contract's state schema version and upgrades
+// existing state through an ordered list of migration steps.
+//
+// Version 0 means state written before the contract adopted this package.
+// Steps[i] upgrades state from version i to i+1, so the version expected by
+// the code is always len(steps). A contract wires it up as:
+//
+//	var migrations = []migrate.Step{
+//		{Name: "split fee keys", Up: splitFeeKeys}, // v0 -> v1
+//	}
+//
+//	//go:wasmexport init
+//	func Init(payload *string) *string {
+//		...
+//		migrate.Init(migrations)
+//		return nil
+//	}
+//
+//	//go:wasmexport migrate
+//	func Migrate(_ *string) *string {
+//		migrate.Run(migrations)
+//		return nil
+//	}
+//
+// and calls migrate.RequireCurrent(migrations) at the top of entrypoints that
+// must never touch state in an older layout.
+//
+// State layout:
+//
+//	schema/version   decimal schema version
+package migrate
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"strconv"
+)
+
+const keyVersion = "schema/version"
+
+// Step upgrades state by exactly one version.
+type Step struct {
+	Name string
+	Up   func()
+}
+
+// Latest returns the schema version produced by applying every step.
+func Latest(steps []Step) uint64 {
+	return uint64(len(steps))
+}
+
+// Version returns the stored schema version, 0 when never recorded.
+func Version() uint64 {
+	v := sdk.StateGetObject(keyVersion)
+	if v == nil || *v == "" {
+		return 0
+	}
+	n, err := strconv.ParseUint(*v, 10, 64)
+	if err != nil {
+		sdk.Abort("migrate: bad schema version")
+	}
+	return n
+}
+
+func setVersion(v uint64) {
+	sdk.StateSetObject(keyVersion, strconv.FormatUint(v, 10))
+}
+
+// Init stamps freshly initialized state with the latest version. Fresh state
+// is already in the current layout, so no steps run.
+func Init(steps []Step) {
+	setVersion(Latest(steps))
+}
+
+// Pending reports whether stored state is older than the code.
+func Pending(steps []Step) bool {
+	return Version() < Latest(steps)
+}
+
+// RequireCurrent aborts unless state is at the version the code expects.
+func RequireCurrent(steps []Step) {
+	v := Version()
+	latest := Latest(steps)
+	if v < latest {
+		sdk.Abort("migrate: state is at v" + strconv.FormatUint(v, 10) + ", run migrate to v" + strconv.FormatUint(latest, 10))
+	}
+	if v > latest {
+		sdk.Abort("migrate: state is newer than code")
+	}
+}
+
+// Apply runs every pending step in order without an authorization check and
+// returns the resulting version. Run is the guarded entrypoint form; Apply is
+// for contracts with their own admin rules and for tests.
+func Apply(steps []Step) uint64 {
+	v := Version()
+	latest := Latest(steps)
+	if v > latest {
+		sdk.Abort("migrate: state is newer than code")
+	}
+	for ; v < latest; v++ {
+		steps[v].Up()
+	}
+	setVersion(v)
+	return v
+}
+
+// Run applies pending steps. Only consensus or the contract owner may migrate.
+func Run(steps []Step) uint64 {
+	if !access.IsSystem() && !access.IsOwner(sdk.GetEnv().Caller.Address) {
+		sdk.Abort("migrate: only owner or system can migrate")
+	}
+	return Apply(steps)
+}
@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"contract-template/sdk"
+	"testing"
+)
+
+func TestMigrate_RunsPendingStepsInOrder(t *testing.T) {
+	sdk.ShimReset()
+	var order []string
+	steps := []Step{
+		{Name: "a", Up: func() { order = append(order, "a") }},
+		{Name: "b", Up: func() { order = append(order, "b") }},
+		{Name: "c", Up: func() { order = append(order, "c") }},
+	}
+	// state already at v1: only b and c run
+	setVersion(1)
+	sdk.ShimSetSender("system:consensus")
+	if v := Run(steps); v != 3 {
+		t.Fatalf("version = %d, want 3", v)
+	}
+	if len(order) != 2 || order[0] != "b" || order[1] != "c" {
+		t.Fatalf("steps ran as %v", order)
+	}
+	RequireCurrent(steps)
+	if Run(steps) != 3 || len(order) != 2 {
+		t.Fatal("re-running must be a no-op")
+	}
+}
+
+func TestMigrate_Guards(t *testing.T) {
+	sdk.ShimReset()
+	steps := []Step{{Name: "a", Up: func() {}}}
+	expectAbort := func(want string, f func()) {
+		t.Helper()
+		defer func() {
+			if r := recover(); r != want {
+				t.Fatalf("abort = %v, want %q", r, want)
+			}
+		}()
+		f()
+	}
+	expectAbort("migrate: state is at v0, run migrate to v1", func() { RequireCurrent(steps) })
+	expectAbort("migrate: only owner or system can migrate", func() { Run(steps) })
+	setVersion(2)
+	expectAbort("migrate: state is newer than code", func() { RequireCurrent(steps) })
+	expectAbort("migrate: state is newer than code", func() { Apply(steps) })
+}
@@ -0,0 +1,332 @@
+// Package multitoken implements an ERC-1155 style contract holding many
+// fungible or semi-fungible token ids, with batch transfers, operator
+// approvals, per-id supply caps and mint roles.
+//
+// State layout:
+//
+//	mt/init                          "1" once Init has run
+//	mt/uri                           metadata URI template; "{id}" is replaced by the id
+//	ids/<id>/max_supply              supply cap, "0" for none; present once the id is created
+//	ids/<id>/supply                  units of id in existence
+//	accs/<address>/bal/<id>          units of id held by address
+//	accs/<address>/ops/<operator>    "1" while operator may move all of address's tokens
+//
+// Ids are created by the owner before minting. Minting is open to the
+// owner, holders of RoleMinter and holders of MinterRole(id) for that id.
+// Transfers and burns act for the immediate caller (sdk.Env.Caller), which
+// must be the holder or one of its operators.
+//
+// Events: "transfer_single" (operator, from, to, id, amount) and
+// "transfer_batch" (operator, from, to, ids, amounts as comma separated
+// lists), with from "" for mints and to "" for burns, and "approval_for_all"
+// (owner, operator, approved).
+package multitoken
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	keyInit      = "mt/init"
+	keyURI       = "mt/uri"
+	keyIdPrefix  = "ids/"  // ids/<id>/...
+	keyAccPrefix = "accs/" // accs/<address>/...
+)
+
+// RoleMinter may mint every id.
+const RoleMinter = "minter"
+
+// MaxBatch bounds the number of entries in one batch call.
+const MaxBatch = 100
+
+// MinterRole is the role allowed to mint only id.
+func MinterRole(id uint64) string {
+	return RoleMinter + ":" + u(id)
+}
+
+func getStr(key string) string {
+	v := sdk.StateGetObject(key)
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func getUint(key string) uint64 {
+	n, _ := strconv.ParseUint(getStr(key), 10, 64)
+	return n
+}
+
+func setUint(key string, v uint64) {
+	sdk.StateSetObject(key, strconv.FormatUint(v, 10))
+}
+
+func u(v uint64) string { return strconv.FormatUint(v, 10) }
+
+func idKey(id uint64, field string) string { return keyIdPrefix + u(id) + "/" + field }
+
+func balanceKey(addr sdk.Address, id uint64) string {
+	return keyAccPrefix + addr.String() + "/bal/" + u(id)
+}
+
+func opKey(owner, operator sdk.Address) string {
+	return keyAccPrefix + owner.String() + "/ops/" + operator.String()
+}
+
+func caller() sdk.Address {
+	return sdk.GetEnv().Caller.Address
+}
+
+func requireAddress(addr sdk.Address) {
+	if _, err := sdk.ParseAddress(addr.String()); err != nil {
+		sdk.Abort("multitoken: bad address " + addr.String())
+	}
+}
+
+func joinUints(v []uint64) string {
+	s := make([]string, len(v))
+	for i, x := range v {
+		s[i] = u(x)
+	}
+	return strings.Join(s, ",")
+}
+
+func IsInit() bool {
+	return getStr(keyInit) == "1"
+}
+
+// RequireInit aborts before Init.
+func RequireInit() {
+	if !IsInit() {
+		sdk.Abort("multitoken: not initialized")
+	}
+}
+
+// Init stores the metadata URI template and makes the caller the owner.
+func Init(uri string) {
+	if IsInit() {
+		sdk.Abort("multitoken: already initialized")
+	}
+	access.InitOwner(caller())
+	sdk.StateSetObject(keyInit, "1")
+	sdk.StateSetObject(keyURI, uri)
+}
+
+// URI returns the metadata URI of id.
+func URI(id uint64) string {
+	return strings.ReplaceAll(getStr(keyURI), "{id}", u(id))
+}
+
+// Exists reports whether id has been created.
+func Exists(id uint64) bool {
+	return getStr(idKey(id, "max_supply")) != ""
+}
+
+func requireExists(id uint64) {
+	if !Exists(id) {
+		sdk.Abort("multitoken: unknown id " + u(id))
+	}
+}
+
+// Create registers id with a supply cap (0 for none). Owner only; ids are
+// created once and their cap never changes.
+func Create(id uint64, maxSupply uint64) {
+	RequireInit()
+	access.RequireOwner()
+	if Exists(id) {
+		sdk.Abort("multitoken: id " + u(id) + " exists")
+	}
+	setUint(idKey(id, "max_supply"), maxSupply)
+	setUint(idKey(id, "supply"), 0)
+}
+
+func MaxSupply(id uint64) uint64 { return getUint(idKey(id, "max_supply")) }
+
+func Supply(id uint64) uint64 { return getUint(idKey(id, "supply")) }
+
+func BalanceOf(addr sdk.Address, id uint64) uint64 {
+	return getUint(balanceKey(addr, id))
+}
+
+// BalanceOfBatch returns BalanceOf(addrs[i], ids[i]) for every i.
+func BalanceOfBatch(addrs []sdk.Address, ids []uint64) []uint64 {
+	checkBatch(len(addrs), len(ids))
+	out := make([]uint64, len(ids))
+	for i := range ids {
+		out[i] = BalanceOf(addrs[i], ids[i])
+	}
+	return out
+}
+
+func IsApprovedForAll(owner, operator sdk.Address) bool {
+	return getStr(opKey(owner, operator)) == "1"
+}
+
+// SetApprovalForAll lets operator move all of the caller's tokens.
+func SetApprovalForAll(operator sdk.Address, approved bool) {
+	RequireInit()
+	requireAddress(operator)
+	owner := caller()
+	if operator == owner {
+		sdk.Abort("multitoken: approve to caller")
+	}
+	if approved {
+		sdk.StateSetObject(opKey(owner, operator), "1")
+	} else {
+		sdk.StateDeleteObject(opKey(owner, operator))
+	}
+	sdk.EmitEvent("approval_for_all", "owner", owner.String(), "operator", operator.String(), "approved", strconv.FormatBool(approved))
+}
+
+func checkBatch(a, b int) {
+	if a != b {
+		sdk.Abort("multitoken: length mismatch")
+	}
+	if a == 0 || a > MaxBatch {
+		sdk.Abort("multitoken: batch must have 1-" + strconv.Itoa(MaxBatch) + " entries")
+	}
+}
+
+func requireAmount(amount uint64) {
+	if amount == 0 {
+		sdk.Abort("multitoken: zero amount")
+	}
+}
+
+func credit(addr sdk.Address, id, amount uint64) {
+	bal := BalanceOf(addr, id)
+	if bal > math.MaxUint64-amount {
+		sdk.Abort("multitoken: balance overflow")
+	}
+	setUint(balanceKey(addr, id), bal+amount)
+}
+
+func debit(addr sdk.Address, id, amount uint64) {
+	bal := BalanceOf(addr, id)
+	if bal < amount {
+		sdk.Abort("multitoken: insufficient balance for id " + u(id))
+	}
+	if bal == amount {
+		sdk.StateDeleteObject(balanceKey(addr, id))
+		return
+	}
+	setUint(balanceKey(addr, id), bal-amount)
+}
+
+func requireHolderOrOperator(from sdk.Address) sdk.Address {
+	c := caller()
+	if c != from && !IsApprovedForAll(from, c) {
+		sdk.Abort("multitoken: caller is not holder nor operator")
+	}
+	return c
+}
+
+func canMint(c sdk.Address, id uint64) bool {
+	return access.IsOwner(c) || access.HasRole(RoleMinter, c) || access.HasRole(MinterRole(id), c)
+}
+
+func mint(c, to sdk.Address, id, amount uint64) {
+	requireExists(id)
+	if !canMint(c, id) {
+		sdk.Abort("multitoken: caller cannot mint id " + u(id))
+	}
+	requireAmount(amount)
+	supply := Supply(id)
+	if supply > math.MaxUint64-amount {
+		sdk.Abort("multitoken: supply overflow")
+	}
+	if limit := MaxSupply(id); limit != 0 && supply+amount > limit {
+		sdk.Abort("multitoken: max supply exceeded for id " + u(id))
+	}
+	setUint(idKey(id, "supply"), supply+amount)
+	credit(to, id, amount)
+}
+
+func burn(from sdk.Address, id, amount uint64) {
+	requireExists(id)
+	requireAmount(amount)
+	debit(from, id, amount)
+	setUint(idKey(id, "supply"), Supply(id)-amount)
+}
+
+func move(from, to sdk.Address, id, amount uint64) {
+	requireExists(id)
+	requireAmount(amount)
+	debit(from, id, amount)
+	credit(to, id, amount)
+}
+
+func emitSingle(op, from, to sdk.Address, id, amount uint64) {
+	sdk.EmitEvent("transfer_single", "operator", op.String(), "from", from.String(), "to", to.String(), "id", u(id), "amount", u(amount))
+}
+
+func emitBatch(op, from, to sdk.Address, ids, amounts []uint64) {
+	sdk.EmitEvent("transfer_batch", "operator", op.String(), "from", from.String(), "to", to.String(), "ids", joinUints(ids), "amounts", joinUints(amounts))
+}
+
+// Mint creates amount of id for to.
+func Mint(to sdk.Address, id, amount uint64) {
+	RequireInit()
+	requireAddress(to)
+	c := caller()
+	mint(c, to, id, amount)
+	emitSingle(c, "", to, id, amount)
+}
+
+// MintBatch mints amounts[i] of ids[i] to to.
+func MintBatch(to sdk.Address, ids, amounts []uint64) {
+	RequireInit()
+	requireAddress(to)
+	checkBatch(len(ids), len(amounts))
+	c := caller()
+	for i := range ids {
+		mint(c, to, ids[i], amounts[i])
+	}
+	emitBatch(c, "", to, ids, amounts)
+}
+
+// Burn destroys amount of from's id. The caller must be from or its operator.
+func Burn(from sdk.Address, id, amount uint64) {
+	RequireInit()
+	c := requireHolderOrOperator(from)
+	burn(from, id, amount)
+	emitSingle(c, from, "", id, amount)
+}
+
+// BurnBatch burns amounts[i] of from's ids[i].
+func BurnBatch(from sdk.Address, ids, amounts []uint64) {
+	RequireInit()
+	c := requireHolderOrOperator(from)
+	checkBatch(len(ids), len(amounts))
+	for i := range ids {
+		burn(from, ids[i], amounts[i])
+	}
+	emitBatch(c, from, "", ids, amounts)
+}
+
+// TransferFrom moves amount of id from from to to. The caller must be from
+// or its operator.
+func TransferFrom(from, to sdk.Address, id, amount uint64) {
+	RequireInit()
+	c := requireHolderOrOperator(from)
+	requireAddress(to)
+	move(from, to, id, amount)
+	emitSingle(c, from, to, id, amount)
+}
+
+// BatchTransferFrom moves amounts[i] of ids[i] from from to to. Either every
+// entry moves or the call aborts.
+func BatchTransferFrom(from, to sdk.Address, ids, amounts []uint64) {
+	RequireInit()
+	c := requireHolderOrOperator(from)
+	requireAddress(to)
+	checkBatch(len(ids), len(amounts))
+	for i := range ids {
+		move(from, to, ids[i], amounts[i])
+	}
+	emitBatch(c, from, to, ids, amounts)
+}
@@ -0,0 +1,150 @@
+package multitoken
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"testing"
+)
+
+func expectAbort(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected abort %q", want)
+		}
+		if msg, _ := r.(string); msg != want {
+			t.Fatalf("abort = %q, want %q", msg, want)
+		}
+	}()
+	f()
+}
+
+func setup() {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:items")
+	sdk.ShimSetSender("hive:owner")
+	Init("ipfs://bafy/{id}.json")
+	Create(1, 0)  // fungible gold
+	Create(2, 10) // limited sword
+	Create(3, 1)  // unique crown
+}
+
+func TestMultiToken_InitAndCreate(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	expectAbort(t, "multitoken: not initialized", func() { Create(1, 0) })
+	Init("")
+	expectAbort(t, "multitoken: already initialized", func() { Init("x") })
+	Create(7, 5)
+	expectAbort(t, "multitoken: id 7 exists", func() { Create(7, 9) })
+	if !Exists(7) || Exists(8) || MaxSupply(7) != 5 || Supply(7) != 0 {
+		t.Fatal("create state")
+	}
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "access: caller is not the owner", func() { Create(8, 0) })
+
+	setup()
+	if URI(42) != "ipfs://bafy/42.json" {
+		t.Fatalf("uri = %s", URI(42))
+	}
+}
+
+func TestMultiToken_MintRolesAndCaps(t *testing.T) {
+	setup()
+	Mint("hive:alice", 1, 1_000_000)
+	MintBatch("hive:alice", []uint64{2, 3}, []uint64{10, 1})
+	if Supply(1) != 1_000_000 || Supply(2) != 10 || BalanceOf("hive:alice", 3) != 1 {
+		t.Fatal("mint")
+	}
+	expectAbort(t, "multitoken: max supply exceeded for id 2", func() { Mint("hive:bob", 2, 1) })
+	expectAbort(t, "multitoken: max supply exceeded for id 3", func() { MintBatch("hive:bob", []uint64{1, 3}, []uint64{5, 1}) })
+	expectAbort(t, "multitoken: unknown id 9", func() { Mint("hive:bob", 9, 1) })
+	expectAbort(t, "multitoken: zero amount", func() { Mint("hive:bob", 1, 0) })
+
+	// per-id minters only mint their id; global minters mint any
+	access.GrantRole(MinterRole(1), "contract:faucet")
+	access.GrantRole(RoleMinter, "hive:admin")
+	sdk.ShimSetCaller("contract:faucet")
+	Mint("hive:bob", 1, 5)
+	expectAbort(t, "multitoken: caller cannot mint id 2", func() { Mint("hive:bob", 2, 1) })
+	// the owner's signature does not let another contract create ids
+	expectAbort(t, "access: caller is not the owner", func() { Create(4, 0) })
+	sdk.ShimSetCaller("")
+	Create(4, 0)
+	sdk.ShimSetSender("hive:admin")
+	Mint("hive:bob", 4, 1)
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "multitoken: caller cannot mint id 1", func() { Mint("hive:mallory", 1, 1) })
+}
+
+func TestMultiToken_TransfersAndBatches(t *testing.T) {
+	setup()
+	MintBatch("hive:alice", []uint64{1, 2}, []uint64{100, 5})
+
+	sdk.ShimSetSender("hive:alice")
+	TransferFrom("hive:alice", "hive:bob", 1, 40)
+	BatchTransferFrom("hive:alice", "hive:bob", []uint64{1, 2}, []uint64{10, 5})
+	got := BalanceOfBatch([]sdk.Address{"hive:alice", "hive:alice", "hive:bob", "hive:bob"}, []uint64{1, 2, 1, 2})
+	if got[0] != 50 || got[1] != 0 || got[2] != 50 || got[3] != 5 {
+		t.Fatalf("balances = %v", got)
+	}
+	if *sdk.StateGetObject("accs/hive:alice/bal/2") != "" {
+		t.Fatal("empty balance not cleared")
+	}
+
+	// batches are all or nothing: the host reverts the whole call on abort
+	restore := sdk.ShimSnapshot()
+	expectAbort(t, "multitoken: insufficient balance for id 2", func() {
+		BatchTransferFrom("hive:alice", "hive:carol", []uint64{1, 2}, []uint64{1, 1})
+	})
+	restore()
+	expectAbort(t, "multitoken: length mismatch", func() { BatchTransferFrom("hive:alice", "hive:bob", []uint64{1}, nil) })
+	expectAbort(t, "multitoken: batch must have 1-100 entries", func() { BalanceOfBatch(nil, nil) })
+	expectAbort(t, "multitoken: bad address bob", func() { TransferFrom("hive:alice", "bob", 1, 1) })
+
+	// operators act for the holder; others cannot
+	sdk.ShimSetSender("hive:bob")
+	expectAbort(t, "multitoken: caller is not holder nor operator", func() { TransferFrom("hive:alice", "hive:bob", 1, 1) })
+	sdk.ShimSetSender("hive:alice")
+	expectAbort(t, "multitoken: approve to caller", func() { SetApprovalForAll("hive:alice", true) })
+	SetApprovalForAll("contract:market", true)
+	sdk.ShimSetCaller("contract:market")
+	TransferFrom("hive:alice", "hive:carol", 1, 20)
+	BurnBatch("hive:alice", []uint64{1}, []uint64{30})
+	if BalanceOf("hive:alice", 1) != 0 || BalanceOf("hive:carol", 1) != 20 || Supply(1) != 70 {
+		t.Fatal("operator transfer and burn")
+	}
+	sdk.ShimSetCaller("")
+	SetApprovalForAll("contract:market", false)
+	sdk.ShimSetCaller("contract:market")
+	expectAbort(t, "multitoken: caller is not holder nor operator", func() { Burn("hive:alice", 1, 1) })
+
+	// burned capped supply can be minted again
+	sdk.ShimSetCaller("")
+	sdk.ShimSetSender("hive:bob")
+	Burn("hive:bob", 2, 5)
+	sdk.ShimSetSender("hive:owner")
+	Mint("hive:dave", 2, 5)
+}
+
+func TestMultiToken_Events(t *testing.T) {
+	setup()
+	Mint("hive:alice", 1, 10)
+	sdk.ShimSetSender("hive:alice")
+	BatchTransferFrom("hive:alice", "hive:bob", []uint64{1}, []uint64{4})
+	Burn("hive:alice", 1, 6)
+	ev := sdk.ShimEvents()
+	if len(ev) != 3 {
+		t.Fatalf("events = %+v", ev)
+	}
+	if ev[0].Type != "transfer_single" || ev[0].Attrs["from"] != "" || ev[0].Attrs["to"] != "hive:alice" || ev[0].Attrs["amount"] != "10" {
+		t.Fatalf("mint event = %+v", ev[0])
+	}
+	if ev[1].Type != "transfer_batch" || ev[1].Attrs["ids"] != "1" || ev[1].Attrs["amounts"] != "4" || ev[1].Attrs["operator"] != "hive:alice" {
+		t.Fatalf("batch event = %+v", ev[1])
+	}
+	if ev[2].Attrs["to"] != "" || ev[2].Attrs["id"] != "1" {
+		t.Fatalf("burn event = %+v", ev[2])
+	}
+}
@@ -0,0 +1,318 @@
+// Package nft implements non-fungible tokens: minting with a metadata URI,
+// transfers, per-token and operator approvals, burning and enumeration.
+//
+// Token ids are assigned sequentially from 1 in mint order, so replaying the
+// same transactions always yields the same ids. Burned ids are never reused.
+//
+// State layout:
+//
+//	nft/name                      collection name
+//	nft/symbol                    collection symbol; set once by Init
+//	nft/last_id                   last id minted
+//	nft/supply                    tokens in existence
+//	nft/all/<index>               id at position index of all tokens
+//	nft/tok/<id>/owner            owner address
+//	nft/tok/<id>/uri              metadata URI
+//	nft/tok/<id>/approved         address approved for this token only
+//	nft/tok/<id>/all_index        position in nft/all
+//	nft/tok/<id>/owner_index      position in the owner's list
+//	nft/own/<address>/count       tokens held by address
+//	nft/own/<address>/<index>     id at position index of address's tokens
+//	nft/ops/<owner>/<operator>    "1" while operator may move all owner's tokens
+//
+// Enumeration lists are kept dense by moving the last entry into the slot
+// of a removed one, so their order changes on transfer and burn.
+//
+// Operations act for the immediate caller (sdk.Env.Caller). Minting is
+// limited to the access owner and holders of RoleMinter.
+//
+// Events: "mint" (to, id, uri), "transfer" (from, to, id), "burn" (from, id),
+// "approval" (owner, approved, id) and "approval_for_all" (owner, operator,
+// approved).
+package nft
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"strconv"
+)
+
+const (
+	keyName   = "nft/name"
+	keySymbol = "nft/symbol"
+	keyLastId = "nft/last_id"
+	keySupply = "nft/supply"
+	keyAll    = "nft/all/" // nft/all/<index>
+	keyTok    = "nft/tok/" // nft/tok/<id>/...
+	keyOwn    = "nft/own/" // nft/own/<address>/...
+	keyOps    = "nft/ops/" // nft/ops/<owner>/<operator>
+)
+
+// RoleMinter may mint in addition to the owner.
+const RoleMinter = "minter"
+
+// MaxURILength bounds the metadata URI stored per token.
+const MaxURILength = 512
+
+type Config struct {
+	Name   string
+	Symbol string
+}
+
+func getStr(key string) string {
+	v := sdk.StateGetObject(key)
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func getUint(key string) uint64 {
+	n, _ := strconv.ParseUint(getStr(key), 10, 64)
+	return n
+}
+
+func setUint(key string, v uint64) {
+	sdk.StateSetObject(key, strconv.FormatUint(v, 10))
+}
+
+func u(v uint64) string { return strconv.FormatUint(v, 10) }
+
+func tokKey(id uint64, field string) string { return keyTok + u(id) + "/" + field }
+
+func ownKey(addr sdk.Address, field string) string { return keyOwn + addr.String() + "/" + field }
+
+func opKey(owner, operator sdk.Address) string {
+	return keyOps + owner.String() + "/" + operator.String()
+}
+
+func caller() sdk.Address {
+	return sdk.GetEnv().Caller.Address
+}
+
+func requireAddress(addr sdk.Address) {
+	if _, err := sdk.ParseAddress(addr.String()); err != nil {
+		sdk.Abort("nft: bad address " + addr.String())
+	}
+}
+
+func IsInit() bool {
+	return getStr(keySymbol) != ""
+}
+
+// RequireInit aborts before Init.
+func RequireInit() {
+	if !IsInit() {
+		sdk.Abort("nft: not initialized")
+	}
+}
+
+// Init stores the collection config and makes the caller the owner.
+func Init(cfg Config) {
+	if IsInit() {
+		sdk.Abort("nft: already initialized")
+	}
+	if cfg.Symbol == "" || len(cfg.Symbol) > 16 {
+		sdk.Abort("nft: symbol must be 1-16 characters")
+	}
+	access.InitOwner(caller())
+	sdk.StateSetObject(keyName, cfg.Name)
+	sdk.StateSetObject(keySymbol, cfg.Symbol)
+	setUint(keyLastId, 0)
+	setUint(keySupply, 0)
+}
+
+func GetConfig() Config {
+	return Config{Name: getStr(keyName), Symbol: getStr(keySymbol)}
+}
+
+// TotalSupply is the number of tokens in existence.
+func TotalSupply() uint64 { return getUint(keySupply) }
+
+// Exists reports whether id is minted and not burned.
+func Exists(id uint64) bool {
+	return getStr(tokKey(id, "owner")) != ""
+}
+
+// OwnerOf returns the owner of id. It aborts if id does not exist.
+func OwnerOf(id uint64) sdk.Address {
+	owner := getStr(tokKey(id, "owner"))
+	if owner == "" {
+		sdk.Abort("nft: unknown token " + u(id))
+	}
+	return sdk.Address(owner)
+}
+
+// TokenURI returns the metadata URI of id.
+func TokenURI(id uint64) string {
+	OwnerOf(id)
+	return getStr(tokKey(id, "uri"))
+}
+
+// GetApproved returns the address approved for id, or "".
+func GetApproved(id uint64) sdk.Address {
+	OwnerOf(id)
+	return sdk.Address(getStr(tokKey(id, "approved")))
+}
+
+func IsApprovedForAll(owner, operator sdk.Address) bool {
+	return getStr(opKey(owner, operator)) == "1"
+}
+
+// BalanceOf is the number of tokens held by addr.
+func BalanceOf(addr sdk.Address) uint64 {
+	return getUint(ownKey(addr, "count"))
+}
+
+// TokenOfOwnerByIndex returns the id at position index of owner's tokens.
+func TokenOfOwnerByIndex(owner sdk.Address, index uint64) uint64 {
+	if index >= BalanceOf(owner) {
+		sdk.Abort("nft: owner index out of range")
+	}
+	return getUint(ownKey(owner, u(index)))
+}
+
+// TokenByIndex returns the id at position index of all tokens.
+func TokenByIndex(index uint64) uint64 {
+	if index >= TotalSupply() {
+		sdk.Abort("nft: index out of range")
+	}
+	return getUint(keyAll + u(index))
+}
+
+func isAuthorized(spender sdk.Address, id uint64) bool {
+	owner := OwnerOf(id)
+	return spender == owner || IsApprovedForAll(owner, spender) || GetApproved(id) == spender
+}
+
+func addToOwner(owner sdk.Address, id uint64) {
+	n := BalanceOf(owner)
+	setUint(ownKey(owner, u(n)), id)
+	setUint(tokKey(id, "owner_index"), n)
+	setUint(ownKey(owner, "count"), n+1)
+	sdk.StateSetObject(tokKey(id, "owner"), owner.String())
+}
+
+func removeFromOwner(owner sdk.Address, id uint64) {
+	last := BalanceOf(owner) - 1
+	idx := getUint(tokKey(id, "owner_index"))
+	if idx != last {
+		moved := getUint(ownKey(owner, u(last)))
+		setUint(ownKey(owner, u(idx)), moved)
+		setUint(tokKey(moved, "owner_index"), idx)
+	}
+	sdk.StateDeleteObject(ownKey(owner, u(last)))
+	if last == 0 {
+		sdk.StateDeleteObject(ownKey(owner, "count"))
+	} else {
+		setUint(ownKey(owner, "count"), last)
+	}
+	sdk.StateDeleteObject(tokKey(id, "approved"))
+}
+
+// Mint creates the next token for to and returns its id. Owner or
+// RoleMinter only.
+func Mint(to sdk.Address, uri string) uint64 {
+	RequireInit()
+	if c := caller(); !access.IsOwner(c) && !access.HasRole(RoleMinter, c) {
+		sdk.Abort("nft: caller cannot mint")
+	}
+	requireAddress(to)
+	if len(uri) > MaxURILength {
+		sdk.Abort("nft: uri too long")
+	}
+	id := getUint(keyLastId) + 1
+	setUint(keyLastId, id)
+	supply := TotalSupply()
+	setUint(keyAll+u(supply), id)
+	setUint(tokKey(id, "all_index"), supply)
+	setUint(keySupply, supply+1)
+	if uri != "" {
+		sdk.StateSetObject(tokKey(id, "uri"), uri)
+	}
+	addToOwner(to, id)
+	sdk.EmitEvent("mint", "to", to.String(), "id", u(id), "uri", uri)
+	return id
+}
+
+// TransferFrom moves id from from to to. The caller must be the owner, the
+// token's approved address or an operator of the owner. The per-token
+// approval is cleared.
+func TransferFrom(from, to sdk.Address, id uint64) {
+	RequireInit()
+	if OwnerOf(id) != from {
+		sdk.Abort("nft: from is not the owner")
+	}
+	if !isAuthorized(caller(), id) {
+		sdk.Abort("nft: caller is not owner nor approved")
+	}
+	requireAddress(to)
+	removeFromOwner(from, id)
+	addToOwner(to, id)
+	sdk.EmitEvent("transfer", "from", from.String(), "to", to.String(), "id", u(id))
+}
+
+// Transfer moves the caller's token id to to.
+func Transfer(to sdk.Address, id uint64) {
+	TransferFrom(caller(), to, id)
+}
+
+// Burn destroys id. Same authorization as TransferFrom.
+func Burn(id uint64) {
+	RequireInit()
+	owner := OwnerOf(id)
+	if !isAuthorized(caller(), id) {
+		sdk.Abort("nft: caller is not owner nor approved")
+	}
+	removeFromOwner(owner, id)
+
+	last := TotalSupply() - 1
+	idx := getUint(tokKey(id, "all_index"))
+	if idx != last {
+		moved := getUint(keyAll + u(last))
+		setUint(keyAll+u(idx), moved)
+		setUint(tokKey(moved, "all_index"), idx)
+	}
+	sdk.StateDeleteObject(keyAll + u(last))
+	setUint(keySupply, last)
+	for _, f := range []string{"owner", "uri", "all_index", "owner_index"} {
+		sdk.StateDeleteObject(tokKey(id, f))
+	}
+	sdk.EmitEvent("burn", "from", owner.String(), "id", u(id))
+}
+
+// Approve lets approved move id until the next transfer. "" clears it. The
+// caller must be the owner or one of its operators.
+func Approve(approved sdk.Address, id uint64) {
+	RequireInit()
+	owner := OwnerOf(id)
+	if c := caller(); c != owner && !IsApprovedForAll(owner, c) {
+		sdk.Abort("nft: caller is not owner nor operator")
+	}
+	if approved == "" {
+		sdk.StateDeleteObject(tokKey(id, "approved"))
+	} else {
+		requireAddress(approved)
+		if approved == owner {
+			sdk.Abort("nft: approval to current owner")
+		}
+		sdk.StateSetObject(tokKey(id, "approved"), approved.String())
+	}
+	sdk.EmitEvent("approval", "owner", owner.String(), "approved", approved.String(), "id", u(id))
+}
+
+// SetApprovalForAll lets operator move all of the caller's tokens.
+func SetApprovalForAll(operator sdk.Address, approved bool) {
+	RequireInit()
+	requireAddress(operator)
+	owner := caller()
+	if operator == owner {
+		sdk.Abort("nft: approve to caller")
+	}
+	if approved {
+		sdk.StateSetObject(opKey(owner, operator), "1")
+	} else {
+		sdk.StateDeleteObject(opKey(owner, operator))
+	}
+	sdk.EmitEvent("approval_for_all", "owner", owner.String(), "operator", operator.String(), "approved", strconv.FormatBool(approved))
+}
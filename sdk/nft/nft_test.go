@@ -0,0 +1,207 @@
+package nft
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"testing"
+)
+
+func expectAbort(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected abort %q", want)
+		}
+		if msg, _ := r.(string); msg != want {
+			t.Fatalf("abort = %q, want %q", msg, want)
+		}
+	}()
+	f()
+}
+
+func setup() {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:nft")
+	sdk.ShimSetSender("hive:owner")
+	Init(Config{Name: "Cards", Symbol: "CARD"})
+}
+
+// owned returns owner's ids in enumeration order.
+func owned(owner sdk.Address) []uint64 {
+	var ids []uint64
+	for i := uint64(0); i < BalanceOf(owner); i++ {
+		ids = append(ids, TokenOfOwnerByIndex(owner, i))
+	}
+	return ids
+}
+
+func all() []uint64 {
+	var ids []uint64
+	for i := uint64(0); i < TotalSupply(); i++ {
+		ids = append(ids, TokenByIndex(i))
+	}
+	return ids
+}
+
+func equal(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNFT_InitAndMint(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	expectAbort(t, "nft: not initialized", func() { Mint("hive:alice", "") })
+	expectAbort(t, "nft: symbol must be 1-16 characters", func() { Init(Config{Name: "x"}) })
+	Init(Config{Name: "Cards", Symbol: "CARD"})
+	expectAbort(t, "nft: already initialized", func() { Init(Config{Symbol: "CARD"}) })
+	if GetConfig() != (Config{Name: "Cards", Symbol: "CARD"}) || access.Owner() != "hive:owner" {
+		t.Fatal("config")
+	}
+
+	// ids are sequential and deterministic
+	if Mint("hive:alice", "ipfs://a") != 1 || Mint("hive:alice", "ipfs://b") != 2 || Mint("hive:bob", "") != 3 {
+		t.Fatal("ids not sequential")
+	}
+	if OwnerOf(1) != "hive:alice" || TokenURI(2) != "ipfs://b" || TokenURI(3) != "" || TotalSupply() != 3 {
+		t.Fatal("mint state")
+	}
+	expectAbort(t, "nft: unknown token 4", func() { OwnerOf(4) })
+	expectAbort(t, "nft: bad address bob", func() { Mint("bob", "") })
+	expectAbort(t, "nft: uri too long", func() { Mint("hive:bob", string(make([]byte, MaxURILength+1))) })
+
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "nft: caller cannot mint", func() { Mint("hive:mallory", "") })
+	sdk.ShimSetSender("hive:owner")
+	access.GrantRole(RoleMinter, "hive:minter")
+	sdk.ShimSetSender("hive:minter")
+	if Mint("hive:carol", "") != 4 {
+		t.Fatal("minter mint")
+	}
+}
+
+func TestNFT_TransferAndApprovals(t *testing.T) {
+	setup()
+	Mint("hive:alice", "")
+	Mint("hive:alice", "")
+
+	sdk.ShimSetSender("hive:bob")
+	expectAbort(t, "nft: caller is not owner nor approved", func() { TransferFrom("hive:alice", "hive:bob", 1) })
+	expectAbort(t, "nft: caller is not owner nor operator", func() { Approve("hive:bob", 1) })
+
+	sdk.ShimSetSender("hive:alice")
+	expectAbort(t, "nft: from is not the owner", func() { TransferFrom("hive:bob", "hive:carol", 1) })
+	expectAbort(t, "nft: approval to current owner", func() { Approve("hive:alice", 1) })
+	Approve("hive:bob", 1)
+	if GetApproved(1) != "hive:bob" {
+		t.Fatal("approval not stored")
+	}
+
+	// the approved address moves the token once; the approval is cleared
+	sdk.ShimSetSender("hive:bob")
+	TransferFrom("hive:alice", "hive:carol", 1)
+	if OwnerOf(1) != "hive:carol" || GetApproved(1) != "" {
+		t.Fatal("approved transfer")
+	}
+	expectAbort(t, "nft: caller is not owner nor approved", func() { TransferFrom("hive:carol", "hive:bob", 1) })
+
+	// operators move every token and may approve others
+	sdk.ShimSetSender("hive:alice")
+	expectAbort(t, "nft: approve to caller", func() { SetApprovalForAll("hive:alice", true) })
+	SetApprovalForAll("contract:market", true)
+	sdk.ShimSetCaller("contract:market")
+	Approve("hive:dave", 2)
+	TransferFrom("hive:alice", "hive:erin", 2)
+	if OwnerOf(2) != "hive:erin" || GetApproved(2) != "" {
+		t.Fatal("operator transfer")
+	}
+	sdk.ShimSetCaller("")
+	SetApprovalForAll("contract:market", false)
+	if IsApprovedForAll("hive:alice", "contract:market") {
+		t.Fatal("operator not revoked")
+	}
+
+	// a contract acting for carol's transaction is not carol
+	sdk.ShimSetSender("hive:carol")
+	sdk.ShimSetCaller("contract:proxy")
+	expectAbort(t, "nft: from is not the owner", func() { Transfer("hive:bob", 1) })
+	sdk.ShimSetCaller("")
+	Transfer("hive:bob", 1)
+	if OwnerOf(1) != "hive:bob" {
+		t.Fatal("owner transfer")
+	}
+}
+
+func TestNFT_EnumerationAndBurn(t *testing.T) {
+	setup()
+	for i := 0; i < 4; i++ {
+		Mint("hive:alice", "")
+	}
+	Mint("hive:bob", "")
+	if !equal(owned("hive:alice"), []uint64{1, 2, 3, 4}) || !equal(all(), []uint64{1, 2, 3, 4, 5}) {
+		t.Fatal("enumeration after mint")
+	}
+
+	sdk.ShimSetSender("hive:alice")
+	Transfer("hive:bob", 2)
+	if !equal(owned("hive:alice"), []uint64{1, 4, 3}) || !equal(owned("hive:bob"), []uint64{5, 2}) {
+		t.Fatalf("enumeration after transfer: %v %v", owned("hive:alice"), owned("hive:bob"))
+	}
+	expectAbort(t, "nft: owner index out of range", func() { TokenOfOwnerByIndex("hive:alice", 3) })
+
+	Burn(1)
+	expectAbort(t, "nft: caller is not owner nor approved", func() { Burn(5) })
+	if Exists(1) || TotalSupply() != 4 || !equal(all(), []uint64{5, 2, 3, 4}) || !equal(owned("hive:alice"), []uint64{3, 4}) {
+		t.Fatalf("after burn: all %v alice %v", all(), owned("hive:alice"))
+	}
+	expectAbort(t, "nft: index out of range", func() { TokenByIndex(4) })
+
+	// burned ids are not reused
+	sdk.ShimSetSender("hive:owner")
+	if Mint("hive:carol", "") != 6 {
+		t.Fatal("id reused")
+	}
+
+	// burning everything leaves no enumeration keys behind
+	sdk.ShimSetSender("hive:alice")
+	Burn(3)
+	Burn(4)
+	if BalanceOf("hive:alice") != 0 || *sdk.StateGetObject("nft/own/hive:alice/count") != "" || *sdk.StateGetObject("nft/tok/3/owner") != "" {
+		t.Fatal("state not cleaned up")
+	}
+}
+
+func TestNFT_Events(t *testing.T) {
+	setup()
+	Mint("hive:alice", "ipfs://a")
+	sdk.ShimSetSender("hive:alice")
+	Approve("hive:bob", 1)
+	SetApprovalForAll("hive:carol", true)
+	Transfer("hive:bob", 1)
+	sdk.ShimSetSender("hive:bob")
+	Burn(1)
+	var types []string
+	for _, e := range sdk.ShimEvents() {
+		types = append(types, e.Type)
+	}
+	want := []string{"mint", "approval", "approval_for_all", "transfer", "burn"}
+	if len(types) != len(want) {
+		t.Fatalf("events = %v", types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("events = %v", types)
+		}
+	}
+	if e := sdk.ShimEvents()[3]; e.Attrs["from"] != "hive:alice" || e.Attrs["to"] != "hive:bob" || e.Attrs["id"] != "1" {
+		t.Fatalf("transfer event = %+v", e)
+	}
+}
@@ -0,0 +1,96 @@
+// Package pause provides per-entrypoint pause flags with an emergency pause
+// that guardians can trigger and only consensus can lift.
+//
+// State layout:
+//
+//	pause/all           "1" while every entrypoint is paused
+//	pause/ep/<name>     "1" while entrypoint <name> is paused
+//
+// Guardians are stored as the access "guardian" role. The pause switch is
+// deliberately asymmetric: anyone trusted to stop the contract in an emergency
+// must not also be able to restart it.
+package pause
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+)
+
+const (
+	keyAll      = "pause/all"
+	keyEpPrefix = "pause/ep/" // pause/ep/<entrypoint>
+)
+
+// RoleGuardian may trigger Pause.
+const RoleGuardian = "guardian"
+
+// All selects the contract-wide flag instead of a single entrypoint.
+const All = ""
+
+func flagKey(entrypoint string) string {
+	if entrypoint == All {
+		return keyAll
+	}
+	return keyEpPrefix + entrypoint
+}
+
+func isSet(key string) bool {
+	v := sdk.StateGetObject(key)
+	return v != nil && *v == "1"
+}
+
+// IsPaused reports whether entrypoint is paused individually or contract-wide.
+func IsPaused(entrypoint string) bool {
+	if isSet(keyAll) {
+		return true
+	}
+	return entrypoint != All && isSet(flagKey(entrypoint))
+}
+
+// RequireNotPaused aborts if entrypoint cannot run.
+func RequireNotPaused(entrypoint string) {
+	if IsPaused(entrypoint) {
+		if entrypoint == All {
+			sdk.Abort("pause: contract is paused")
+		}
+		sdk.Abort("pause: " + entrypoint + " is paused")
+	}
+}
+
+func IsGuardian(addr sdk.Address) bool {
+	return access.HasRole(RoleGuardian, addr)
+}
+
+// Pause stops entrypoint, or the whole contract for All. Guardians, the owner
+// and consensus may pause.
+func Pause(entrypoint string) {
+	caller := sdk.GetEnv().Caller.Address
+	if !IsGuardian(caller) && !access.IsOwner(caller) && !access.IsSystem() {
+		sdk.Abort("pause: caller is not a guardian")
+	}
+	sdk.StateSetObject(flagKey(entrypoint), "1")
+}
+
+// SetPaused sets or clears a pause flag. Consensus-only, since it can unpause.
+func SetPaused(entrypoint string, paused bool) {
+	access.RequireSystem()
+	SetFlag(entrypoint, paused)
+}
+
+// SetFlag sets or clears a pause flag without any authorization check, e.g.
+// from a state migration. Callers must authorize first.
+func SetFlag(entrypoint string, paused bool) {
+	if paused {
+		sdk.StateSetObject(flagKey(entrypoint), "1")
+	} else {
+		sdk.StateDeleteObject(flagKey(entrypoint))
+	}
+}
+
+// SetGuardian adds or removes a guardian. The owner or consensus may call it.
+func SetGuardian(addr sdk.Address, enabled bool) {
+	if !access.IsOwner(sdk.GetEnv().Caller.Address) && !access.IsSystem() {
+		sdk.Abort("pause: only owner or system can set guardians")
+	}
+	access.SetRole(RoleGuardian, addr, enabled)
+}
@@ -0,0 +1,62 @@
+package pause
+
+import (
+	"contract-template/sdk"
+	"contract-template/sdk/access"
+	"testing"
+)
+
+func expectAbort(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected abort %q", want)
+		}
+		if msg, _ := r.(string); msg != want {
+			t.Fatalf("abort = %q, want %q", msg, want)
+		}
+	}()
+	f()
+}
+
+func TestPause_GuardianPausesSystemUnpauses(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("hive:owner")
+	access.InitOwner("hive:owner")
+	SetGuardian("hive:guard", true)
+
+	sdk.ShimSetSender("hive:mallory")
+	expectAbort(t, "pause: caller is not a guardian", func() { Pause("swap") })
+	expectAbort(t, "pause: only owner or system can set guardians", func() { SetGuardian("hive:mallory", true) })
+
+	sdk.ShimSetSender("hive:guard")
+	Pause("swap")
+	expectAbort(t, "pause: swap is paused", func() { RequireNotPaused("swap") })
+	RequireNotPaused("add_liquidity")
+	// guardians cannot lift a pause
+	expectAbort(t, "access: system only", func() { SetPaused("swap", false) })
+
+	Pause(All)
+	expectAbort(t, "pause: add_liquidity is paused", func() { RequireNotPaused("add_liquidity") })
+	expectAbort(t, "pause: contract is paused", func() { RequireNotPaused(All) })
+
+	sdk.ShimSetSender("system:consensus")
+	SetPaused(All, false)
+	RequireNotPaused("add_liquidity")
+	if !IsPaused("swap") {
+		t.Fatal("entrypoint flag must survive lifting the global flag")
+	}
+	SetPaused("swap", false)
+	RequireNotPaused("swap")
+}
+
+func TestPause_GuardianIsCallerScoped(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetSender("system:consensus")
+	SetGuardian("hive:guard", true)
+	// a contract invoked by the guardian's transaction is not a guardian
+	sdk.ShimSetSender("hive:guard")
+	sdk.ShimSetCaller("contract:other")
+	expectAbort(t, "pause: caller is not a guardian", func() { Pause(All) })
+}
@@ -0,0 +1,66 @@
+// Package reentrancy provides a state-backed lock that stops a contract from
+// being re-entered while it is calling out to another contract.
+//
+// Contracts are built with -panic=trap and without defer, so a lock cannot be
+// released on the way out of a failing call. It does not need to be: an abort
+// reverts every state write of the transaction, including the lock. What must
+// not happen is a lock left behind by a code path that returned without
+// calling Exit permanently bricking the contract. The lock therefore records
+// the transaction and operation that took it and only blocks callers from
+// that same operation, i.e. genuine re-entry through contracts.call.
+//
+// State layout:
+//
+//	reentrancy/<name>   "<tx id>/<op index>" while held
+package reentrancy
+
+import (
+	"contract-template/sdk"
+	"strconv"
+)
+
+const keyPrefix = "reentrancy/" // reentrancy/<name>
+
+func lockKey(name string) string {
+	return keyPrefix + name
+}
+
+func owner() string {
+	env := sdk.GetEnv()
+	return env.TxId + "/" + strconv.FormatUint(env.OpIndex, 10)
+}
+
+// Locked reports whether name is held by the current operation.
+func Locked(name string) bool {
+	v := sdk.StateGetObject(lockKey(name))
+	return v != nil && *v != "" && *v == owner()
+}
+
+// RequireUnlocked aborts if name is held by the current operation. Use it on
+// entrypoints that must not run during a callback but do not call out.
+func RequireUnlocked(name string) {
+	if Locked(name) {
+		sdk.Abort("reentrancy: " + name + " is locked")
+	}
+}
+
+// Enter takes the lock or aborts if the current operation already holds it.
+// Every return path after Enter must call Exit.
+func Enter(name string) {
+	RequireUnlocked(name)
+	sdk.StateSetObject(lockKey(name), owner())
+}
+
+// Exit releases the lock.
+func Exit(name string) {
+	sdk.StateDeleteObject(lockKey(name))
+}
+
+// Guard runs fn while holding name and returns its result. It is the
+// defer-free equivalent of Enter followed by a deferred Exit.
+func Guard(name string, fn func() *string) *string {
+	Enter(name)
+	res := fn()
+	Exit(name)
+	return res
+}
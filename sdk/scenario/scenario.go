@@ -0,0 +1,205 @@
+//go:build !gc.custom
+
+// Package scenario replays a JSON list of contract calls against the host
+// shim and checks returns, aborts, state and balances after each step.
+//
+//	{
+//	  "contract_id": "contract:token",
+//	  "balances": {"hive:alice": {"hbd": 1000}},
+//	  "steps": [
+//	    {"name": "init", "sender": "hive:alice", "action": "init"},
+//	    {"name": "mint", "action": "mint", "payload": "1000",
+//	     "state": {"supply": "1000"}},
+//	    {"name": "bob cannot mint", "sender": "hive:bob", "action": "mint",
+//	     "payload": "1", "abort": "not the owner"}
+//	  ]
+//	}
+//
+// Sender, caller and required_auths carry over to later steps until changed.
+// A step with "abort" must panic with a message containing that text; its
+// effects are reverted like on chain. "returns" compares the returned string,
+// with null meaning a nil return. "state" and "balances" compare only the
+// listed keys; an empty string expects the key to be absent.
+package scenario
+
+import (
+	"contract-template/sdk"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type Scenario struct {
+	ContractId string                      `json:"contract_id"`
+	State      map[string]string           `json:"state,omitempty"`
+	Balances   map[string]map[string]int64 `json:"balances,omitempty"`
+	Steps      []Step                      `json:"steps"`
+}
+
+type Step struct {
+	Name          string                      `json:"name,omitempty"`
+	Sender        string                      `json:"sender,omitempty"`
+	Caller        string                      `json:"caller,omitempty"`
+	RequiredAuths []string                    `json:"required_auths,omitempty"`
+	Timestamp     string                      `json:"timestamp,omitempty"`
+	Action        string                      `json:"action"`
+	Payload       *string                     `json:"payload,omitempty"`
+	Returns       json.RawMessage             `json:"returns,omitempty"`
+	Abort         string                      `json:"abort,omitempty"`
+	State         map[string]string           `json:"state,omitempty"`
+	Balances      map[string]map[string]int64 `json:"balances,omitempty"`
+}
+
+// Entrypoints maps exported action names to their Go functions.
+type Entrypoints map[string]func(*string) *string
+
+// Load reads a scenario file.
+func Load(path string) (*Scenario, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Scenario{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, st := range s.Steps {
+		if st.Action == "" {
+			return nil, fmt.Errorf("%s: step %d has no action", path, i)
+		}
+	}
+	return s, nil
+}
+
+// Run loads the scenario at path and replays it as subtests of t.
+func Run(t *testing.T, path string, eps Entrypoints) {
+	t.Helper()
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Run(t, eps)
+}
+
+// Run resets the shim and replays the steps as subtests of t, stopping at the
+// first failing step since later steps depend on its effects.
+func (s *Scenario) Run(t *testing.T, eps Entrypoints) {
+	t.Helper()
+	sdk.ShimReset()
+	if s.ContractId != "" {
+		sdk.ShimSetContractId(s.ContractId)
+	}
+	if s.State != nil {
+		b, _ := json.Marshal(s.State)
+		if err := sdk.ShimLoadState(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for addr, bals := range s.Balances {
+		for asset, amt := range bals {
+			sdk.ShimSetBalance(sdk.Address(addr), sdk.Asset(asset), amt)
+		}
+	}
+	for i, st := range s.Steps {
+		name := st.Name
+		if name == "" {
+			name = st.Action
+		}
+		ok := t.Run(fmt.Sprintf("%02d_%s", i, name), func(t *testing.T) {
+			fn := eps[st.Action]
+			if fn == nil {
+				t.Fatalf("unknown action %q", st.Action)
+			}
+			st.apply(i)
+			ret, panicked, msg := call(fn, st.Payload)
+			if st.Abort != "" {
+				if !panicked {
+					t.Fatalf("expected abort containing %q, call succeeded", st.Abort)
+				}
+				if !strings.Contains(msg, st.Abort) {
+					t.Fatalf("abort = %q, want it to contain %q", msg, st.Abort)
+				}
+			} else if panicked {
+				t.Fatalf("unexpected abort: %s", msg)
+			}
+			if st.Returns != nil {
+				checkReturn(t, st.Returns, ret)
+			}
+			st.check(t)
+		})
+		if !ok {
+			return
+		}
+	}
+}
+
+// apply sets the env for step i. The tx id changes every step so per-tx
+// guards such as reentrancy locks do not leak between steps.
+func (st *Step) apply(i int) {
+	sdk.ShimSetEnv("anchor.id", "tx:"+strconv.Itoa(i+1))
+	if st.Sender != "" {
+		sdk.ShimSetSender(sdk.Address(st.Sender))
+	}
+	if st.Caller != "" {
+		sdk.ShimSetCaller(sdk.Address(st.Caller))
+	}
+	if st.RequiredAuths != nil {
+		auths := make([]sdk.Address, len(st.RequiredAuths))
+		for i, a := range st.RequiredAuths {
+			auths[i] = sdk.Address(a)
+		}
+		sdk.ShimSetAuths(auths, nil)
+	}
+	if st.Timestamp != "" {
+		sdk.ShimSetTimestamp(st.Timestamp)
+	}
+}
+
+func call(fn func(*string) *string, payload *string) (ret *string, panicked bool, msg string) {
+	restore := sdk.ShimSnapshot()
+	defer func() {
+		if r := recover(); r != nil {
+			restore()
+			ret, panicked, msg = nil, true, fmt.Sprint(r)
+		}
+	}()
+	if payload == nil {
+		payload = new(string)
+	}
+	return fn(payload), false, ""
+}
+
+func checkReturn(t *testing.T, want json.RawMessage, got *string) {
+	t.Helper()
+	var w *string
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("returns must be a string or null: %v", err)
+	}
+	switch {
+	case w == nil && got != nil:
+		t.Fatalf("returned %q, want nil", *got)
+	case w != nil && got == nil:
+		t.Fatalf("returned nil, want %q", *w)
+	case w != nil && *w != *got:
+		t.Fatalf("returned %q, want %q", *got, *w)
+	}
+}
+
+func (st *Step) check(t *testing.T) {
+	t.Helper()
+	for key, want := range st.State {
+		if got := *sdk.StateGetObject(key); got != want {
+			t.Errorf("state %s = %q, want %q", key, got, want)
+		}
+	}
+	for addr, bals := range st.Balances {
+		for asset, want := range bals {
+			if got := sdk.ShimGetBalance(sdk.Address(addr), sdk.Asset(asset)); got != want {
+				t.Errorf("balance %s %s = %d, want %d", addr, asset, got, want)
+			}
+		}
+	}
+}
@@ -86,6 +86,12 @@ func GetEnv() Env {
 		rpa = append(rpa, Address(a))
 	}
 	env.Sender = Sender{Address: Address(sender), RequiredAuths: ra, RequiredPostingAuths: rpa}
+	// Caller is the immediate invoker; it equals the sender unless called by another contract
+	caller := get("msg.caller")
+	if caller == "" {
+		caller = sender
+	}
+	env.Caller = Caller{Address: Address(caller)}
 	return env
 }
 
@@ -127,3 +133,30 @@ func HiveWithdraw(to Address, amount int64, asset Asset) {
 	as := asset.String()
 	hiveWithdraw(&toaddr, &amt, &as)
 }
+
+// ContractCallOptions are passed to contracts.call. Intents let the callee
+// draw funds from the calling contract, e.g. a transfer.allow limit.
+type ContractCallOptions struct {
+	Intents []Intent `json:"intents"`
+}
+
+// Call a method on another contract and return its result. The callee sees
+// this contract as msg.caller; an abort in the callee aborts this call too.
+func ContractCall(contract Address, method string, payload string, options *ContractCallOptions) *string {
+	id := contract.String()
+	if options == nil {
+		options = &ContractCallOptions{}
+	}
+	if options.Intents == nil {
+		options.Intents = []Intent{}
+	}
+	b, _ := json.Marshal(options)
+	opts := string(b)
+	return contractCall(&id, &method, &payload, &opts)
+}
+
+// Read a state key of another contract.
+func ContractRead(contract Address, key string) *string {
+	id := contract.String()
+	return contractRead(&id, &key)
+}
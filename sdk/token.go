@@ -0,0 +1,163 @@
+package sdk
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Token moves a fungible asset on behalf of the running contract. Pools and
+// routers hold Token values instead of calling the hive.* host functions so
+// the same logic works for native assets and token contracts.
+type Token interface {
+	// Id is the asset id, e.g. "hbd" or "contract:xyz/TOKEN".
+	Id() string
+	// Decimals is the number of decimals of one unit.
+	Decimals() int
+	BalanceOf(addr Address) int64
+	// Draw moves amount from the caller to this contract: the transaction
+	// sender, or the calling contract during contracts.call.
+	Draw(amount int64)
+	// Transfer moves amount from this contract to another account.
+	Transfer(to Address, amount int64)
+}
+
+// NativeToken is a Hive asset held through the hive.* host functions.
+type NativeToken struct {
+	Asset Asset
+}
+
+func (t NativeToken) Id() string                   { return t.Asset.String() }
+func (t NativeToken) Decimals() int                { return t.Asset.Precision() }
+func (t NativeToken) BalanceOf(addr Address) int64 { return GetBalance(addr, t.Asset) }
+
+func (t NativeToken) Draw(amount int64) {
+	HiveDrawAmount(Amount{Asset: t.Asset, Units: amount})
+}
+
+func (t NativeToken) Transfer(to Address, amount int64) {
+	HiveTransferAmount(to, Amount{Asset: t.Asset, Units: amount})
+}
+
+// ContractToken is a token implemented by another contract and reached with
+// contracts.call. The token contract exposes:
+//
+//	transfer       "to,amount"      from msg.caller to `to`
+//	transfer_from  "from,to,amount" spends an allowance granted to msg.caller
+//	balance_of     "address"        returns the balance as a decimal string
+//	decimals       ""               returns the precision as a decimal string
+//
+//	approve        "spender,amount" lets spender transfer_from msg.caller
+//
+// Draw pulls from the caller with transfer_from, so callers approve the
+// contract on the token first.
+type ContractToken struct {
+	Contract Address
+	Symbol   string
+}
+
+func (t ContractToken) Id() string { return t.Contract.String() + "/" + t.Symbol }
+
+func (t ContractToken) call(method, payload string) string {
+	res := ContractCall(t.Contract, method, payload, nil)
+	if res == nil {
+		return ""
+	}
+	return *res
+}
+
+func (t ContractToken) Decimals() int {
+	n, err := strconv.Atoi(t.call("decimals", ""))
+	if err != nil {
+		Abort("token: bad decimals from " + t.Id())
+	}
+	return n
+}
+
+func (t ContractToken) BalanceOf(addr Address) int64 {
+	res := t.call("balance_of", addr.String())
+	if res == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(res, 10, 64)
+	if err != nil {
+		Abort("token: bad balance from " + t.Id())
+	}
+	return n
+}
+
+func (t ContractToken) Draw(amount int64) {
+	if amount <= 0 {
+		Abort("amount: must be positive")
+	}
+	env := GetEnv()
+	t.call("transfer_from", env.Caller.Address.String()+","+env.ContractId+","+strconv.FormatInt(amount, 10))
+}
+
+func (t ContractToken) Transfer(to Address, amount int64) {
+	if amount <= 0 {
+		Abort("amount: must be positive")
+	}
+	t.call("transfer", to.String()+","+strconv.FormatInt(amount, 10))
+}
+
+// ParseToken resolves an asset id: a native asset such as "hive", or a token
+// contract as "contract:<id>/<SYMBOL>".
+func ParseToken(id string) (Token, error) {
+	if a := Asset(id); a.Known() {
+		return NativeToken{Asset: a}, nil
+	}
+	contract, symbol, ok := strings.Cut(id, "/")
+	if !ok {
+		return nil, errors.New("token: unknown asset " + strconv.Quote(id))
+	}
+	addr, err := ParseAddress(contract)
+	if err != nil || addr.Type() != AddressTypeContract {
+		return nil, errors.New("token: " + strconv.Quote(contract) + " is not a contract address")
+	}
+	if !isTokenSymbol(symbol) {
+		return nil, errors.New("token: bad symbol " + strconv.Quote(symbol))
+	}
+	return ContractToken{Contract: addr, Symbol: symbol}, nil
+}
+
+// TokenOf is ParseToken for asset ids read from state or payloads; it
+// aborts on an invalid id.
+func TokenOf(asset Asset) Token {
+	t, err := ParseToken(asset.String())
+	if err != nil {
+		Abort(err.Error())
+	}
+	return t
+}
+
+// AllowDraw lets spender Draw up to amount of t from this contract during
+// the next ContractCall. Native assets travel as a transfer.allow intent in
+// the returned options; token contracts are approved up front.
+func AllowDraw(t Token, spender Address, amount int64) *ContractCallOptions {
+	if amount <= 0 {
+		Abort("amount: must be positive")
+	}
+	if ct, ok := t.(ContractToken); ok {
+		ct.call("approve", spender.String()+","+strconv.FormatInt(amount, 10))
+		return &ContractCallOptions{}
+	}
+	limit, _, _ := strings.Cut(Amount{Asset: Asset(t.Id()), Units: amount}.String(), " ")
+	return &ContractCallOptions{Intents: []Intent{{
+		Type: "transfer.allow",
+		Args: map[string]string{"limit": limit, "token": t.Id()},
+	}}}
+}
+
+func isTokenSymbol(s string) bool {
+	if len(s) == 0 || len(s) > 16 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
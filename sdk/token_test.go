@@ -0,0 +1,141 @@
+package sdk
+
+import "testing"
+
+func TestParseToken(t *testing.T) {
+	valid := map[string]Token{
+		"hive":                   NativeToken{AssetHive},
+		"hbd_savings":            NativeToken{AssetHbdSavings},
+		"contract:xyz/TOKEN":     ContractToken{"contract:xyz", "TOKEN"},
+		"contract:a-b_c/LP_2024": ContractToken{"contract:a-b_c", "LP_2024"},
+	}
+	for id, want := range valid {
+		got, err := ParseToken(id)
+		if err != nil || got != want || got.Id() != id {
+			t.Errorf("ParseToken(%q) = %v, %v", id, got, err)
+		}
+	}
+	for _, id := range []string{"", "HIVE", "btc", "contract:xyz", "contract:xyz/", "contract:xyz/tok", "hive:alice/TOK", "contract:/TOK", "contract:xyz/TOOOOOOOOOOOOOOOK"} {
+		if tok, err := ParseToken(id); err == nil {
+			t.Errorf("ParseToken(%q) = %v, want error", id, tok)
+		}
+	}
+	mustAbort(t, "unknown asset", func() { TokenOf("btc") })
+}
+
+func TestNativeToken(t *testing.T) {
+	ShimReset()
+	alice := Address("hive:alice")
+	ShimSetSender(alice)
+	ShimSetBalance(alice, AssetHive, 1000)
+	tok := TokenOf(AssetHive)
+	tok.Draw(600)
+	tok.Transfer("hive:bob", 100)
+	if tok.Decimals() != 3 || tok.BalanceOf(alice) != 400 || tok.BalanceOf("contract:test") != 500 || tok.BalanceOf("hive:bob") != 100 {
+		t.Fatal("native token balances")
+	}
+	mustAbort(t, "must be positive", func() { tok.Draw(0) })
+}
+
+func TestContractToken(t *testing.T) {
+	ShimReset()
+	ShimSetContractId("contract:pool")
+	alice := Address("hive:alice")
+	ShimSetSender(alice)
+	tok := ShimRegisterToken("contract:tok", "TOK", 6)
+	asset := Asset(tok.Id())
+	ShimSetBalance(alice, asset, 1000)
+
+	if tok.Decimals() != 6 || tok.BalanceOf(alice) != 1000 {
+		t.Fatal("decimals or balance")
+	}
+	mustAbort(t, "allowance exceeded", func() { tok.Draw(10) })
+	ShimApproveToken(tok, alice, "contract:pool", 700)
+	tok.Draw(700)
+	mustAbort(t, "allowance exceeded", func() { tok.Draw(1) })
+	tok.Transfer("hive:bob", 200)
+	mustAbort(t, "insufficient balance", func() { tok.Transfer("hive:bob", 501) })
+	if ShimGetBalance(alice, asset) != 300 || ShimGetBalance("contract:pool", asset) != 500 || ShimGetBalance("hive:bob", asset) != 200 {
+		t.Fatal("token balances")
+	}
+	if *GetEnvKey("contract_id") != "contract:pool" || *GetEnvKey("msg.caller") != "" {
+		t.Fatal("env not restored after calls")
+	}
+}
+
+func TestAllowDraw_NestedCall(t *testing.T) {
+	ShimReset()
+	ShimSetContractId("contract:router")
+	ShimSetSender("hive:alice")
+	ShimSetBalance("contract:router", AssetHbd, 5000)
+	tok := ShimRegisterToken("contract:tok", "TOK", 3)
+	ShimSetBalance("contract:router", Asset(tok.Id()), 500)
+	// the pool draws from its caller, the router, not from the sender
+	ShimRegisterContract("contract:pool", map[string]func(*string) *string{
+		"pull": func(p *string) *string {
+			TokenOf(Asset(*p)).Draw(1250)
+			return nil
+		},
+		"pull_tok": func(*string) *string {
+			tok.Draw(400)
+			return nil
+		},
+	})
+
+	opts := AllowDraw(TokenOf(AssetHbd), "contract:pool", 1250)
+	if len(opts.Intents) != 1 || opts.Intents[0].Type != "transfer.allow" ||
+		opts.Intents[0].Args["limit"] != "1.250" || opts.Intents[0].Args["token"] != "hbd" {
+		t.Fatalf("intents = %+v", opts.Intents)
+	}
+	ContractCall("contract:pool", "pull", "hbd", opts)
+	if ShimGetBalance("contract:router", AssetHbd) != 3750 || ShimGetBalance("contract:pool", AssetHbd) != 1250 || ShimGetBalance("hive:alice", AssetHbd) != 0 {
+		t.Fatal("native draw did not come from the calling contract")
+	}
+
+	mustAbort(t, "allowance exceeded", func() { ContractCall("contract:pool", "pull_tok", "", nil) })
+	if opts := AllowDraw(tok, "contract:pool", 400); len(opts.Intents) != 0 {
+		t.Fatal("token contracts are approved, not sent intents")
+	}
+	ContractCall("contract:pool", "pull_tok", "", nil)
+	if ShimGetBalance("contract:pool", Asset(tok.Id())) != 400 {
+		t.Fatal("token draw")
+	}
+	mustAbort(t, "must be positive", func() { AllowDraw(tok, "contract:pool", 0) })
+}
+
+func TestContractCall_StateAndCaller(t *testing.T) {
+	ShimReset()
+	ShimSetContractId("contract:a")
+	ShimSetSender("hive:alice")
+	StateSetObject("k", "a")
+	ShimRegisterContract("contract:b", map[string]func(*string) *string{
+		"set": func(p *string) *string {
+			StateSetObject("k", *p)
+			env := GetEnv()
+			s := env.ContractId + "," + env.Caller.Address.String() + "," + env.Sender.Address.String()
+			return &s
+		},
+		"fail": func(*string) *string { Abort("nope"); return nil },
+	})
+
+	res := ContractCall("contract:b", "set", "b", nil)
+	if res == nil || *res != "contract:b,contract:a,hive:alice" {
+		t.Fatalf("callee env = %v", res)
+	}
+	if *StateGetObject("k") != "a" || *ContractRead("contract:b", "k") != "b" || *ContractRead("contract:a", "k") != "a" {
+		t.Fatal("contract state not isolated")
+	}
+
+	restore := ShimSnapshot()
+	ContractCall("contract:b", "set", "changed", nil)
+	restore()
+	if *ContractRead("contract:b", "k") != "b" {
+		t.Fatal("snapshot did not restore callee state")
+	}
+
+	mustAbort(t, "nope", func() { ContractCall("contract:b", "fail", "", nil) })
+	mustAbort(t, "has no method", func() { ContractCall("contract:b", "missing", "", nil) })
+	if *StateGetObject("k") != "a" || *GetEnvKey("contract_id") != "contract:a" {
+		t.Fatal("caller context not restored after abort")
+	}
+}
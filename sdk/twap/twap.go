@@ -0,0 +1,182 @@
+// Package twap computes time weighted average prices from cumulative price
+// accumulators, as kept by the v2-amm pool.
+//
+// A pool adds its spot price times the seconds it was in effect to an
+// accumulator before every reserve change. Two observations of the
+// accumulator taken at different times give the average price over the
+// window between them, which a single block cannot move far:
+//
+//	twap = (cumulative(t2) - cumulative(t1)) / (t2 - t1)
+//
+// Prices are UQ64x64 fixed point numbers. Accumulators are 128 bit and wrap
+// on overflow; only differences are meaningful and they stay exact as long
+// as price × window length fits in 64 integer bits.
+package twap
+
+import (
+	"contract-template/sdk"
+	"encoding/json"
+	"errors"
+	"math/bits"
+	"strconv"
+)
+
+// UQ64x64 is an unsigned fixed point number with 64 integer and 64
+// fractional bits: Hi is the integer part, Lo the fraction in 1/2^64.
+type UQ64x64 struct {
+	Hi, Lo uint64
+}
+
+// Ratio returns num/den rounded down. It aborts if den is 0.
+func Ratio(num, den uint64) UQ64x64 {
+	if den == 0 {
+		sdk.Abort("twap: zero denominator")
+	}
+	lo, _ := bits.Div64(num%den, 0, den)
+	return UQ64x64{Hi: num / den, Lo: lo}
+}
+
+// Mul returns floor(q * x), e.g. the amount of asset1 worth x of asset0 at
+// price0. It aborts if the result does not fit 64 bits.
+func (q UQ64x64) Mul(x uint64) uint64 {
+	hh, hl := bits.Mul64(q.Hi, x)
+	lh, _ := bits.Mul64(q.Lo, x)
+	sum, carry := bits.Add64(hl, lh, 0)
+	if hh != 0 || carry != 0 {
+		sdk.Abort("twap: overflow")
+	}
+	return sum
+}
+
+// String formats q as 0x followed by 32 hex digits.
+func (q UQ64x64) String() string { return hex128(q.Hi, q.Lo) }
+
+// Cumulative is a wrapping 128 bit price accumulator in UQ64x64 seconds.
+type Cumulative struct {
+	Hi, Lo uint64
+}
+
+// Add returns c + p*seconds modulo 2^128.
+func (c Cumulative) Add(p UQ64x64, seconds uint64) Cumulative {
+	hi := p.Hi * seconds // wraps like the rest of the accumulator
+	carryHi, lo := bits.Mul64(p.Lo, seconds)
+	hi += carryHi
+	var carry uint64
+	c.Lo, carry = bits.Add64(c.Lo, lo, 0)
+	c.Hi += hi + carry
+	return c
+}
+
+// Sub returns c - o modulo 2^128.
+func (c Cumulative) Sub(o Cumulative) Cumulative {
+	var borrow uint64
+	c.Lo, borrow = bits.Sub64(c.Lo, o.Lo, 0)
+	c.Hi -= o.Hi + borrow
+	return c
+}
+
+// Div returns c/seconds rounded down as a price. It aborts if seconds is 0.
+func (c Cumulative) Div(seconds uint64) UQ64x64 {
+	if seconds == 0 {
+		sdk.Abort("twap: zero window")
+	}
+	hi := c.Hi / seconds
+	lo, _ := bits.Div64(c.Hi%seconds, c.Lo, seconds)
+	return UQ64x64{Hi: hi, Lo: lo}
+}
+
+func (c Cumulative) String() string { return hex128(c.Hi, c.Lo) }
+
+func (c Cumulative) MarshalText() ([]byte, error) { return []byte(c.String()), nil }
+
+func (c *Cumulative) UnmarshalText(b []byte) error {
+	v, err := ParseCumulative(string(b))
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+// ParseCumulative parses the String form. An empty string is zero, so
+// accumulators missing from state read as a fresh accumulator.
+func ParseCumulative(s string) (Cumulative, error) {
+	if s == "" {
+		return Cumulative{}, nil
+	}
+	if len(s) != 34 || s[:2] != "0x" {
+		return Cumulative{}, errors.New("twap: bad accumulator " + strconv.Quote(s))
+	}
+	hi, err1 := strconv.ParseUint(s[2:18], 16, 64)
+	lo, err2 := strconv.ParseUint(s[18:], 16, 64)
+	if err1 != nil || err2 != nil {
+		return Cumulative{}, errors.New("twap: bad accumulator " + strconv.Quote(s))
+	}
+	return Cumulative{Hi: hi, Lo: lo}, nil
+}
+
+func hex128(hi, lo uint64) string {
+	const digits = "0123456789abcdef"
+	b := make([]byte, 34)
+	b[0], b[1] = '0', 'x'
+	for i := 0; i < 16; i++ {
+		b[17-i] = digits[hi>>(4*i)&0xf]
+		b[33-i] = digits[lo>>(4*i)&0xf]
+	}
+	return string(b)
+}
+
+// Observation is an accumulator snapshot. Price0 accumulates the price of
+// asset0 in asset1 (reserve1/reserve0), Price1 the inverse.
+type Observation struct {
+	Time   uint64     `json:"time"` // unix seconds
+	Price0 Cumulative `json:"price0Cumulative"`
+	Price1 Cumulative `json:"price1Cumulative"`
+}
+
+// Advance accrues the prices of reserves r0, r1 from o.Time until now and
+// returns the observation at now. Empty reserves accrue nothing, and a first
+// observation (Time 0) only starts the clock.
+func (o Observation) Advance(now, r0, r1 uint64) Observation {
+	if o.Time != 0 && now > o.Time && r0 > 0 && r1 > 0 {
+		dt := now - o.Time
+		o.Price0 = o.Price0.Add(Ratio(r1, r0), dt)
+		o.Price1 = o.Price1.Add(Ratio(r0, r1), dt)
+	}
+	if now > o.Time {
+		o.Time = now
+	}
+	return o
+}
+
+// Average returns the time weighted average prices between two
+// observations of the same pool.
+func Average(older, newer Observation) (price0, price1 UQ64x64, err error) {
+	if newer.Time <= older.Time {
+		return UQ64x64{}, UQ64x64{}, errors.New("twap: observations must be in increasing time order")
+	}
+	dt := newer.Time - older.Time
+	return newer.Price0.Sub(older.Price0).Div(dt), newer.Price1.Sub(older.Price1).Div(dt), nil
+}
+
+// ParseObservation decodes the JSON returned by a pool's observe view.
+func ParseObservation(s string) (Observation, error) {
+	var o Observation
+	if err := json.Unmarshal([]byte(s), &o); err != nil {
+		return Observation{}, errors.New("twap: bad observation: " + err.Error())
+	}
+	return o, nil
+}
+
+// Observe calls the observe view of pool and returns its current snapshot.
+func Observe(pool sdk.Address) Observation {
+	res := sdk.ContractCall(pool, "observe", "", nil)
+	if res == nil {
+		sdk.Abort("twap: no observation from " + pool.String())
+	}
+	o, err := ParseObservation(*res)
+	if err != nil {
+		sdk.Abort(err.Error())
+	}
+	return o
+}
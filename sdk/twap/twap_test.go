@@ -0,0 +1,113 @@
+package twap
+
+import (
+	"contract-template/sdk"
+	"math"
+	"strings"
+	"testing"
+)
+
+func expectAbort(t *testing.T, want string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected abort %q", want)
+		}
+		if msg, _ := r.(string); msg != want {
+			t.Fatalf("abort = %q, want %q", msg, want)
+		}
+	}()
+	f()
+}
+
+func TestRatioAndMul(t *testing.T) {
+	if q := Ratio(3, 2); q != (UQ64x64{Hi: 1, Lo: 1 << 63}) || q.String() != "0x00000000000000018000000000000000" {
+		t.Fatalf("3/2 = %v", q)
+	}
+	if q := Ratio(1, 3); q.Hi != 0 || q.Mul(3_000_000) != 999_999 {
+		t.Fatalf("1/3 = %v", q)
+	}
+	if Ratio(4_000_000, 1_000_000).Mul(250) != 1000 {
+		t.Fatal("4 * 250")
+	}
+	if Ratio(math.MaxUint64, 1).Mul(1) != math.MaxUint64 {
+		t.Fatal("max price")
+	}
+	expectAbort(t, "twap: overflow", func() { Ratio(math.MaxUint64, 1).Mul(2) })
+	expectAbort(t, "twap: zero denominator", func() { Ratio(1, 0) })
+}
+
+func TestCumulativeWraps(t *testing.T) {
+	p := Ratio(5, 2)
+	// start just below 2^128 so the window crosses the wrap
+	start := Cumulative{Hi: math.MaxUint64, Lo: math.MaxUint64 - 10}
+	end := start.Add(p, 1000).Add(Ratio(1, 2), 1000)
+	if end.Hi >= start.Hi {
+		t.Fatal("accumulator did not wrap")
+	}
+	if avg := end.Sub(start).Div(2000); avg != Ratio(3, 2) {
+		t.Fatalf("average = %v, want 1.5", avg)
+	}
+	expectAbort(t, "twap: zero window", func() { end.Div(0) })
+
+	c, err := ParseCumulative(end.String())
+	if err != nil || c != end {
+		t.Fatalf("round trip %v %v", c, err)
+	}
+	if c, err := ParseCumulative(""); err != nil || c != (Cumulative{}) {
+		t.Fatal("empty accumulator")
+	}
+	for _, s := range []string{"0x1", "00000000000000000000000000000000ab", "0xzz000000000000000000000000000000"} {
+		if _, err := ParseCumulative(s); err == nil {
+			t.Errorf("ParseCumulative(%q) succeeded", s)
+		}
+	}
+}
+
+func TestObservationsAndAverage(t *testing.T) {
+	var o Observation
+	o = o.Advance(1000, 100, 400) // starts the clock only
+	if o.Time != 1000 || o.Price0 != (Cumulative{}) {
+		t.Fatalf("first observation %+v", o)
+	}
+	first := o
+	o = o.Advance(1010, 100, 400) // price0 4 for 10s
+	o = o.Advance(1010, 100, 100) // same block: nothing accrues
+	o = o.Advance(1040, 200, 100) // price0 0.5 for 30s
+	o = o.Advance(1030, 1, 1)     // time never runs backwards
+	if o.Time != 1040 {
+		t.Fatal("time went backwards")
+	}
+	p0, p1, err := Average(first, o)
+	// (4*10 + 0.5*30) / 40 = 1.375; (0.25*10 + 2*30) / 40 = 1.5625
+	if err != nil || p0 != Ratio(11, 8) || p1 != Ratio(25, 16) {
+		t.Fatalf("averages %v %v %v", p0, p1, err)
+	}
+	if _, _, err := Average(o, first); err == nil {
+		t.Fatal("reversed observations")
+	}
+
+	// zero reserves accrue nothing
+	if e := o.Advance(2000, 0, 5); e.Price0 != o.Price0 || e.Time != 2000 {
+		t.Fatal("empty pool accrued")
+	}
+}
+
+func TestObserveViaContract(t *testing.T) {
+	sdk.ShimReset()
+	sdk.ShimSetContractId("contract:lender")
+	want := Observation{Time: 1040, Price0: Cumulative{Hi: 7, Lo: 9}, Price1: Cumulative{Lo: 3}}
+	sdk.ShimRegisterContract("contract:pool", map[string]func(*string) *string{
+		"observe": func(*string) *string {
+			s := `{"time":1040,"price0Cumulative":"` + want.Price0.String() + `","price1Cumulative":"` + want.Price1.String() + `"}`
+			return &s
+		},
+	})
+	if got := Observe("contract:pool"); got != want {
+		t.Fatalf("observe = %+v, want %+v", got, want)
+	}
+	if _, err := ParseObservation(`{"time":1,"price0Cumulative":"12"}`); err == nil || !strings.Contains(err.Error(), "bad accumulator") {
+		t.Fatalf("err = %v", err)
+	}
+}
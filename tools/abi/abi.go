@@ -0,0 +1,124 @@
+// Package abi describes a contract's entrypoints and extracts that description
+// from Go source.
+//
+// Entrypoints are functions marked //go:wasmexport. Their interface is read
+// from directives placed next to the export directive:
+//
+//	// Swap tokens against the pool.
+//	//
+//	//abi:payload dir:enum(0to1|1to0),amountIn:uint64,minOut:uint64?
+//	//abi:returns none
+//	//abi:auth any
+//	//abi:mutability payable
+//	//go:wasmexport swap
+//	func Swap(payload *string) *string
+//
+// //abi:payload takes "none", "json" or a comma separated field list matching
+// the comma separated payload. A field is name:type with an optional trailing
+// "?" meaning the value may be empty or, for trailing fields, omitted. Types
+// are string, uint64, int64, bool, address, asset and enum(a|b|...). Repeat
+// the directive to declare alternative payload forms; the first is canonical.
+//
+// Missing directives fall back to defaults: a legacy `Payload: "a,b"` doc line
+// yields string fields, auth is inferred from direct access.Require* calls,
+// mutability defaults to write and returns to none.
+package abi
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Version of the manifest format.
+const Version = 1
+
+const (
+	EncodingNone = "none"
+	EncodingCSV  = "csv"
+	EncodingJSON = "json"
+)
+
+const (
+	MutabilityView    = "view"    // reads state only
+	MutabilityWrite   = "write"   // writes state
+	MutabilityPayable = "payable" // draws funds from the caller; needs transfer.allow intents
+)
+
+const (
+	AuthAny    = "any"
+	AuthSystem = "system"
+	AuthOwner  = "owner"
+	AuthActive = "active"
+	// AuthRolePrefix is followed by the role name, e.g. "role:minter".
+	AuthRolePrefix = "role:"
+)
+
+const (
+	TypeString  = "string"
+	TypeUint64  = "uint64"
+	TypeInt64   = "int64"
+	TypeBool    = "bool"
+	TypeAddress = "address"
+	TypeAsset   = "asset"
+	TypeEnum    = "enum"
+)
+
+type ABI struct {
+	Version     int          `json:"version"`
+	Contract    string       `json:"contract"`
+	Entrypoints []Entrypoint `json:"entrypoints"`
+}
+
+type Entrypoint struct {
+	Name       string    `json:"name"`
+	Function   string    `json:"function"`
+	Doc        string    `json:"doc,omitempty"`
+	Payloads   []Payload `json:"payloads"`
+	Returns    string    `json:"returns"`
+	Auth       string    `json:"auth"`
+	Mutability string    `json:"mutability"`
+}
+
+type Payload struct {
+	Encoding string  `json:"encoding"`
+	Fields   []Field `json:"fields,omitempty"`
+}
+
+type Field struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Optional bool     `json:"optional,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+}
+
+// Entrypoint returns the entrypoint exported as name, or nil.
+func (a *ABI) Entrypoint(name string) *Entrypoint {
+	for i := range a.Entrypoints {
+		if a.Entrypoints[i].Name == name {
+			return &a.Entrypoints[i]
+		}
+	}
+	return nil
+}
+
+// Marshal renders the manifest as indented JSON with a trailing newline.
+func (a *ABI) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Load reads a manifest written by Marshal.
+func Load(path string) (*ABI, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	a := &ABI{}
+	if err := json.Unmarshal(b, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
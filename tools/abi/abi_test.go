@@ -0,0 +1,106 @@
+package abi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDir_Sample(t *testing.T) {
+	m, err := ParseDir("testdata/sample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Contract != "sample" || len(m.Entrypoints) != 4 {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+
+	swap := m.Entrypoint("swap")
+	if swap == nil || swap.Function != "Swap" || swap.Mutability != MutabilityPayable || swap.Auth != AuthAny {
+		t.Fatalf("swap: %+v", swap)
+	}
+	if swap.Doc != "Swap tokens.\nPayload: \"dir,amountIn\"" {
+		t.Fatalf("swap doc: %q", swap.Doc)
+	}
+	if len(swap.Payloads) != 2 || len(swap.Payloads[1].Fields) != 5 {
+		t.Fatalf("swap payloads: %+v", swap.Payloads)
+	}
+	dir := swap.Payloads[0].Fields[0]
+	if dir.Type != TypeEnum || !reflect.DeepEqual(dir.Enum, []string{"0to1", "1to0"}) {
+		t.Fatalf("dir field: %+v", dir)
+	}
+	if minOut := swap.Payloads[0].Fields[2]; !minOut.Optional || minOut.Type != TypeUint64 {
+		t.Fatalf("minOut field: %+v", minOut)
+	}
+
+	// legacy Payload: doc lines become untyped string fields
+	tr := m.Entrypoint("transfer")
+	want := Payload{Encoding: EncodingCSV, Fields: []Field{{Name: "to", Type: TypeString}, {Name: "amount", Type: TypeString}}}
+	if !reflect.DeepEqual(tr.Payloads, []Payload{want}) || tr.Mutability != MutabilityWrite {
+		t.Fatalf("transfer: %+v", tr)
+	}
+
+	// auth inferred through a renamed access import
+	if fee := m.Entrypoint("set_fee"); fee.Auth != AuthSystem || fee.Payloads[0].Encoding != EncodingNone {
+		t.Fatalf("set_fee: %+v", fee)
+	}
+	if res := m.Entrypoint("get_reserves"); res.Returns != "json" || res.Mutability != MutabilityView {
+		t.Fatalf("get_reserves: %+v", res)
+	}
+}
+
+func TestParseDir_RejectsBadDirectives(t *testing.T) {
+	cases := map[string]string{
+		"type":      "//abi:payload a:float64",
+		"field":     "//abi:payload amount",
+		"auth":      "//abi:auth admin",
+		"mut":       "//abi:mutability pure",
+		"directive": "//abi:gas 100",
+	}
+	for name, line := range cases {
+		dir := t.TempDir()
+		src := "package main\n\n" + line + "\n//go:wasmexport f\nfunc F(p *string) *string { return nil }\n"
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseDir(dir); err == nil {
+			t.Fatalf("%s: expected error for %q", name, line)
+		}
+	}
+}
+
+// The example contracts must always produce a valid manifest.
+func TestParseDir_Examples(t *testing.T) {
+	for _, dir := range []string{"v2", "v2-amm", "v3", "token", "nft", "multitoken", "router"} {
+		if _, err := ParseDir(filepath.Join("..", "..", "examples", dir)); err != nil {
+			t.Fatalf("%s: %v", dir, err)
+		}
+	}
+	m, _ := ParseDir(filepath.Join("..", "..", "examples", "v2-amm"))
+	if swap := m.Entrypoint("swap"); swap == nil || len(swap.Payloads) != 5 {
+		t.Fatal("v2-amm swap must declare its five payload forms")
+	}
+	if claim := m.Entrypoint("claim_fees"); claim == nil || claim.Auth != AuthSystem {
+		t.Fatal("v2-amm claim_fees auth not inferred")
+	}
+}
+
+func TestMarshalLoadRoundTrip(t *testing.T) {
+	m, err := ParseDir("testdata/sample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "main.abi.json")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil || !reflect.DeepEqual(got, m) {
+		t.Fatalf("round trip mismatch: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+package abi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var legacyPayload = regexp.MustCompile(`^Payload:\s*"([^"]*)"`)
+
+// ParseDir extracts the manifest of the contract package in dir. Test files
+// are ignored.
+func ParseDir(dir string) (*ABI, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() || !strings.HasSuffix(n, ".go") || strings.HasSuffix(n, "_test.go") {
+			continue
+		}
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("abi: no Go files in %s", dir)
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, n := range names {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, n), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFiles(fset, filepath.Base(abs), files)
+}
+
+// ParseFiles extracts the manifest from already parsed files, in file order.
+func ParseFiles(fset *token.FileSet, contract string, files []*ast.File) (*ABI, error) {
+	a := &ABI{Version: Version, Contract: contract, Entrypoints: []Entrypoint{}}
+	seen := map[string]token.Position{}
+	for _, f := range files {
+		access := importName(f, "contract-template/sdk/access", "access")
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil || fn.Recv != nil {
+				continue
+			}
+			ep, err := parseFunc(fset, fn, access)
+			if err != nil {
+				return nil, err
+			}
+			if ep == nil {
+				continue
+			}
+			pos := fset.Position(fn.Pos())
+			if prev, dup := seen[ep.Name]; dup {
+				return nil, fmt.Errorf("%s: entrypoint %q already exported at %s", pos, ep.Name, prev)
+			}
+			seen[ep.Name] = pos
+			a.Entrypoints = append(a.Entrypoints, *ep)
+		}
+	}
+	return a, nil
+}
+
+// importName returns the local name of path in f, or "" if not imported.
+func importName(f *ast.File, path, def string) string {
+	for _, imp := range f.Imports {
+		if strings.Trim(imp.Path.Value, `"`) != path {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return def
+	}
+	return ""
+}
+
+func parseFunc(fset *token.FileSet, fn *ast.FuncDecl, accessPkg string) (*Entrypoint, error) {
+	ep := &Entrypoint{Function: fn.Name.Name}
+	var doc []string
+	var legacy string
+	errorf := func(c *ast.Comment, format string, args ...any) error {
+		return fmt.Errorf("%s: %s", fset.Position(c.Pos()), fmt.Sprintf(format, args...))
+	}
+	for _, c := range fn.Doc.List {
+		text := c.Text
+		switch {
+		case strings.HasPrefix(text, "//go:wasmexport "):
+			ep.Name = strings.TrimSpace(strings.TrimPrefix(text, "//go:wasmexport "))
+		case strings.HasPrefix(text, "//abi:"):
+			key, val, _ := strings.Cut(strings.TrimPrefix(text, "//abi:"), " ")
+			val = strings.TrimSpace(val)
+			switch key {
+			case "payload":
+				p, err := parsePayload(val)
+				if err != nil {
+					return nil, errorf(c, "%v", err)
+				}
+				ep.Payloads = append(ep.Payloads, p)
+			case "returns":
+				ep.Returns = val
+			case "auth":
+				if !validAuth(val) {
+					return nil, errorf(c, "unknown auth %q", val)
+				}
+				ep.Auth = val
+			case "mutability":
+				if val != MutabilityView && val != MutabilityWrite && val != MutabilityPayable {
+					return nil, errorf(c, "unknown mutability %q", val)
+				}
+				ep.Mutability = val
+			default:
+				return nil, errorf(c, "unknown directive //abi:%s", key)
+			}
+		case strings.HasPrefix(text, "//go:"), strings.HasPrefix(text, "/*"):
+		default:
+			line := strings.TrimSpace(strings.TrimPrefix(text, "//"))
+			if m := legacyPayload.FindStringSubmatch(line); m != nil {
+				legacy = m[1]
+			}
+			doc = append(doc, line)
+		}
+	}
+	if ep.Name == "" {
+		return nil, nil
+	}
+	ep.Doc = strings.TrimSpace(strings.Join(doc, "\n"))
+	if len(ep.Payloads) == 0 {
+		ep.Payloads = []Payload{legacyPayloadOf(legacy)}
+	}
+	if ep.Returns == "" {
+		ep.Returns = "none"
+	}
+	if ep.Auth == "" {
+		ep.Auth = inferAuth(fn, accessPkg)
+	}
+	if ep.Mutability == "" {
+		ep.Mutability = MutabilityWrite
+	}
+	return ep, nil
+}
+
+func validAuth(s string) bool {
+	switch s {
+	case AuthAny, AuthSystem, AuthOwner, AuthActive:
+		return true
+	}
+	return strings.HasPrefix(s, AuthRolePrefix) && len(s) > len(AuthRolePrefix)
+}
+
+func parsePayload(s string) (Payload, error) {
+	switch s {
+	case "", EncodingNone:
+		return Payload{Encoding: EncodingNone}, nil
+	case EncodingJSON:
+		return Payload{Encoding: EncodingJSON}, nil
+	}
+	p := Payload{Encoding: EncodingCSV}
+	for _, spec := range strings.Split(s, ",") {
+		f, err := parseField(strings.TrimSpace(spec))
+		if err != nil {
+			return Payload{}, err
+		}
+		p.Fields = append(p.Fields, f)
+	}
+	return p, nil
+}
+
+func parseField(spec string) (Field, error) {
+	name, typ, ok := strings.Cut(spec, ":")
+	if !ok || name == "" {
+		return Field{}, fmt.Errorf("bad field %q, want name:type", spec)
+	}
+	f := Field{Name: name}
+	if strings.HasSuffix(typ, "?") {
+		f.Optional = true
+		typ = strings.TrimSuffix(typ, "?")
+	}
+	switch typ {
+	case TypeString, TypeUint64, TypeInt64, TypeBool, TypeAddress, TypeAsset:
+		f.Type = typ
+	default:
+		if !strings.HasPrefix(typ, "enum(") || !strings.HasSuffix(typ, ")") {
+			return Field{}, fmt.Errorf("unknown type %q for field %s", typ, name)
+		}
+		f.Type = TypeEnum
+		f.Enum = strings.Split(typ[len("enum("):len(typ)-1], "|")
+		for _, v := range f.Enum {
+			if v == "" {
+				return Field{}, fmt.Errorf("empty enum value for field %s", name)
+			}
+		}
+	}
+	return f, nil
+}
+
+func legacyPayloadOf(s string) Payload {
+	if s == "" {
+		return Payload{Encoding: EncodingNone}
+	}
+	p := Payload{Encoding: EncodingCSV}
+	for _, n := range strings.Split(s, ",") {
+		p.Fields = append(p.Fields, Field{Name: strings.TrimSpace(n), Type: TypeString})
+	}
+	return p
+}
+
+// inferAuth looks for direct access.Require* calls in the function body.
+func inferAuth(fn *ast.FuncDecl, accessPkg string) string {
+	auth := AuthAny
+	if accessPkg == "" || fn.Body == nil {
+		return auth
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || auth != AuthAny {
+			return auth == AuthAny
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); !ok || id.Name != accessPkg {
+			return true
+		}
+		switch sel.Sel.Name {
+		case "RequireSystem":
+			auth = AuthSystem
+		case "RequireOwner":
+			auth = AuthOwner
+		case "RequireActiveAuth":
+			auth = AuthActive
+		case "RequireRole":
+			if len(call.Args) == 1 {
+				if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					auth = AuthRolePrefix + strings.Trim(lit.Value, `"`)
+				}
+			}
+		}
+		return true
+	})
+	return auth
+}
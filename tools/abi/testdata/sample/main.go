@@ -0,0 +1,37 @@
+package main
+
+import (
+	acc "contract-template/sdk/access"
+)
+
+func main() {}
+
+// Swap tokens.
+// Payload: "dir,amountIn"
+//
+//abi:payload dir:enum(0to1|1to0),amountIn:uint64,minOut:uint64?
+//abi:payload dir:enum(0to1|1to0),amountIn:uint64,minOut:uint64?,beneficiary:address,refBps:uint64
+//abi:mutability payable
+//go:wasmexport swap
+func Swap(payload *string) *string { return nil }
+
+// Legacy doc only.
+// Payload: "to,amount"
+//
+//go:wasmexport transfer
+func Transfer(payload *string) *string { return nil }
+
+//go:wasmexport set_fee
+func SetFee(payload *string) *string {
+	acc.RequireSystem()
+	return nil
+}
+
+//abi:payload none
+//abi:returns json
+//abi:mutability view
+//go:wasmexport get_reserves
+func GetReserves(_ *string) *string { return nil }
+
+// Not exported to wasm.
+func helper() {}
@@ -0,0 +1,346 @@
+// Package clientgen generates a typed Go client package from a contract ABI
+// manifest. Generated clients build payload strings, attach transfer.allow
+// intents and produce vsc.call_contract transactions through
+// contract-template/tools/vscclient.
+package clientgen
+
+import (
+	"bytes"
+	"contract-template/tools/abi"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const runtimeImport = "contract-template/tools/vscclient"
+
+// Generate returns the formatted source of a client package named pkg.
+func Generate(m *abi.ABI, pkg string) ([]byte, error) {
+	g := &gen{}
+	g.p("// Code generated by contract client from the %s ABI; DO NOT EDIT.", m.Contract)
+	g.p("")
+	g.p("// Package %s is a typed client for the %s contract.", pkg, m.Contract)
+	g.p("package %s", pkg)
+	g.p("")
+	g.p("import (")
+	if g.needsJSON(m) {
+		g.p("\t\"encoding/json\"")
+	}
+	if g.needsStrconv(m) {
+		g.p("\t\"strconv\"")
+	}
+	g.p("")
+	g.p("\t%q", runtimeImport)
+	g.p(")")
+	g.p("")
+	g.p("type Client struct {")
+	g.p("\tContractId string")
+	g.p("\tCaller     string")
+	g.p("\tNetId      string")
+	g.p("\tRcLimit    uint64")
+	g.p("}")
+	g.p("")
+	g.p("// New returns a client calling contractId as caller on mainnet.")
+	g.p("func New(contractId, caller string) *Client {")
+	g.p("\treturn &Client{ContractId: contractId, Caller: caller, NetId: vscclient.DefaultNetId, RcLimit: vscclient.DefaultRcLimit}")
+	g.p("}")
+	g.p("")
+	g.p("func (c *Client) call(action, payload string, intents []vscclient.Intent) *vscclient.CallContract {")
+	g.p("\treturn &vscclient.CallContract{NetId: c.NetId, Caller: c.Caller, ContractId: c.ContractId, Action: action, Payload: payload, RcLimit: c.RcLimit, Intents: intents}")
+	g.p("}")
+
+	seen := map[string]string{}
+	for _, ep := range m.Entrypoints {
+		for i, p := range ep.Payloads {
+			name := goName(ep.Name)
+			if i > 0 {
+				name += strconv.Itoa(i + 1)
+			}
+			if prev, dup := seen[name]; dup {
+				return nil, fmt.Errorf("clientgen: %s and %s both map to method %s", prev, ep.Name, name)
+			}
+			seen[name] = ep.Name
+			if err := g.method(ep, p, name); err != nil {
+				return nil, err
+			}
+		}
+		g.decoder(ep)
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("clientgen: generated invalid source: %w", err)
+	}
+	return src, nil
+}
+
+type gen struct {
+	buf bytes.Buffer
+}
+
+func (g *gen) p(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+func (g *gen) needsJSON(m *abi.ABI) bool {
+	for _, ep := range m.Entrypoints {
+		if ep.Returns == "json" {
+			return true
+		}
+		for _, p := range ep.Payloads {
+			if p.Encoding == abi.EncodingJSON {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *gen) needsStrconv(m *abi.ABI) bool {
+	for _, ep := range m.Entrypoints {
+		if ep.Returns == abi.TypeUint64 || ep.Returns == abi.TypeInt64 {
+			return true
+		}
+		for _, p := range ep.Payloads {
+			for _, f := range p.Fields {
+				switch f.Type {
+				case abi.TypeUint64, abi.TypeInt64, abi.TypeBool:
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (g *gen) method(ep abi.Entrypoint, p abi.Payload, name string) error {
+	payable := ep.Mutability == abi.MutabilityPayable
+	allowParam, allowArg := "", "nil"
+	if payable {
+		allowParam, allowArg = "allow ...vscclient.Intent", "allow"
+	}
+	g.p("")
+	switch p.Encoding {
+	case abi.EncodingNone:
+		g.comment(ep, name, "")
+		g.p("func (c *Client) %s(%s) (*vscclient.CallContract, error) {", name, allowParam)
+		g.p("\treturn c.call(%q, \"\", %s), nil", ep.Name, allowArg)
+		g.p("}")
+	case abi.EncodingJSON:
+		g.comment(ep, name, "args is marshalled to JSON.")
+		sep := ""
+		if payable {
+			sep = ", "
+		}
+		g.p("func (c *Client) %s(args any%s%s) (*vscclient.CallContract, error) {", name, sep, allowParam)
+		g.p("\tb, err := json.Marshal(args)")
+		g.p("\tif err != nil {")
+		g.p("\t\treturn nil, err")
+		g.p("\t}")
+		g.p("\treturn c.call(%q, string(b), %s), nil", ep.Name, allowArg)
+		g.p("}")
+	case abi.EncodingCSV:
+		if err := g.args(ep, p, name); err != nil {
+			return err
+		}
+		g.comment(ep, name, "")
+		sep := ""
+		if payable {
+			sep = ", "
+		}
+		g.p("func (c *Client) %s(args %sArgs%s%s) (*vscclient.CallContract, error) {", name, name, sep, allowParam)
+		g.p("\tpayload, err := args.Payload()")
+		g.p("\tif err != nil {")
+		g.p("\t\treturn nil, err")
+		g.p("\t}")
+		g.p("\treturn c.call(%q, payload, %s), nil", ep.Name, allowArg)
+		g.p("}")
+	default:
+		return fmt.Errorf("clientgen: %s: unknown payload encoding %q", ep.Name, p.Encoding)
+	}
+	return nil
+}
+
+func (g *gen) comment(ep abi.Entrypoint, name, extra string) {
+	g.p("// %s builds a call to %s (auth: %s, %s).", name, ep.Name, ep.Auth, ep.Mutability)
+	if ep.Mutability == abi.MutabilityPayable {
+		g.p("// Pass vscclient.Allow intents covering every amount the contract draws.")
+	}
+	if extra != "" {
+		g.p("// %s", extra)
+	}
+}
+
+func (g *gen) args(ep abi.Entrypoint, p abi.Payload, name string) error {
+	required := 0
+	for i, f := range p.Fields {
+		if !f.Optional {
+			required = i + 1
+		}
+	}
+	g.p("// %sArgs is the payload of %s: %s", name, ep.Name, fieldList(p))
+	g.p("type %sArgs struct {", name)
+	for _, f := range p.Fields {
+		typ, err := goType(f)
+		if err != nil {
+			return fmt.Errorf("clientgen: %s: %w", ep.Name, err)
+		}
+		note := ""
+		switch {
+		case f.Type == abi.TypeEnum:
+			note = " // one of " + strings.Join(f.Enum, "|")
+		case f.Optional && !strings.HasPrefix(typ, "*"):
+			note = " // optional; empty omits it"
+		}
+		g.p("\t%s %s%s", goName(f.Name), typ, note)
+	}
+	g.p("}")
+	g.p("")
+	g.p("// Payload encodes the arguments in the contract's comma separated form.")
+	g.p("func (a %sArgs) Payload() (string, error) {", name)
+	g.p("\tfields := make([]string, %d)", len(p.Fields))
+	for i, f := range p.Fields {
+		fn := goName(f.Name)
+		ref := "a." + fn
+		if f.Optional && isPointer(f) {
+			g.p("\tif a.%s != nil {", fn)
+			ref = "*a." + fn
+		}
+		switch f.Type {
+		case abi.TypeUint64:
+			g.p("\tfields[%d] = strconv.FormatUint(%s, 10)", i, ref)
+		case abi.TypeInt64:
+			g.p("\tfields[%d] = strconv.FormatInt(%s, 10)", i, ref)
+		case abi.TypeBool:
+			g.p("\tfields[%d] = strconv.FormatBool(%s)", i, ref)
+		case abi.TypeEnum:
+			check := fmt.Sprintf("vscclient.CheckEnum(%q, %s", f.Name, ref)
+			for _, v := range f.Enum {
+				check += fmt.Sprintf(", %q", v)
+			}
+			check += ")"
+			if f.Optional {
+				g.p("\tif %s != \"\" {", ref)
+			}
+			g.p("\tif err := %s; err != nil {", check)
+			g.p("\t\treturn \"\", err")
+			g.p("\t}")
+			if f.Optional {
+				g.p("\t}")
+			}
+			g.p("\tfields[%d] = %s", i, ref)
+		default:
+			g.p("\tif err := vscclient.CheckField(%q, %s); err != nil {", f.Name, ref)
+			g.p("\t\treturn \"\", err")
+			g.p("\t}")
+			g.p("\tfields[%d] = %s", i, ref)
+		}
+		if f.Optional && isPointer(f) {
+			g.p("\t}")
+		}
+	}
+	g.p("\treturn vscclient.JoinPayload(fields, %d), nil", required)
+	g.p("}")
+	g.p("")
+	return nil
+}
+
+func (g *gen) decoder(ep abi.Entrypoint) {
+	name := "Decode" + goName(ep.Name)
+	switch ep.Returns {
+	case "", "none":
+		return
+	case "json":
+		g.p("")
+		g.p("// %s unmarshals the JSON returned by %s into v.", name, ep.Name)
+		g.p("func %s(envelope []byte, v any) error {", name)
+		g.p("\tret, err := vscclient.DecodeResult(envelope)")
+		g.p("\tif err != nil {")
+		g.p("\t\treturn err")
+		g.p("\t}")
+		g.p("\treturn json.Unmarshal([]byte(ret), v)")
+		g.p("}")
+	case abi.TypeUint64:
+		g.p("")
+		g.p("// %s returns the value returned by %s.", name, ep.Name)
+		g.p("func %s(envelope []byte) (uint64, error) {", name)
+		g.p("\tret, err := vscclient.DecodeResult(envelope)")
+		g.p("\tif err != nil {")
+		g.p("\t\treturn 0, err")
+		g.p("\t}")
+		g.p("\treturn strconv.ParseUint(ret, 10, 64)")
+		g.p("}")
+	case abi.TypeInt64:
+		g.p("")
+		g.p("// %s returns the value returned by %s.", name, ep.Name)
+		g.p("func %s(envelope []byte) (int64, error) {", name)
+		g.p("\tret, err := vscclient.DecodeResult(envelope)")
+		g.p("\tif err != nil {")
+		g.p("\t\treturn 0, err")
+		g.p("\t}")
+		g.p("\treturn strconv.ParseInt(ret, 10, 64)")
+		g.p("}")
+	default:
+		g.p("")
+		g.p("// %s returns the value returned by %s.", name, ep.Name)
+		g.p("func %s(envelope []byte) (string, error) {", name)
+		g.p("\treturn vscclient.DecodeResult(envelope)")
+		g.p("}")
+	}
+}
+
+func isPointer(f abi.Field) bool {
+	switch f.Type {
+	case abi.TypeUint64, abi.TypeInt64, abi.TypeBool:
+		return true
+	}
+	return false
+}
+
+func goType(f abi.Field) (string, error) {
+	var t string
+	switch f.Type {
+	case abi.TypeUint64, abi.TypeInt64, abi.TypeBool:
+		t = f.Type
+	case abi.TypeString, abi.TypeAddress, abi.TypeAsset, abi.TypeEnum:
+		return "string", nil
+	default:
+		return "", fmt.Errorf("unsupported field type %q", f.Type)
+	}
+	if f.Optional {
+		t = "*" + t
+	}
+	return t, nil
+}
+
+func fieldList(p abi.Payload) string {
+	parts := make([]string, len(p.Fields))
+	for i, f := range p.Fields {
+		parts[i] = f.Name
+		if f.Optional {
+			parts[i] += "?"
+		}
+	}
+	return "\"" + strings.Join(parts, ",") + "\""
+}
+
+// goName converts snake_case and camelCase identifiers to exported Go names.
+func goName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if r == '_' || r == '-' || r == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package clientgen
+
+import (
+	"bytes"
+	"contract-template/tools/abi"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The checked-in v2-amm client must match what the generator produces today.
+func TestGenerate_V2AMMGolden(t *testing.T) {
+	dir := filepath.Join("..", "..", "examples", "v2-amm")
+	m, err := abi.ParseDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(m, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "client", "client.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("examples/v2-amm/client is stale; run go generate ./examples/v2-amm/client")
+	}
+}
+
+func TestGenerate_Shapes(t *testing.T) {
+	m := &abi.ABI{Contract: "demo", Entrypoints: []abi.Entrypoint{
+		{Name: "get_reserves", Payloads: []abi.Payload{{Encoding: abi.EncodingNone}}, Returns: "json", Auth: abi.AuthAny, Mutability: abi.MutabilityView},
+		{Name: "configure", Payloads: []abi.Payload{{Encoding: abi.EncodingJSON}}, Returns: "uint64", Auth: abi.AuthSystem, Mutability: abi.MutabilityWrite},
+		{Name: "flag", Payloads: []abi.Payload{{Encoding: abi.EncodingCSV, Fields: []abi.Field{{Name: "on", Type: abi.TypeBool, Optional: true}}}}, Returns: "string", Auth: abi.AuthAny, Mutability: abi.MutabilityWrite},
+	}}
+	src, err := Generate(m, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func (c *Client) GetReserves() (*vscclient.CallContract, error)",
+		"func DecodeGetReserves(envelope []byte, v any) error",
+		"func (c *Client) Configure(args any) (*vscclient.CallContract, error)",
+		"func DecodeConfigure(envelope []byte) (uint64, error)",
+		"On *bool",
+		"func DecodeFlag(envelope []byte) (string, error)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Fatalf("generated source missing %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerate_RejectsMethodCollision(t *testing.T) {
+	none := []abi.Payload{{Encoding: abi.EncodingNone}}
+	m := &abi.ABI{Entrypoints: []abi.Entrypoint{
+		{Name: "set_fee", Payloads: none},
+		{Name: "setFee", Payloads: none},
+	}}
+	if _, err := Generate(m, "x"); err == nil {
+		t.Fatal("expected collision error")
+	}
+}
@@ -0,0 +1,307 @@
+// Package contractlint defines an analyzer that enforces the restrictions of
+// the contract runtime (TinyGo, -scheduler=none, -panic=trap) and of
+// deterministic consensus execution.
+//
+// Reported constructs:
+//
+//	goroutine  go statements; there is no scheduler
+//	channel    channel types, sends, receives and close
+//	select     select statements
+//	defer      defer statements; disabled in the runtime
+//	recover    recover(); panics always trap
+//	float      floating point arithmetic and conversions
+//	time       time.Now, time.Since, time.Until
+//	maprange   map iteration whose body writes state, calls the host or builds output
+//	import     heavy packages (fmt, math/big, reflect, ...)
+//	export     exported functions of package main without //go:wasmexport
+//
+// A finding can be silenced with a "//contractlint:allow <check>" comment on
+// the same line or the line above. Test files and files built only without
+// the gc.custom tag (the host shim) are skipped.
+package contractlint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const sdkPath = "contract-template/sdk"
+
+var Analyzer = &analysis.Analyzer{
+	Name: "contractlint",
+	Doc:  "report constructs that are unavailable or non-deterministic in VSC contracts",
+	Run:  run,
+}
+
+var heavy string
+
+// DefaultHeavy lists packages that bloat the wasm binary or pull in
+// reflection the runtime does not support well.
+const DefaultHeavy = "fmt,math/big,reflect,regexp,text/template,html/template,log,net/http"
+
+func init() {
+	Analyzer.Flags.StringVar(&heavy, "heavy", DefaultHeavy, "comma separated import paths to report")
+}
+
+type checker struct {
+	pass  *analysis.Pass
+	allow map[allowKey]bool
+}
+
+// allowKey is a line where a check is silenced; check "" silences all.
+type allowKey struct {
+	file  string
+	line  int
+	check string
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	c := &checker{pass: pass}
+	var files []*ast.File
+	for _, f := range pass.Files {
+		if skipFile(pass, f) {
+			continue
+		}
+		files = append(files, f)
+	}
+	c.collectAllows(files)
+
+	heavySet := map[string]bool{}
+	for _, p := range strings.Split(heavy, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			heavySet[p] = true
+		}
+	}
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			path := strings.Trim(imp.Path.Value, `"`)
+			if heavySet[path] {
+				c.report(imp.Pos(), "import", "import of heavy package %s; avoid it in contract code", path)
+			}
+		}
+		if pass.Pkg.Name() == "main" {
+			c.checkExports(f)
+		}
+	}
+
+	// skipped files are excluded, so build a private inspector
+	ins := inspector.New(files)
+	filter := []ast.Node{
+		(*ast.GoStmt)(nil),
+		(*ast.DeferStmt)(nil),
+		(*ast.SelectStmt)(nil),
+		(*ast.SendStmt)(nil),
+		(*ast.UnaryExpr)(nil),
+		(*ast.ChanType)(nil),
+		(*ast.CallExpr)(nil),
+		(*ast.SelectorExpr)(nil),
+		(*ast.BinaryExpr)(nil),
+		(*ast.RangeStmt)(nil),
+	}
+	ins.WithStack(filter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			c.report(n.Pos(), "goroutine", "goroutines are disabled in contracts")
+		case *ast.DeferStmt:
+			c.report(n.Pos(), "defer", "defer is disabled in contracts; release on every return path instead")
+		case *ast.SelectStmt:
+			c.report(n.Pos(), "select", "select is unavailable: channels are disabled in contracts")
+		case *ast.SendStmt:
+			c.report(n.Pos(), "channel", "channel send: channels are disabled in contracts")
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				c.report(n.Pos(), "channel", "channel receive: channels are disabled in contracts")
+			}
+		case *ast.ChanType:
+			c.report(n.Pos(), "channel", "channel type: channels are disabled in contracts")
+		case *ast.CallExpr:
+			c.checkCall(n, stack)
+		case *ast.SelectorExpr:
+			c.checkTime(n)
+		case *ast.BinaryExpr:
+			if isFloat(pass.TypesInfo.TypeOf(n)) && !floatParent(pass, stack) {
+				c.report(n.Pos(), "float", "floating point arithmetic is not deterministic across nodes; use integer or fixed point math")
+			}
+		case *ast.RangeStmt:
+			c.checkMapRange(n)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+func skipFile(pass *analysis.Pass, f *ast.File) bool {
+	name := pass.Fset.File(f.Pos()).Name()
+	if strings.HasSuffix(name, "_test.go") {
+		return true
+	}
+	if ast.IsGenerated(f) {
+		return true
+	}
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			break
+		}
+		for _, cm := range cg.List {
+			if strings.HasPrefix(cm.Text, "//go:build") && strings.Contains(cm.Text, "!gc.custom") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *checker) collectAllows(files []*ast.File) {
+	c.allow = map[allowKey]bool{}
+	for _, f := range files {
+		for _, cg := range f.Comments {
+			for _, cm := range cg.List {
+				rest, ok := strings.CutPrefix(cm.Text, "//contractlint:allow")
+				if !ok {
+					continue
+				}
+				pos := c.pass.Fset.Position(cm.Pos())
+				checks := strings.Fields(rest)
+				if len(checks) == 0 {
+					checks = []string{""}
+				}
+				for _, check := range checks {
+					// a directive covers its own line and the next one
+					c.allow[allowKey{pos.Filename, pos.Line, check}] = true
+					c.allow[allowKey{pos.Filename, pos.Line + 1, check}] = true
+				}
+			}
+		}
+	}
+}
+
+func (c *checker) report(pos token.Pos, check, format string, args ...any) {
+	p := c.pass.Fset.Position(pos)
+	if c.allow[allowKey{p.Filename, p.Line, check}] || c.allow[allowKey{p.Filename, p.Line, ""}] {
+		return
+	}
+	c.pass.Reportf(pos, check+": "+format, args...)
+}
+
+func (c *checker) checkExports(f *ast.File) {
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !fn.Name.IsExported() {
+			continue
+		}
+		exported := false
+		if fn.Doc != nil {
+			for _, cm := range fn.Doc.List {
+				if strings.HasPrefix(cm.Text, "//go:wasmexport ") {
+					exported = true
+				}
+			}
+		}
+		if !exported {
+			c.report(fn.Name.Pos(), "export", "exported function %s is not a wasm entrypoint; add //go:wasmexport or unexport it", fn.Name.Name)
+		}
+	}
+}
+
+func (c *checker) checkCall(call *ast.CallExpr, stack []ast.Node) {
+	info := c.pass.TypesInfo
+	if id, ok := ast.Unparen(call.Fun).(*ast.Ident); ok {
+		if b, ok := info.Uses[id].(*types.Builtin); ok {
+			switch b.Name() {
+			case "recover":
+				c.report(call.Pos(), "recover", "recover is unavailable: panics always trap in contracts")
+			case "close":
+				c.report(call.Pos(), "channel", "close: channels are disabled in contracts")
+			}
+			return
+		}
+	}
+	// conversions to a float type
+	if tv, ok := info.Types[call.Fun]; ok && tv.IsType() && isFloat(tv.Type) && !floatParent(c.pass, stack) {
+		c.report(call.Pos(), "float", "conversion to %s: floating point is not deterministic across nodes", tv.Type)
+	}
+}
+
+func (c *checker) checkTime(sel *ast.SelectorExpr) {
+	fn, ok := c.pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "time" {
+		return
+	}
+	switch fn.Name() {
+	case "Now", "Since", "Until":
+		c.report(sel.Pos(), "time", "time.%s reads the node clock; use sdk.GetEnv().Timestamp", fn.Name())
+	}
+}
+
+// checkMapRange reports ranging over a map when the loop body writes state,
+// calls into the SDK or appends to a slice, since iteration order is random.
+func (c *checker) checkMapRange(rs *ast.RangeStmt) {
+	t := c.pass.TypesInfo.TypeOf(rs.X)
+	if t == nil {
+		return
+	}
+	if _, ok := t.Underlying().(*types.Map); !ok {
+		return
+	}
+	var effect string
+	ast.Inspect(rs.Body, func(n ast.Node) bool {
+		if effect != "" {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch fun := ast.Unparen(call.Fun).(type) {
+		case *ast.Ident:
+			if b, ok := c.pass.TypesInfo.Uses[fun].(*types.Builtin); ok && b.Name() == "append" {
+				effect = "appends to a slice"
+			}
+		case *ast.SelectorExpr:
+			if fn, ok := c.pass.TypesInfo.Uses[fun.Sel].(*types.Func); ok && fn.Pkg() != nil {
+				if p := fn.Pkg().Path(); p == sdkPath || strings.HasPrefix(p, sdkPath+"/") {
+					effect = "calls " + fn.Pkg().Name() + "." + fn.Name()
+				}
+			}
+		}
+		return true
+	})
+	if effect != "" {
+		c.report(rs.Pos(), "maprange", "map iteration order is random but the loop %s; iterate sorted keys instead", effect)
+	}
+}
+
+func isFloat(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsFloat != 0
+}
+
+// floatParent reports whether the innermost enclosing expression is already a
+// float binary expression or conversion, so one finding covers a whole formula.
+func floatParent(pass *analysis.Pass, stack []ast.Node) bool {
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch p := stack[i].(type) {
+		case *ast.ParenExpr:
+			continue
+		case *ast.BinaryExpr:
+			return isFloat(pass.TypesInfo.TypeOf(p))
+		case *ast.CallExpr:
+			tv, ok := pass.TypesInfo.Types[p.Fun]
+			return ok && tv.IsType() && isFloat(tv.Type)
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package contractlint_test
+
+import (
+	"testing"
+
+	"contract-template/tools/contractlint"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), contractlint.Analyzer, "a", "lib")
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"contract-template/sdk"
+	"fmt" // want `import: import of heavy package fmt`
+	"sort"
+	"time"
+)
+
+func main() {}
+
+//go:wasmexport entry
+func Entry(payload *string) *string {
+	go helper()         // want `goroutine: goroutines are disabled`
+	defer helper()      // want `defer: defer is disabled`
+	c := make(chan int) // want `channel: channel type`
+	c <- 1              // want `channel: channel send`
+	<-c                 // want `channel: channel receive`
+	close(c)            // want `channel: close`
+	select {}           // want `select: select is unavailable`
+}
+
+func Helper() {} // want `export: exported function Helper is not a wasm entrypoint`
+
+func helper() {
+	_ = recover()  // want `recover: recover is unavailable`
+	_ = time.Now() // want `time: time.Now reads the node clock`
+	fmt.Println()
+}
+
+func price(a, b uint64) uint64 {
+	f := float64(a) / float64(b) * 1.5 // want `float: floating point arithmetic`
+	g := float64(a)                    // want `float: conversion to float64`
+	_ = g
+	return uint64(f) + 1e3
+}
+
+func balances(m map[string]string) []string {
+	for k, v := range m { // want `maprange: map iteration order is random but the loop calls sdk.StateSetObject`
+		sdk.StateSetObject(k, v)
+	}
+	var out []string
+	for k := range m { // want `maprange: map iteration order is random but the loop appends to a slice`
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	n := 0
+	for range m {
+		n++
+	}
+	return out
+}
+
+//contractlint:allow time
+var started = time.Now()
+
+func allowed() {
+	defer helper() //contractlint:allow defer
+	go helper()    //contractlint:allow
+}
@@ -0,0 +1,7 @@
+//go:build !gc.custom
+
+package main
+
+func shimOnly() {
+	defer func() {}()
+}
@@ -0,0 +1,5 @@
+package sdk
+
+func StateSetObject(key, value string) {}
+
+func Log(s string) {}
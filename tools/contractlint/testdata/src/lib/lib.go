@@ -0,0 +1,4 @@
+// Package lib is not a contract main package: exported helpers are fine.
+package lib
+
+func Exported() {}
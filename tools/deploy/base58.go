@@ -0,0 +1,50 @@
+package deploy
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+const b58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix = big.NewInt(58)
+
+// base58Encode uses the Bitcoin alphabet, as Hive keys and did:key do.
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	var out []byte
+	mod := new(big.Int)
+	for x.Sign() > 0 {
+		x.DivMod(x, bigRadix, mod)
+		out = append(out, b58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, b58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	for _, r := range s {
+		i := strings.IndexRune(b58Alphabet, r)
+		if i < 0 {
+			return nil, errors.New("deploy: invalid base58 character")
+		}
+		x.Mul(x, bigRadix)
+		x.Add(x, big.NewInt(int64(i)))
+	}
+	out := x.Bytes()
+	zeros := 0
+	for zeros < len(s) && s[zeros] == b58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), out...), nil
+}
@@ -0,0 +1,194 @@
+// Package deploy builds and signs contract-creation transactions offline.
+//
+// The code CID is computed locally from the artifact, the vsc.create_contract
+// body is assembled from metadata, and the result is signed either as a Hive
+// custom_json transaction with an active key (WIF) or as a VSC transaction
+// with an ed25519 did:key. Signed transactions are plain JSON so they can be
+// reviewed, archived and broadcast later through an RPC implementation.
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// CreateContractId is the Hive custom_json id for contract deployment.
+const CreateContractId = "vsc.create_contract"
+
+const DefaultNetId = "vsc-mainnet"
+
+// Metadata describes the contract being deployed.
+type Metadata struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Owner       string `json:"owner"` // hive:<account> or did:key:...; defaults to the signer
+}
+
+// CreateContract is the body of a vsc.create_contract transaction.
+type CreateContract struct {
+	Version     string `json:"__v"`
+	NetId       string `json:"net_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Owner       string `json:"owner"`
+	Code        string `json:"code"`
+	Runtime     string `json:"runtime"`
+}
+
+// Code identifies a wasm artifact.
+type Code struct {
+	CID    string `json:"cid"`
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+}
+
+var cidBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// CodeOf hashes an artifact. The CID is the one `ipfs add --cid-version=1`
+// yields: a raw block CID for artifacts of up to 256 KiB, the root of a
+// UnixFS DAG for larger ones, with a sha2-256 multihash, in base32.
+func CodeOf(wasm []byte) Code {
+	sum := sha256.Sum256(wasm)
+	return Code{
+		CID:    "b" + strings.ToLower(cidBase32.EncodeToString(unixfsRoot(wasm))),
+		SHA256: hex.EncodeToString(sum[:]),
+		Size:   len(wasm),
+	}
+}
+
+// Options control transaction building. Hive signing needs a reference block
+// and expiration; did:key signing ignores them.
+type Options struct {
+	NetId      string
+	Account    string // Hive account authorizing the custom_json; defaults to the owner's
+	ChainId    string // hex; defaults to Hive mainnet
+	Ref        RefBlock
+	Expiration time.Time
+	CID        string // code CID to deploy instead of CodeOf's, e.g. one pinned with other import settings
+}
+
+// Key signs deploy transactions.
+type Key interface {
+	// Identity is the address the key signs as, e.g. did:key:z6Mk...; Hive keys
+	// return "" since the account is chosen per transaction.
+	Identity() string
+	sign(body []byte, opts Options) (kind string, txId string, tx json.RawMessage, err error)
+}
+
+// Signed is the file written by the deploy command.
+type Signed struct {
+	Kind        string          `json:"kind"` // "hive" or "did"
+	Code        Code            `json:"code"`
+	Contract    CreateContract  `json:"contract"`
+	Signer      string          `json:"signer"`
+	TxId        string          `json:"tx_id"`
+	Transaction json.RawMessage `json:"transaction"`
+}
+
+const (
+	KindHive = "hive"
+	KindDID  = "did"
+)
+
+// Build assembles and signs the creation transaction for wasm.
+func Build(wasm []byte, meta Metadata, key Key, opts Options) (*Signed, error) {
+	if len(wasm) == 0 {
+		return nil, errors.New("deploy: empty artifact")
+	}
+	if meta.Name == "" {
+		return nil, errors.New("deploy: name is required")
+	}
+	if opts.NetId == "" {
+		opts.NetId = DefaultNetId
+	}
+	if meta.Owner == "" {
+		switch {
+		case key.Identity() != "":
+			meta.Owner = key.Identity()
+		case opts.Account != "":
+			meta.Owner = "hive:" + opts.Account
+		default:
+			return nil, errors.New("deploy: owner or account is required")
+		}
+	}
+	if opts.Account == "" {
+		opts.Account, _ = strings.CutPrefix(meta.Owner, "hive:")
+		if opts.Account == meta.Owner {
+			opts.Account = ""
+		}
+	}
+	code := CodeOf(wasm)
+	if opts.CID != "" {
+		raw, err := cidBase32.DecodeString(strings.ToUpper(strings.TrimPrefix(opts.CID, "b")))
+		if err != nil || !strings.HasPrefix(opts.CID, "b") || len(raw) < 4 || raw[0] != 0x01 {
+			return nil, fmt.Errorf("deploy: %q is not a base32 CIDv1", opts.CID)
+		}
+		code.CID = opts.CID
+	}
+	body := CreateContract{
+		Version:     "0.1",
+		NetId:       opts.NetId,
+		Name:        meta.Name,
+		Description: meta.Description,
+		Owner:       meta.Owner,
+		Code:        code.CID,
+		Runtime:     "go",
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	kind, txId, tx, err := key.sign(b, opts)
+	if err != nil {
+		return nil, err
+	}
+	signer := key.Identity()
+	if signer == "" {
+		signer = "hive:" + opts.Account
+	}
+	return &Signed{Kind: kind, Code: code, Contract: body, Signer: signer, TxId: txId, Transaction: tx}, nil
+}
+
+// Marshal renders the signed transaction as indented JSON with a trailing
+// newline.
+func (s *Signed) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// LoadSigned reads a file written by Marshal.
+func LoadSigned(path string) (*Signed, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Signed{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// LoadKey reads a key file: a Hive WIF private key, or a hex ed25519 seed
+// (32 bytes) or private key (64 bytes) for a did:key.
+func LoadKey(path string) (Key, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimSpace(string(b))
+	if raw, err := hex.DecodeString(s); err == nil {
+		return NewDIDKey(raw)
+	}
+	return ParseWIF(s)
+}
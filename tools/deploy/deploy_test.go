@@ -0,0 +1,234 @@
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+const testWIF = "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ"
+
+var testExp = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestCodeOf_RawCIDv1(t *testing.T) {
+	// CID of the empty block, as printed by `ipfs add --cid-version=1 --raw-leaves`
+	if got := CodeOf(nil).CID; got != "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku" {
+		t.Fatalf("cid = %s", got)
+	}
+	c := CodeOf([]byte("wasm"))
+	sum := sha256.Sum256([]byte("wasm"))
+	if c.SHA256 != hex.EncodeToString(sum[:]) || c.Size != 4 || !strings.HasPrefix(c.CID, "bafkrei") {
+		t.Fatalf("code = %+v", c)
+	}
+	if CodeOf(make([]byte, chunkSize)).CID != "b"+strings.ToLower(cidBase32.EncodeToString(cidOf(codecRaw, make([]byte, chunkSize)))) {
+		t.Fatal("one chunk is not a raw block")
+	}
+
+	// two chunks: a dag-pb root linking both raw leaves, then the UnixFS data
+	wasm := make([]byte, chunkSize+1)
+	var root []byte
+	for _, leaf := range [][]byte{wasm[:chunkSize], wasm[chunkSize:]} {
+		link := append([]byte{0x0a, 0x24}, cidOf(codecRaw, leaf)...)
+		link = append(link, 0x12, 0x00, 0x18)
+		link = binary.AppendUvarint(link, uint64(len(leaf)))
+		root = append(append(root, 0x12, byte(len(link))), link...)
+	}
+	root = append(root, 0x0a, 0x0c, 0x08, 0x02, 0x18, 0x81, 0x80, 0x10, 0x20, 0x80, 0x80, 0x10, 0x20, 0x01)
+	if got := CodeOf(wasm).CID; got != "b"+strings.ToLower(cidBase32.EncodeToString(cidOf(codecDagPB, root))) || !strings.HasPrefix(got, "bafybei") {
+		t.Fatalf("cid = %s", got)
+	}
+}
+
+func TestParseWIF(t *testing.T) {
+	k, err := ParseWIF(testWIF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(k.priv.Serialize()) != "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d" {
+		t.Fatal("wrong private key")
+	}
+	if k.WIF() != testWIF {
+		t.Fatal("WIF does not round trip")
+	}
+	if pub := k.PublicKey(); !strings.HasPrefix(pub, "STM") || len(pub) != 53 {
+		t.Fatalf("public key = %s", pub)
+	}
+	if _, err := ParseWIF(testWIF[:len(testWIF)-1] + "K"); err == nil {
+		t.Fatal("bad checksum accepted")
+	}
+}
+
+func TestBuild_HiveSignatureRecoversSigner(t *testing.T) {
+	k, _ := ParseWIF(testWIF)
+	ref, err := RefBlockOf(0x1234abcd, "0000abcd78563412000000000000000000000000")
+	if err != nil || ref.Num != 0xabcd || ref.Prefix != 0x12345678 {
+		t.Fatalf("ref = %+v, %v", ref, err)
+	}
+	s, err := Build([]byte("\x00asm"), Metadata{Name: "amm", Description: "pool", Owner: "hive:alice"}, k, Options{Ref: ref, Expiration: testExp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Kind != KindHive || s.Signer != "hive:alice" || len(s.TxId) != 40 {
+		t.Fatalf("signed = %+v", s)
+	}
+
+	var tx HiveTx
+	if err := json.Unmarshal(s.Transaction, &tx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.Expiration != "2025-01-02T03:04:05" || tx.RefBlockNum != ref.Num || len(tx.Signatures) != 1 {
+		t.Fatalf("tx = %+v", tx)
+	}
+	op := tx.Operations[0][1].(map[string]any)
+	if tx.Operations[0][0] != "custom_json" || op["id"] != CreateContractId || op["required_auths"].([]any)[0] != "alice" {
+		t.Fatalf("op = %v", tx.Operations)
+	}
+	var body CreateContract
+	if err := json.Unmarshal([]byte(op["json"].(string)), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body != s.Contract || body.Code != CodeOf([]byte("\x00asm")).CID || body.NetId != DefaultNetId {
+		t.Fatalf("body = %+v", body)
+	}
+
+	// rebuild the signed digest and recover the public key from the signature
+	rebuilt := &HiveTx{RefBlockNum: ref.Num, RefBlockPrefix: ref.Prefix, expiration: testExp,
+		customJSON: &customJSONBody{RequiredAuths: []string{"alice"}, RequiredPostingAuths: []string{}, Id: CreateContractId, Json: op["json"].(string)}}
+	raw := rebuilt.serialize()
+	chain, _ := hex.DecodeString(HiveChainId)
+	digest := sha256.Sum256(append(chain, raw...))
+	sig, _ := hex.DecodeString(tx.Signatures[0])
+	if !isCanonical(sig) {
+		t.Fatal("signature not canonical")
+	}
+	pub, compressed, err := ecdsa.RecoverCompact(sig, digest[:])
+	if err != nil || !compressed || hivePublicKey(pub.SerializeCompressed()) != k.PublicKey() {
+		t.Fatalf("recovered %v compressed=%v err=%v", pub, compressed, err)
+	}
+	id := sha256.Sum256(raw)
+	if s.TxId != hex.EncodeToString(id[:20]) {
+		t.Fatal("tx id does not match serialized transaction")
+	}
+}
+
+func TestBuild_HiveNeedsAccountAndExpiration(t *testing.T) {
+	k, _ := ParseWIF(testWIF)
+	if _, err := Build([]byte("x"), Metadata{Name: "a"}, k, Options{Expiration: testExp}); err == nil {
+		t.Fatal("missing owner/account accepted")
+	}
+	if _, err := Build([]byte("x"), Metadata{Name: "a", Owner: "hive:alice"}, k, Options{}); err == nil {
+		t.Fatal("missing expiration accepted")
+	}
+	if _, err := Build([]byte("x"), Metadata{Name: "a", Owner: "did:key:zabc"}, k, Options{Expiration: testExp}); err == nil {
+		t.Fatal("did owner without hive account accepted")
+	}
+	if _, err := Build(nil, Metadata{Name: "a", Owner: "hive:alice"}, k, Options{Expiration: testExp}); err == nil {
+		t.Fatal("empty artifact accepted")
+	}
+	s, err := Build(make([]byte, chunkSize+1), Metadata{Name: "a", Owner: "hive:alice"}, k, Options{Expiration: testExp})
+	if err != nil || s.Contract.Code != s.Code.CID || !strings.HasPrefix(s.Code.CID, "bafybei") {
+		t.Fatalf("multi-block artifact: %v", err)
+	}
+	const pinned = "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+	if s, err := Build([]byte("x"), Metadata{Name: "a", Owner: "hive:alice"}, k, Options{Expiration: testExp, CID: pinned}); err != nil || s.Contract.Code != pinned || s.Code.CID != pinned {
+		t.Fatalf("cid override: %v", err)
+	}
+	if _, err := Build([]byte("x"), Metadata{Name: "a", Owner: "hive:alice"}, k, Options{Expiration: testExp, CID: "Qmabc"}); err == nil {
+		t.Fatal("non-CIDv1 override accepted")
+	}
+}
+
+func TestBuild_DIDKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deployer.key")
+	os.WriteFile(path, []byte(strings.Repeat("01", 32)+"\n"), 0o600)
+	key, err := LoadKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key.Identity(), "did:key:z6Mk") {
+		t.Fatalf("did = %s", key.Identity())
+	}
+	s, err := Build([]byte("\x00asm"), Metadata{Name: "token"}, key, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Kind != KindDID || s.Contract.Owner != key.Identity() || s.Signer != key.Identity() {
+		t.Fatalf("signed = %+v", s)
+	}
+	var tx DIDTx
+	json.Unmarshal(s.Transaction, &tx)
+	if err := VerifyDID(&tx); err != nil {
+		t.Fatal(err)
+	}
+	tx.Body = json.RawMessage(strings.Replace(string(tx.Body), "token", "t0ken", 1))
+	if VerifyDID(&tx) == nil {
+		t.Fatal("tampered body verified")
+	}
+
+	// the file round trips
+	b, _ := s.Marshal()
+	out := filepath.Join(dir, "deploy.json")
+	os.WriteFile(out, b, 0o644)
+	back, err := LoadSigned(out)
+	if err != nil || back.TxId != s.TxId || back.Contract != s.Contract {
+		t.Fatalf("loaded %+v, %v", back, err)
+	}
+}
+
+func TestFakeRPC_Broadcast(t *testing.T) {
+	rpc := &FakeRPC{Ref: RefBlock{Num: 7, Prefix: 9}}
+	ref, _ := rpc.RefBlock(context.Background())
+	k, _ := ParseWIF(testWIF)
+	s, err := Build([]byte("x"), Metadata{Name: "a", Owner: "hive:alice"}, k, Options{Ref: ref, Expiration: testExp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := rpc.Broadcast(context.Background(), s)
+	if err != nil || id != s.TxId || len(rpc.Broadcasts()) != 1 {
+		t.Fatalf("broadcast id=%s err=%v n=%d", id, err, len(rpc.Broadcasts()))
+	}
+}
+
+func TestHiveRPC(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var req struct{ Method string }
+		json.Unmarshal(b, &req)
+		methods = append(methods, req.Method)
+		switch req.Method {
+		case "condenser_api.get_dynamic_global_properties":
+			io.WriteString(w, `{"result":{"head_block_number":66000000,"head_block_id":"03ef1480aabbccdd000000000000000000000000"}}`)
+		default:
+			io.WriteString(w, `{"result":{}}`)
+		}
+	}))
+	defer srv.Close()
+	rpc := &HiveRPC{URL: srv.URL}
+	ref, err := rpc.RefBlock(context.Background())
+	if err != nil || ref.Num != uint16(66000000&0xffff) || ref.Prefix != 0xddccbbaa {
+		t.Fatalf("ref = %+v, %v", ref, err)
+	}
+	if _, err := rpc.Broadcast(context.Background(), &Signed{Kind: KindDID}); err == nil {
+		t.Fatal("did transaction broadcast to hive")
+	}
+	if _, err := rpc.Broadcast(context.Background(), &Signed{Kind: KindHive, Transaction: json.RawMessage(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 2 || methods[1] != "condenser_api.broadcast_transaction" {
+		t.Fatalf("methods = %v", methods)
+	}
+}
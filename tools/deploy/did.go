@@ -0,0 +1,75 @@
+package deploy
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// DIDKey is an ed25519 did:key.
+type DIDKey struct {
+	priv ed25519.PrivateKey
+}
+
+// NewDIDKey accepts a 32 byte seed or a 64 byte ed25519 private key.
+func NewDIDKey(raw []byte) (*DIDKey, error) {
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return &DIDKey{priv: ed25519.NewKeyFromSeed(raw)}, nil
+	case ed25519.PrivateKeySize:
+		return &DIDKey{priv: ed25519.PrivateKey(raw)}, nil
+	}
+	return nil, errors.New("deploy: ed25519 key must be a 32 byte seed or 64 byte private key")
+}
+
+// Identity returns did:key:z6Mk..., the multibase base58btc encoding of the
+// multicodec ed25519-pub prefixed public key.
+func (k *DIDKey) Identity() string {
+	pub := k.priv.Public().(ed25519.PublicKey)
+	return "did:key:z" + base58Encode(append([]byte{0xed, 0x01}, pub...))
+}
+
+// DIDTx is a transaction body signed by a did:key. The signature covers the
+// exact bytes of Body; the transaction id is the CID of the signed JSON.
+type DIDTx struct {
+	Id        string          `json:"id"`
+	Body      json.RawMessage `json:"body"`
+	Signer    string          `json:"signer"`
+	Signature string          `json:"signature"` // base64url, no padding
+}
+
+func (k *DIDKey) sign(body []byte, _ Options) (string, string, json.RawMessage, error) {
+	tx := DIDTx{
+		Id:        CreateContractId,
+		Body:      body,
+		Signer:    k.Identity(),
+		Signature: base64.RawURLEncoding.EncodeToString(ed25519.Sign(k.priv, body)),
+	}
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return KindDID, CodeOf(b).CID, b, nil
+}
+
+// VerifyDID checks the signature of a did:key transaction.
+func VerifyDID(tx *DIDTx) error {
+	rest, ok := strings.CutPrefix(tx.Signer, "did:key:z")
+	if !ok {
+		return errors.New("deploy: signer is not a did:key")
+	}
+	raw, err := base58Decode(rest)
+	if err != nil || len(raw) != 2+ed25519.PublicKeySize || raw[0] != 0xed || raw[1] != 0x01 {
+		return errors.New("deploy: signer is not an ed25519 did:key")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(tx.Signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(raw[2:]), tx.Body, sig) {
+		return errors.New("deploy: bad signature")
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package deploy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// HiveChainId is the Hive mainnet chain id signatures commit to.
+const HiveChainId = "beeab0de00000000000000000000000000000000000000000000000000000000"
+
+const opCustomJSON = 18
+
+// RefBlock is the TaPoS reference of a Hive transaction.
+type RefBlock struct {
+	Num    uint16 `json:"ref_block_num"`
+	Prefix uint32 `json:"ref_block_prefix"`
+}
+
+// RefBlockOf derives the reference from a head block number and id.
+func RefBlockOf(headNum uint32, headId string) (RefBlock, error) {
+	id, err := hex.DecodeString(headId)
+	if err != nil || len(id) != 20 {
+		return RefBlock{}, errors.New("deploy: head block id must be 20 bytes of hex")
+	}
+	return RefBlock{Num: uint16(headNum), Prefix: binary.LittleEndian.Uint32(id[4:8])}, nil
+}
+
+// HiveKey is a Hive active private key.
+type HiveKey struct {
+	priv *secp256k1.PrivateKey
+}
+
+// ParseWIF decodes a Hive private key in wallet import format.
+func ParseWIF(wif string) (*HiveKey, error) {
+	b, err := base58Decode(wif)
+	if err != nil || len(b) != 37 || b[0] != 0x80 {
+		return nil, errors.New("deploy: not a WIF private key")
+	}
+	h := sha256.Sum256(b[:33])
+	h = sha256.Sum256(h[:])
+	if !bytes.Equal(h[:4], b[33:]) {
+		return nil, errors.New("deploy: WIF checksum mismatch")
+	}
+	return &HiveKey{priv: secp256k1.PrivKeyFromBytes(b[1:33])}, nil
+}
+
+// WIF encodes the key in wallet import format.
+func (k *HiveKey) WIF() string {
+	b := append([]byte{0x80}, k.priv.Serialize()...)
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	return base58Encode(append(b, h[:4]...))
+}
+
+// PublicKey returns the key in Hive's STM... form.
+func (k *HiveKey) PublicKey() string {
+	return hivePublicKey(k.priv.PubKey().SerializeCompressed())
+}
+
+func hivePublicKey(pub []byte) string {
+	r := ripemd160.New()
+	r.Write(pub)
+	return "STM" + base58Encode(append(pub, r.Sum(nil)[:4]...))
+}
+
+func (k *HiveKey) Identity() string { return "" }
+
+// HiveTx is a Hive transaction in condenser API JSON form.
+type HiveTx struct {
+	RefBlockNum    uint16          `json:"ref_block_num"`
+	RefBlockPrefix uint32          `json:"ref_block_prefix"`
+	Expiration     string          `json:"expiration"`
+	Operations     [][2]any        `json:"operations"`
+	Extensions     []any           `json:"extensions"`
+	Signatures     []string        `json:"signatures"`
+	customJSON     *customJSONBody `json:"-"`
+	expiration     time.Time       `json:"-"`
+}
+
+type customJSONBody struct {
+	RequiredAuths        []string `json:"required_auths"`
+	RequiredPostingAuths []string `json:"required_posting_auths"`
+	Id                   string   `json:"id"`
+	Json                 string   `json:"json"`
+}
+
+func (k *HiveKey) sign(body []byte, opts Options) (string, string, json.RawMessage, error) {
+	if opts.Account == "" {
+		return "", "", nil, errors.New("deploy: hive signing needs an account")
+	}
+	if opts.Expiration.IsZero() {
+		return "", "", nil, errors.New("deploy: hive signing needs an expiration")
+	}
+	chainId := opts.ChainId
+	if chainId == "" {
+		chainId = HiveChainId
+	}
+	chain, err := hex.DecodeString(chainId)
+	if err != nil || len(chain) != 32 {
+		return "", "", nil, errors.New("deploy: chain id must be 32 bytes of hex")
+	}
+	op := &customJSONBody{RequiredAuths: []string{opts.Account}, RequiredPostingAuths: []string{}, Id: CreateContractId, Json: string(body)}
+	exp := opts.Expiration.UTC().Truncate(time.Second)
+	tx := &HiveTx{
+		RefBlockNum:    opts.Ref.Num,
+		RefBlockPrefix: opts.Ref.Prefix,
+		Expiration:     exp.Format("2006-01-02T15:04:05"),
+		Operations:     [][2]any{{"custom_json", op}},
+		Extensions:     []any{},
+		customJSON:     op,
+		expiration:     exp,
+	}
+	raw := tx.serialize()
+	digest := sha256.Sum256(append(chain, raw...))
+	sig, err := signCanonical(k.priv, digest[:])
+	if err != nil {
+		return "", "", nil, err
+	}
+	tx.Signatures = []string{hex.EncodeToString(sig)}
+	id := sha256.Sum256(raw)
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return KindHive, hex.EncodeToString(id[:20]), b, nil
+}
+
+// serialize writes the binary form Hive hashes for signatures and tx ids.
+func (tx *HiveTx) serialize() []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, tx.RefBlockNum)
+	binary.Write(&b, binary.LittleEndian, tx.RefBlockPrefix)
+	binary.Write(&b, binary.LittleEndian, uint32(tx.expiration.Unix()))
+	writeVarint(&b, 1)
+	writeVarint(&b, opCustomJSON)
+	op := tx.customJSON
+	writeStrings(&b, op.RequiredAuths)
+	writeStrings(&b, op.RequiredPostingAuths)
+	writeString(&b, op.Id)
+	writeString(&b, op.Json)
+	writeVarint(&b, 0) // extensions
+	return b.Bytes()
+}
+
+func writeVarint(b *bytes.Buffer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	b.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func writeString(b *bytes.Buffer, s string) {
+	writeVarint(b, uint64(len(s)))
+	b.WriteString(s)
+}
+
+func writeStrings(b *bytes.Buffer, ss []string) {
+	writeVarint(b, uint64(len(ss)))
+	for _, s := range ss {
+		writeString(b, s)
+	}
+}
+
+// signCanonical returns a 65 byte compact recoverable signature that Hive
+// accepts. Hive rejects signatures whose r or s needs a sign byte in DER, so
+// RFC 6979 nonces are iterated until both are canonical.
+func signCanonical(priv *secp256k1.PrivateKey, hash []byte) ([]byte, error) {
+	var e secp256k1.ModNScalar
+	e.SetByteSlice(hash)
+	key := priv.Serialize()
+	for iter := uint32(0); iter < 1000; iter++ {
+		k := secp256k1.NonceRFC6979(key, hash, nil, nil, iter)
+		var R secp256k1.JacobianPoint
+		secp256k1.ScalarBaseMultNonConst(k, &R)
+		R.ToAffine()
+		var r secp256k1.ModNScalar
+		overflow := r.SetByteSlice(R.X.Bytes()[:])
+		if r.IsZero() {
+			continue
+		}
+		recid := byte(R.Y.IsOddBit())
+		if overflow {
+			recid |= 2
+		}
+		// s = k^-1 (e + r*d)
+		var s secp256k1.ModNScalar
+		s.Mul2(&priv.Key, &r).Add(&e)
+		var kinv secp256k1.ModNScalar
+		kinv.InverseValNonConst(k)
+		s.Mul(&kinv)
+		if s.IsZero() {
+			continue
+		}
+		if s.IsOverHalfOrder() {
+			s.Negate()
+			recid ^= 1
+		}
+		sig := make([]byte, 65)
+		sig[0] = 27 + 4 + recid // compressed public key
+		r.PutBytesUnchecked(sig[1:33])
+		s.PutBytesUnchecked(sig[33:65])
+		if isCanonical(sig) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("deploy: no canonical signature found")
+}
+
+func isCanonical(sig []byte) bool {
+	return sig[1]&0x80 == 0 && !(sig[1] == 0 && sig[2]&0x80 == 0) &&
+		sig[33]&0x80 == 0 && !(sig[33] == 0 && sig[34]&0x80 == 0)
+}
@@ -0,0 +1,113 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// RPC is the network side of a deployment: it supplies the reference block
+// for Hive transactions and broadcasts signed ones.
+type RPC interface {
+	RefBlock(ctx context.Context) (RefBlock, error)
+	Broadcast(ctx context.Context, s *Signed) (txId string, err error)
+}
+
+// HiveRPC talks to a Hive API node over JSON-RPC. It broadcasts Hive-signed
+// transactions only.
+type HiveRPC struct {
+	URL    string
+	Client *http.Client
+}
+
+func (h *HiveRPC) call(ctx context.Context, method string, params, result any) error {
+	req, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	hr, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(req))
+	if err != nil {
+		return err
+	}
+	hr.Header.Set("Content-Type", "application/json")
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(hr)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("deploy: %s: %w", method, err)
+	}
+	if out.Error != nil {
+		return fmt.Errorf("deploy: %s: %s", method, out.Error.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(out.Result, result)
+}
+
+func (h *HiveRPC) RefBlock(ctx context.Context) (RefBlock, error) {
+	var props struct {
+		HeadBlockNumber uint32 `json:"head_block_number"`
+		HeadBlockId     string `json:"head_block_id"`
+	}
+	if err := h.call(ctx, "condenser_api.get_dynamic_global_properties", []any{}, &props); err != nil {
+		return RefBlock{}, err
+	}
+	return RefBlockOf(props.HeadBlockNumber, props.HeadBlockId)
+}
+
+func (h *HiveRPC) Broadcast(ctx context.Context, s *Signed) (string, error) {
+	if s.Kind != KindHive {
+		return "", errors.New("deploy: only hive-signed transactions can be broadcast to a Hive node")
+	}
+	if err := h.call(ctx, "condenser_api.broadcast_transaction", []any{s.Transaction}, nil); err != nil {
+		return "", err
+	}
+	return s.TxId, nil
+}
+
+// FakeRPC records broadcasts in memory, for tests and dry runs.
+type FakeRPC struct {
+	Ref RefBlock
+	Err error // returned by every call when set
+
+	mu        sync.Mutex
+	broadcast []*Signed
+}
+
+func (f *FakeRPC) RefBlock(context.Context) (RefBlock, error) {
+	return f.Ref, f.Err
+}
+
+func (f *FakeRPC) Broadcast(_ context.Context, s *Signed) (string, error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.broadcast = append(f.broadcast, s)
+	return s.TxId, nil
+}
+
+// Broadcasts returns the transactions broadcast so far.
+func (f *FakeRPC) Broadcasts() []*Signed {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*Signed(nil), f.broadcast...)
+}
@@ -0,0 +1,86 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// UnixFS import settings of `ipfs add --cid-version=1` (kubo defaults):
+// fixed-size chunks stored as raw leaves under a balanced tree of dag-pb
+// nodes.
+const (
+	chunkSize    = 256 << 10
+	linksPerNode = 174
+)
+
+const (
+	codecRaw   = 0x55
+	codecDagPB = 0x70
+)
+
+// dagNode is a block of the imported DAG as its parent links to it.
+type dagNode struct {
+	cid      []byte // binary CIDv1
+	tsize    uint64 // the block's size plus the tsize of its children
+	fileSize uint64 // file bytes below the block
+}
+
+// cidOf is the binary CIDv1 of block with a sha2-256 multihash.
+func cidOf(codec byte, block []byte) []byte {
+	sum := sha256.Sum256(block)
+	return append([]byte{0x01, codec, 0x12, 0x20}, sum[:]...)
+}
+
+// unixfsRoot returns the root CID of data as imported by ipfs add. Data that
+// fits in one chunk is a single raw block; longer data gets a dag-pb root
+// with all leaves at the same depth.
+func unixfsRoot(data []byte) []byte {
+	var level []dagNode
+	for off := 0; off == 0 || off < len(data); off += chunkSize {
+		chunk := data[off:min(off+chunkSize, len(data))]
+		level = append(level, dagNode{cid: cidOf(codecRaw, chunk), tsize: uint64(len(chunk)), fileSize: uint64(len(chunk))})
+	}
+	for len(level) > 1 {
+		var next []dagNode
+		for i := 0; i < len(level); i += linksPerNode {
+			next = append(next, fileNode(level[i:min(i+linksPerNode, len(level))]))
+		}
+		level = next
+	}
+	return level[0].cid
+}
+
+// fileNode encodes a UnixFS file node over children. dag-pb puts the links
+// (field 2) before the data (field 1); links carry an empty name.
+func fileNode(children []dagNode) dagNode {
+	var n dagNode
+	for _, c := range children {
+		n.fileSize += c.fileSize
+	}
+	data := []byte{0x08, 0x02} // Type: File
+	data = pbUvarint(data, 3, n.fileSize)
+	for _, c := range children {
+		data = pbUvarint(data, 4, c.fileSize) // blocksizes
+	}
+	var block []byte
+	for _, c := range children {
+		link := pbBytes(nil, 1, c.cid)
+		link = pbBytes(link, 2, nil)
+		link = pbUvarint(link, 3, c.tsize)
+		block = pbBytes(block, 2, link)
+		n.tsize += c.tsize
+	}
+	block = pbBytes(block, 1, data)
+	n.cid = cidOf(codecDagPB, block)
+	n.tsize += uint64(len(block))
+	return n
+}
+
+func pbUvarint(b []byte, field byte, v uint64) []byte {
+	return binary.AppendUvarint(append(b, field<<3), v)
+}
+
+func pbBytes(b []byte, field byte, v []byte) []byte {
+	b = binary.AppendUvarint(append(b, field<<3|2), uint64(len(v)))
+	return append(b, v...)
+}
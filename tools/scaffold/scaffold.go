@@ -0,0 +1,184 @@
+// Package scaffold creates new contract packages from the maintained examples.
+//
+// A template is the Go source and testdata of an existing package in this
+// module, copied as is, so scaffolded contracts start from code that is built
+// and tested with the rest of the tree. Every template ships entrypoints,
+// shim tests and a testdata/scenario.json replayed by
+// contract-template/sdk/scenario; New adds a Makefile with build targets.
+// Templates with their own state (empty, amm-v2, clmm) declare it as typed
+// stateKey constants in keys.go; the token templates keep theirs in
+// sdk/fungible, sdk/nft and sdk/multitoken.
+package scaffold
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Module is the module path generated contracts import the SDK from.
+const Module = "contract-template"
+
+type Template struct {
+	Name string
+	Dir  string // source package, relative to the module root
+	Doc  string
+}
+
+var Templates = []Template{
+	{"empty", "contract", "entrypoint skeleton with typed state keys"},
+	{"token", "examples/token", "fungible token with allowances and a capped supply"},
+	{"nft", "examples/nft", "non-fungible token collection with approvals and enumeration"},
+	{"multitoken", "examples/multitoken", "multi-token contract with batch transfers, supply caps and per-id minters"},
+	{"amm-v2", "examples/v2-amm", "constant product AMM with fees, referrals and typed state keys"},
+	{"clmm", "examples/v3", "concentrated liquidity AMM with schema migrations and typed state keys"},
+}
+
+// Lookup returns the template called name.
+func Lookup(name string) (Template, bool) {
+	for _, t := range Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+var validName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ModuleRoot walks up from dir to the directory holding the contract-template
+// go.mod.
+func ModuleRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if mod, err := modulePath(filepath.Join(dir, "go.mod")); err == nil && mod == Module {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("scaffold: not inside the %s module", Module)
+		}
+		dir = parent
+	}
+}
+
+func modulePath(gomod string) (string, error) {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if mod, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(mod), `"`), nil
+		}
+	}
+	return "", errors.New("no module line")
+}
+
+// New creates parent/name from template tmpl and returns the new directory.
+// parent must be inside the module so the package can import the SDK.
+func New(root, tmpl, parent, name string) (string, error) {
+	t, ok := Lookup(tmpl)
+	if !ok {
+		return "", fmt.Errorf("scaffold: unknown template %q", tmpl)
+	}
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("scaffold: invalid contract name %q (lower case letters, digits, - and _)", name)
+	}
+	out, err := filepath.Abs(filepath.Join(parent, name))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, out); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("scaffold: %s is outside the module at %s", out, root)
+	}
+	if _, err := os.Stat(out); err == nil {
+		return "", fmt.Errorf("scaffold: %s already exists", out)
+	}
+
+	src := filepath.Join(root, t.Dir)
+	oldId, err := scenarioContractId(src)
+	if err != nil {
+		return "", err
+	}
+	newId := "contract:" + name
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if d.IsDir() {
+			if rel == "." || rel == "testdata" || strings.HasPrefix(rel, "testdata"+string(filepath.Separator)) {
+				return nil
+			}
+			return filepath.SkipDir
+		}
+		if !strings.HasSuffix(rel, ".go") && !strings.HasPrefix(rel, "testdata"+string(filepath.Separator)) {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if rel == filepath.Join("testdata", "scenario.json") {
+			b = []byte(strings.ReplaceAll(string(b), `"`+oldId+`"`, `"`+newId+`"`))
+		}
+		dst := filepath.Join(out, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, b, 0o644)
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, os.WriteFile(filepath.Join(out, "Makefile"), makefile(name, root, out), 0o644)
+}
+
+// scenarioContractId reads the contract id the template's scenario runs as.
+func scenarioContractId(dir string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "testdata", "scenario.json"))
+	if err != nil {
+		return "", fmt.Errorf("scaffold: template has no scenario: %w", err)
+	}
+	m := regexp.MustCompile(`"contract_id":\s*"([^"]+)"`).FindSubmatch(b)
+	if m == nil {
+		return "", errors.New("scaffold: template scenario has no contract_id")
+	}
+	return string(m[1]), nil
+}
+
+func makefile(name, root, out string) []byte {
+	rel, _ := filepath.Rel(out, root)
+	rel = filepath.ToSlash(rel)
+	return []byte(fmt.Sprintf(`# Build targets for the %[1]s contract.
+ROOT := %[2]s
+WASM := $(ROOT)/artifacts/%[1]s.wasm
+
+.PHONY: build test abi vet
+
+build:
+	mkdir -p $(ROOT)/artifacts
+	tinygo build -o $(WASM) -gc=custom -scheduler=none -panic=trap -no-debug -target=wasm-unknown .
+
+test:
+	go test .
+
+abi: build
+	go run %[3]s/cmd/contract abi -wasm $(WASM) .
+
+vet:
+	go build -o $(ROOT)/bin/contractvet %[3]s/cmd/contractvet
+	go vet -vettool=$(abspath $(ROOT)/bin/contractvet) .
+`, name, rel, Module))
+}
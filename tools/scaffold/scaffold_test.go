@@ -0,0 +1,63 @@
+package scaffold
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNew_EveryTemplateBuildsAndPassesItsTests(t *testing.T) {
+	root, err := ModuleRoot(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a dot directory keeps the packages out of ./... while they exist
+	parent, err := os.MkdirTemp(root, ".scaffold-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(parent) })
+
+	for _, tmpl := range Templates {
+		name := "c" + strings.ReplaceAll(tmpl.Name, "-", "")
+		out, err := New(root, tmpl.Name, parent, name)
+		if err != nil {
+			t.Fatalf("%s: %v", tmpl.Name, err)
+		}
+		for _, f := range []string{"main.go", "scenario_test.go", "testdata/scenario.json", "Makefile"} {
+			if _, err := os.Stat(filepath.Join(out, f)); err != nil {
+				t.Errorf("%s: missing %s", tmpl.Name, f)
+			}
+		}
+		sc, _ := os.ReadFile(filepath.Join(out, "testdata", "scenario.json"))
+		if !strings.Contains(string(sc), `"contract:`+name+`"`) {
+			t.Errorf("%s: scenario contract id not renamed", tmpl.Name)
+		}
+		cmd := exec.Command("go", "test", "./"+filepath.Base(parent)+"/"+name)
+		cmd.Dir = root
+		if b, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("%s: go test failed: %v\n%s", tmpl.Name, err, b)
+		}
+	}
+}
+
+func TestNew_Rejects(t *testing.T) {
+	root, err := ModuleRoot(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct{ tmpl, parent, name string }{
+		{"nope", root, "x"},           // unknown template
+		{"empty", root, "Bad Name"},   // invalid name
+		{"empty", root, "contract"},   // existing directory
+		{"empty", t.TempDir(), "ok"},  // outside the module
+		{"empty", root, "../escaped"}, // path in name
+	}
+	for _, c := range cases {
+		if _, err := New(root, c.tmpl, c.parent, c.name); err == nil {
+			t.Errorf("New(%q, %q, %q) succeeded", c.tmpl, c.parent, c.name)
+		}
+	}
+}
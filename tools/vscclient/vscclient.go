@@ -0,0 +1,148 @@
+// Package vscclient holds the runtime pieces shared by generated contract
+// clients: the vsc.call_contract transaction body, transfer.allow intents and
+// the contract return envelope.
+package vscclient
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// CallContractId is the Hive custom_json id for contract calls.
+const CallContractId = "vsc.call_contract"
+
+const DefaultNetId = "vsc-mainnet"
+
+// DefaultRcLimit matches the limit used by the node e2e harness.
+const DefaultRcLimit = 5_000_000_000
+
+// Intent grants the contract permission to act for the caller, e.g. draw funds.
+type Intent struct {
+	Type string            `json:"type"`
+	Args map[string]string `json:"args"`
+}
+
+// Allow builds a transfer.allow intent letting the contract draw up to units
+// (smallest unit, 3 decimals for hive and hbd) of asset from the caller.
+func Allow(asset string, units uint64) Intent {
+	return Intent{Type: "transfer.allow", Args: map[string]string{
+		"limit": formatUnits(units, 3),
+		"token": asset,
+	}}
+}
+
+func formatUnits(units uint64, decimals int) string {
+	s := strconv.FormatUint(units, 10)
+	for len(s) <= decimals {
+		s = "0" + s
+	}
+	return s[:len(s)-decimals] + "." + s[len(s)-decimals:]
+}
+
+// CallContract is the body of a vsc.call_contract transaction.
+type CallContract struct {
+	NetId      string   `json:"net_id"`
+	Caller     string   `json:"caller"`
+	ContractId string   `json:"contract_id"`
+	Action     string   `json:"action"`
+	Payload    string   `json:"payload"`
+	RcLimit    uint64   `json:"rc_limit"`
+	Intents    []Intent `json:"intents"`
+}
+
+// JSON returns the transaction body.
+func (c *CallContract) JSON() ([]byte, error) {
+	if c.Intents == nil {
+		cp := *c
+		cp.Intents = []Intent{}
+		return json.Marshal(&cp)
+	}
+	return json.Marshal(c)
+}
+
+// CustomJSONOp is a Hive custom_json operation carrying a contract call.
+type CustomJSONOp struct {
+	Id                   string   `json:"id"`
+	RequiredAuths        []string `json:"required_auths"`
+	RequiredPostingAuths []string `json:"required_posting_auths"`
+	Json                 string   `json:"json"`
+}
+
+// Op wraps the call in a custom_json operation signed by the caller's Hive
+// account. Calls that draw funds need active authority, so active is used
+// when payable is true and posting otherwise.
+func (c *CallContract) Op(payable bool) (*CustomJSONOp, error) {
+	account, ok := strings.CutPrefix(c.Caller, "hive:")
+	if !ok {
+		return nil, errors.New("vscclient: caller must be a hive: address")
+	}
+	body, err := c.JSON()
+	if err != nil {
+		return nil, err
+	}
+	op := &CustomJSONOp{Id: CallContractId, RequiredAuths: []string{}, RequiredPostingAuths: []string{}, Json: string(body)}
+	if payable {
+		op.RequiredAuths = append(op.RequiredAuths, account)
+	} else {
+		op.RequiredPostingAuths = append(op.RequiredPostingAuths, account)
+	}
+	return op, nil
+}
+
+// Result is the envelope the node returns for a contract call.
+type Result struct {
+	Success bool   `json:"success"`
+	Ret     string `json:"ret"`
+	Err     string `json:"err,omitempty"`
+}
+
+// ErrCallFailed wraps the message of an unsuccessful call.
+type ErrCallFailed struct{ Msg string }
+
+func (e *ErrCallFailed) Error() string { return "contract call failed: " + e.Msg }
+
+// DecodeResult parses the envelope and returns the raw return value of a
+// successful call.
+func DecodeResult(b []byte) (string, error) {
+	var r Result
+	if err := json.Unmarshal(b, &r); err != nil {
+		return "", err
+	}
+	if !r.Success {
+		msg := r.Err
+		if msg == "" {
+			msg = r.Ret
+		}
+		return "", &ErrCallFailed{Msg: msg}
+	}
+	return r.Ret, nil
+}
+
+// CheckField rejects values that would break the comma separated payload.
+func CheckField(name, v string) error {
+	if strings.Contains(v, ",") {
+		return errors.New("vscclient: field " + name + " must not contain a comma")
+	}
+	return nil
+}
+
+// CheckEnum rejects values outside the allowed set.
+func CheckEnum(name, v string, allowed ...string) error {
+	for _, a := range allowed {
+		if v == a {
+			return nil
+		}
+	}
+	return errors.New("vscclient: field " + name + " must be one of " + strings.Join(allowed, "|"))
+}
+
+// JoinPayload joins fields with commas, dropping empty trailing optional fields.
+func JoinPayload(fields []string, required int) string {
+	n := len(fields)
+	for n > required && fields[n-1] == "" {
+		n--
+	}
+	return strings.Join(fields[:n], ",")
+}
@@ -0,0 +1,42 @@
+package vscclient
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAllow_FormatsLimit(t *testing.T) {
+	cases := map[uint64]string{0: "0.000", 5: "0.005", 1000: "1.000", 1234567: "1234.567"}
+	for units, want := range cases {
+		if got := Allow("hbd", units).Args["limit"]; got != want {
+			t.Fatalf("Allow(%d) = %s, want %s", units, got, want)
+		}
+	}
+}
+
+func TestCallContract_OpAuth(t *testing.T) {
+	c := &CallContract{Caller: "hive:alice", Action: "transfer"}
+	op, err := c.Op(false)
+	if err != nil || len(op.RequiredAuths) != 0 || op.RequiredPostingAuths[0] != "alice" {
+		t.Fatalf("posting op: %+v %v", op, err)
+	}
+	if op.Json != `{"net_id":"","caller":"hive:alice","contract_id":"","action":"transfer","payload":"","rc_limit":0,"intents":[]}` {
+		t.Fatalf("json = %s", op.Json)
+	}
+	c.Caller = "did:key:z6Mk"
+	if _, err := c.Op(true); err == nil {
+		t.Fatal("non-hive caller cannot sign custom_json")
+	}
+}
+
+func TestDecodeResult(t *testing.T) {
+	ret, err := DecodeResult([]byte(`{"success":true,"ret":"42"}`))
+	if err != nil || ret != "42" {
+		t.Fatalf("ret=%q err=%v", ret, err)
+	}
+	_, err = DecodeResult([]byte(`{"success":false,"ret":"assertion failed"}`))
+	var failed *ErrCallFailed
+	if !errors.As(err, &failed) || failed.Msg != "assertion failed" {
+		t.Fatalf("err = %v", err)
+	}
+}